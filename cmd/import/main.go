@@ -0,0 +1,56 @@
+// Command import loads a Slack or Discord export into Telex rooms.
+//
+//	go run ./cmd/import -provider slack -file export.zip -owner admin@example.com
+//
+// Imports are idempotent, so an interrupted run can simply be repeated.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/services/importer"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func main() {
+	var (
+		provider = flag.String("provider", models.ImportProviderSlack, "export format: slack or discord")
+		file     = flag.String("file", "", "path to the export file")
+		owner    = flag.String("owner", "", "email of the user who will own the imported rooms")
+	)
+	flag.Parse()
+
+	if *file == "" || *owner == "" {
+		flag.Usage()
+		log.Fatal("-file and -owner are required")
+	}
+
+	logger := utility.NewLogger()
+	configuration := config.Setup(logger, "./app")
+	db := postgresql.ConnectToDatabase(logger, configuration.Database)
+
+	var user models.User
+	user, err := user.GetUserByEmail(db, *owner)
+	if err != nil {
+		log.Fatalf("owner %v not found: %v", *owner, err)
+	}
+
+	src, err := importer.Open(*provider, *file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer src.Close()
+
+	result, err := importer.Run(db, src, user.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("rooms created: %d, users created: %d, messages created: %d, messages skipped: %d\n",
+		result.RoomsCreated, result.UsersCreated, result.MessagesCreated, result.MessagesSkipped)
+}
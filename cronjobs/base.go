@@ -13,7 +13,16 @@ import (
 
 var (
 	cronJobs = map[string]CronJobObject{
-		"send-notifications": {CronJob: SendNotifications, Interval: time.Second * 5},
+		"send-notifications":      {CronJob: SendNotifications, Interval: time.Second * 5},
+		"send-scheduled-messages": {CronJob: SendScheduledMessages, Interval: time.Second * 30},
+		"purge-expired-messages":  {CronJob: PurgeExpiredMessages, Interval: time.Hour},
+		"process-room-exports":    {CronJob: ProcessRoomExports, Interval: time.Second * 10},
+		"purge-expired-exports":   {CronJob: PurgeExpiredExports, Interval: time.Hour},
+		"process-imports":         {CronJob: ProcessImports, Interval: time.Second * 30},
+		"process-account-exports": {CronJob: ProcessAccountExports, Interval: time.Second * 30},
+		"purge-deleted-accounts":  {CronJob: PurgeDeletedAccounts, Interval: time.Hour},
+		"resolve-event-locations": {CronJob: ResolveSecurityEventLocations, Interval: time.Second * 30},
+		"rotate-signing-keys":     {CronJob: RotateSigningKeys, Interval: time.Minute * 10},
 	}
 	stopSignals = map[string]chan bool{}
 )
@@ -0,0 +1,37 @@
+package cronjobs
+
+import (
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/export"
+)
+
+var accountExportsBatchSize = 5
+
+func ProcessAccountExports(extReq request.ExternalRequest, db storage.Database) {
+	accountExport := models.AccountExport{}
+
+	pending, err := accountExport.GetPendingAccountExports(db.Postgresql, accountExportsBatchSize)
+	if err != nil {
+		extReq.Logger.Error("error getting pending account exports: ", err.Error())
+		return
+	}
+
+	for _, job := range pending {
+		claimed, err := job.Claim(db.Postgresql)
+		if err != nil {
+			extReq.Logger.Error("error claiming account export: ", job.ID, err.Error())
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		err = export.ProcessAccountExport(db.Postgresql, db.Redis, job)
+		if err != nil {
+			extReq.Logger.Error("error processing account export: ", job.ID, err.Error())
+		}
+	}
+}
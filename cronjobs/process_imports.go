@@ -0,0 +1,38 @@
+package cronjobs
+
+import (
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/importer"
+)
+
+// imports can be large, so each tick runs at most one
+var importsBatchSize = 1
+
+func ProcessImports(extReq request.ExternalRequest, db storage.Database) {
+	job := models.ImportJob{}
+
+	pending, err := job.GetPendingImportJobs(db.Postgresql, importsBatchSize)
+	if err != nil {
+		extReq.Logger.Error("error getting pending imports: ", err.Error())
+		return
+	}
+
+	for _, job := range pending {
+		claimed, err := job.Claim(db.Postgresql)
+		if err != nil {
+			extReq.Logger.Error("error claiming import: ", job.ID, err.Error())
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		err = importer.ProcessImportJob(db.Postgresql, job)
+		if err != nil {
+			extReq.Logger.Error("error processing import: ", job.ID, err.Error())
+		}
+	}
+}
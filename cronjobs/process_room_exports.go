@@ -0,0 +1,37 @@
+package cronjobs
+
+import (
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/export"
+)
+
+var roomExportsBatchSize = 5
+
+func ProcessRoomExports(extReq request.ExternalRequest, db storage.Database) {
+	roomExport := models.RoomExport{}
+
+	pending, err := roomExport.GetPendingRoomExports(db.Postgresql, roomExportsBatchSize)
+	if err != nil {
+		extReq.Logger.Error("error getting pending room exports: ", err.Error())
+		return
+	}
+
+	for _, job := range pending {
+		claimed, err := job.Claim(db.Postgresql)
+		if err != nil {
+			extReq.Logger.Error("error claiming room export: ", job.ID, err.Error())
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		err = export.ProcessRoomExport(db.Postgresql, db.Redis, job)
+		if err != nil {
+			extReq.Logger.Error("error processing room export: ", job.ID, err.Error())
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package cronjobs
+
+import (
+	"fmt"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/user"
+)
+
+var deletedAccountsBatchSize = 20
+
+func PurgeDeletedAccounts(extReq request.ExternalRequest, db storage.Database) {
+	users, err := models.GetUsersDueForDeletion(db.Postgresql, deletedAccountsBatchSize)
+	if err != nil {
+		extReq.Logger.Error("error getting accounts due for deletion: ", err.Error())
+		return
+	}
+
+	for _, account := range users {
+		err := user.PurgeAccount(db.Postgresql, account)
+		if err != nil {
+			extReq.Logger.Error("error deleting account: ", account.ID, err.Error())
+			continue
+		}
+		extReq.Logger.Info(fmt.Sprintf("deleted account %s", account.ID))
+	}
+}
@@ -0,0 +1,23 @@
+package cronjobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/export"
+)
+
+var expiredExportsBatchSize = 100
+
+func PurgeExpiredExports(extReq request.ExternalRequest, db storage.Database) {
+	purged, err := export.PurgeExpiredExports(db.Postgresql, time.Now(), expiredExportsBatchSize)
+	if err != nil {
+		extReq.Logger.Error("error purging expired exports: ", err.Error())
+	}
+
+	if purged > 0 {
+		extReq.Logger.Info(fmt.Sprintf("deleted %d expired export files", purged))
+	}
+}
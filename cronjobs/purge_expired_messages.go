@@ -0,0 +1,63 @@
+package cronjobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+)
+
+var (
+	defaultRetentionBatchSize = 500
+	retentionBatchPause       = 100 * time.Millisecond
+)
+
+func PurgeExpiredMessages(extReq request.ExternalRequest, db storage.Database) {
+	var (
+		retention = config.GetConfig().Retention
+		batchSize = retention.BatchSize
+		room      models.Room
+	)
+
+	if batchSize <= 0 {
+		batchSize = defaultRetentionBatchSize
+	}
+
+	rooms, err := room.GetRoomsForRetention(db.Postgresql, retention.DefaultDays)
+	if err != nil {
+		extReq.Logger.Error("error getting rooms for retention: ", err.Error())
+		return
+	}
+
+	for _, room := range rooms {
+		days := room.RetentionDays
+		if days <= 0 {
+			days = retention.DefaultDays
+		}
+		cutoff := time.Now().AddDate(0, 0, -days)
+
+		var total int64
+		for {
+			purged, err := room.PurgeMessagesBefore(db.Postgresql, cutoff, batchSize, retention.Archive)
+			if err != nil {
+				extReq.Logger.Error("error purging messages for room: ", room.ID, err.Error())
+				break
+			}
+
+			total += purged
+			if purged < int64(batchSize) {
+				break
+			}
+
+			// give other writers a chance at the table between batches
+			time.Sleep(retentionBatchPause)
+		}
+
+		if total > 0 {
+			extReq.Logger.Info(fmt.Sprintf("purged %d messages from room %s", total, room.ID))
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package cronjobs
+
+import (
+	"fmt"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/auth"
+)
+
+var securityEventLocationsBatchSize = 50
+
+func ResolveSecurityEventLocations(extReq request.ExternalRequest, db storage.Database) {
+	resolved, err := auth.ResolveSecurityEventLocations(extReq, db.Postgresql, securityEventLocationsBatchSize)
+	if err != nil {
+		extReq.Logger.Error("error resolving security event locations: ", err.Error())
+		return
+	}
+
+	if resolved > 0 {
+		extReq.Logger.Info(fmt.Sprintf("resolved locations for %d security events", resolved))
+	}
+}
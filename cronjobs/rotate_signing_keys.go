@@ -0,0 +1,21 @@
+package cronjobs
+
+import (
+	"time"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/auth"
+)
+
+func RotateSigningKeys(extReq request.ExternalRequest, db storage.Database) {
+	rotated, err := auth.RotateSigningKeys(db.Postgresql, time.Now())
+	if err != nil {
+		extReq.Logger.Error("error rotating signing keys: ", err.Error())
+		return
+	}
+
+	if rotated {
+		extReq.Logger.Info("published a new signing key")
+	}
+}
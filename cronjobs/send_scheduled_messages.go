@@ -0,0 +1,39 @@
+package cronjobs
+
+import (
+	"time"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/room"
+)
+
+var scheduledMessagesBatchSize = 100
+
+func SendScheduledMessages(extReq request.ExternalRequest, db storage.Database) {
+	scheduled := models.ScheduledMessage{}
+
+	due, err := scheduled.GetDueScheduledMessages(db.Postgresql, time.Now(), scheduledMessagesBatchSize)
+	if err != nil {
+		extReq.Logger.Error("error getting due scheduled messages: ", err.Error())
+		return
+	}
+
+	for _, message := range due {
+		claimed, err := message.Claim(db.Postgresql)
+		if err != nil {
+			extReq.Logger.Error("error claiming scheduled message: ", message.ID, err.Error())
+			continue
+		}
+
+		if !claimed {
+			continue
+		}
+
+		err = room.SendScheduledMessage(db.Postgresql, db.Redis, extReq.Logger, message)
+		if err != nil {
+			extReq.Logger.Error("error sending scheduled message: ", message.ID, err.Error())
+		}
+	}
+}
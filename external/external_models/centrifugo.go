@@ -0,0 +1,16 @@
+package external_models
+
+type CentrifugoPublishRequest struct {
+	Channel string      `json:"channel"`
+	Data    interface{} `json:"data"`
+}
+
+type CentrifugoError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type CentrifugoPublishResponse struct {
+	Error  *CentrifugoError       `json:"error,omitempty"`
+	Result map[string]interface{} `json:"result,omitempty"`
+}
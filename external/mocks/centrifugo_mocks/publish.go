@@ -0,0 +1,26 @@
+package centrifugo_mocks
+
+import (
+	"fmt"
+
+	"github.com/hngprojects/telex_be/external/external_models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func CentrifugoPublish(logger *utility.Logger, idata interface{}) (external_models.CentrifugoPublishResponse, error) {
+
+	var (
+		outBoundResponse external_models.CentrifugoPublishResponse
+	)
+
+	data, ok := idata.(external_models.CentrifugoPublishRequest)
+	if !ok {
+		logger.Error("centrifugo publish", idata, "request data format error")
+		return outBoundResponse, fmt.Errorf("request data format error")
+	}
+	outBoundResponse.Result = map[string]interface{}{}
+
+	logger.Info("centrifugo publish", data.Channel)
+
+	return outBoundResponse, nil
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"fmt"
 
+	"github.com/hngprojects/telex_be/external/mocks/centrifugo_mocks"
 	"github.com/hngprojects/telex_be/external/mocks/ipstack_mocks"
 	"github.com/hngprojects/telex_be/utility"
 )
@@ -33,6 +34,8 @@ func (er ExternalRequest) SendExternalRequest(name string, data interface{}) (in
 	switch name {
 	case "ipstack_resolve_ip":
 		return ipstack_mocks.IpstackResolveIp(er.Logger, data)
+	case "centrifugo_publish":
+		return centrifugo_mocks.CentrifugoPublish(er.Logger, data)
 	default:
 		return nil, fmt.Errorf("request not found")
 	}
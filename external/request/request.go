@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/hngprojects/telex_be/external/mocks"
+	"github.com/hngprojects/telex_be/external/thirdparty/centrifugo"
 	"github.com/hngprojects/telex_be/external/thirdparty/ipstack"
 	"github.com/hngprojects/telex_be/internal/config"
 	"github.com/hngprojects/telex_be/utility"
@@ -20,7 +21,8 @@ var (
 	PhpSerializerMethod string = "phpserializer"
 
 	// requests
-	IpstackResolveIp string = "ipstack_resolve_ip"
+	IpstackResolveIp  string = "ipstack_resolve_ip"
+	CentrifugoPublish string = "centrifugo_publish"
 )
 
 func (er ExternalRequest) SendExternalRequest(name string, data interface{}) (interface{}, error) {
@@ -40,6 +42,17 @@ func (er ExternalRequest) SendExternalRequest(name string, data interface{}) (in
 				Logger:       er.Logger,
 			}
 			return obj.IpstackResolveIp()
+		case CentrifugoPublish:
+			obj := centrifugo.RequestObj{
+				Name:         name,
+				Path:         fmt.Sprintf("%v", config.Centrifuge.ApiUrl),
+				Method:       "POST",
+				SuccessCode:  200,
+				DecodeMethod: JsonDecodeMethod,
+				RequestData:  data,
+				Logger:       er.Logger,
+			}
+			return obj.CentrifugoPublish()
 		default:
 			return nil, fmt.Errorf("request not found")
 		}
@@ -0,0 +1,43 @@
+package centrifugo
+
+import (
+	"fmt"
+
+	"github.com/hngprojects/telex_be/external/external_models"
+	"github.com/hngprojects/telex_be/internal/config"
+)
+
+func (r *RequestObj) CentrifugoPublish() (external_models.CentrifugoPublishResponse, error) {
+
+	var (
+		key              = config.GetConfig().Centrifuge.ApiKey
+		outBoundResponse external_models.CentrifugoPublishResponse
+		logger           = r.Logger
+		idata            = r.RequestData
+	)
+
+	data, ok := idata.(external_models.CentrifugoPublishRequest)
+	if !ok {
+		logger.Error("centrifugo publish", idata, "request data format error")
+		return outBoundResponse, fmt.Errorf("request data format error")
+	}
+
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "apikey " + key,
+	}
+
+	logger.Info("centrifugo publish", data.Channel)
+	err := r.getNewSendRequestObject(data, headers, "/publish").SendRequest(&outBoundResponse)
+	if err != nil {
+		logger.Error("centrifugo publish", outBoundResponse, err.Error())
+		return outBoundResponse, err
+	}
+
+	if outBoundResponse.Error != nil {
+		logger.Error("centrifugo publish", data.Channel, outBoundResponse.Error.Message)
+		return outBoundResponse, fmt.Errorf("centrifugo publish failed: %v", outBoundResponse.Error.Message)
+	}
+
+	return outBoundResponse, nil
+}
@@ -2,4 +2,6 @@ package config
 
 type Centrifuge struct {
 	Secret string
+	ApiUrl string
+	ApiKey string
 }
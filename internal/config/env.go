@@ -10,19 +10,27 @@ type Configuration struct {
 	Database     Database
 	TestDatabase Database
 	App          App
+	Retention    Retention
+	Storage      Storage
+	Moderation   Moderation
 	IPStack      IPStack
+	OAuth        OAuth
 	Centrifuge   Centrifuge
 	Redis        Redis
 	Mail         MAIL
+	SMS          SMS
 }
 
 type BaseConfig struct {
-	SERVER_PORT                      string  `mapstructure:"SERVER_PORT"`
-	SERVER_SECRET                    string  `mapstructure:"SERVER_SECRET"`
-	SERVER_ACCESSTOKENEXPIREDURATION int     `mapstructure:"SERVER_ACCESSTOKENEXPIREDURATION"`
-	REQUEST_PER_SECOND               float64 `mapstructure:"REQUEST_PER_SECOND"`
-	TRUSTED_PROXIES                  string  `mapstructure:"TRUSTED_PROXIES"`
-	EXEMPT_FROM_THROTTLE             string  `mapstructure:"EXEMPT_FROM_THROTTLE"`
+	SERVER_PORT                     string `mapstructure:"SERVER_PORT"`
+	SERVER_SECRET                   string `mapstructure:"SERVER_SECRET"`
+	SERVER_ACCESSTOKENEXPIREMINUTES int    `mapstructure:"SERVER_ACCESSTOKENEXPIREMINUTES"`
+	SERVER_REFRESHTOKENEXPIREDAYS   int    `mapstructure:"SERVER_REFRESHTOKENEXPIREDAYS"`
+	SERVER_JWTALGORITHM             string `mapstructure:"SERVER_JWTALGORITHM"`
+	SERVER_JWTKEYROTATIONDAYS       int    `mapstructure:"SERVER_JWTKEYROTATIONDAYS"`
+	SERVER_SIGNINGKEYSECRET         string `mapstructure:"SERVER_SIGNINGKEYSECRET"`
+	TRUSTED_PROXIES                 string `mapstructure:"TRUSTED_PROXIES"`
+	EXEMPT_FROM_THROTTLE            string `mapstructure:"EXEMPT_FROM_THROTTLE"`
 
 	APP_NAME                string `mapstructure:"APP_NAME"`
 	APP_MODE                string `mapstructure:"APP_MODE"`
@@ -30,6 +38,19 @@ type BaseConfig struct {
 	MAGIC_LINK_DURATION     int    `mapstructure:"MAGIC_LINK_DURATION"`
 	RESET_PASSWORD_DURATION int    `mapstructure:"RESET_PASSWORD_DURATION"`
 
+	MESSAGE_RETENTION_DAYS    int  `mapstructure:"MESSAGE_RETENTION_DAYS"`
+	MESSAGE_RETENTION_BATCH   int  `mapstructure:"MESSAGE_RETENTION_BATCH"`
+	MESSAGE_RETENTION_ARCHIVE bool `mapstructure:"MESSAGE_RETENTION_ARCHIVE"`
+
+	STORAGE_LOCAL_PATH string `mapstructure:"STORAGE_LOCAL_PATH"`
+
+	MODERATION_BLOCKLIST        string `mapstructure:"MODERATION_BLOCKLIST"`
+	MODERATION_BLOCKLIST_ACTION string `mapstructure:"MODERATION_BLOCKLIST_ACTION"`
+	MODERATION_LINK_ALLOWLIST   string `mapstructure:"MODERATION_LINK_ALLOWLIST"`
+	MODERATION_LINK_DENYLIST    string `mapstructure:"MODERATION_LINK_DENYLIST"`
+	MODERATION_SPAM_THRESHOLD   int    `mapstructure:"MODERATION_SPAM_THRESHOLD"`
+	MODERATION_SPAM_WINDOW      int    `mapstructure:"MODERATION_SPAM_WINDOW"`
+
 	DB_HOST       string `mapstructure:"DB_HOST"`
 	DB_PORT       string `mapstructure:"DB_PORT"`
 	DB_CONNECTION string `mapstructure:"DB_CONNECTION"`
@@ -53,7 +74,21 @@ type BaseConfig struct {
 	IPSTACK_KEY      string `mapstructure:"IPSTACK_KEY"`
 	IPSTACK_BASE_URL string `mapstructure:"IPSTACK_BASE_URL"`
 
-	HMAC_SECRET string `mapstructure:"HMAC_SECRET"`
+	OAUTH_GOOGLE_CLIENT_ID   string `mapstructure:"OAUTH_GOOGLE_CLIENT_ID"`
+	OAUTH_GITHUB_KEY         string `mapstructure:"OAUTH_GITHUB_KEY"`
+	OAUTH_GITHUB_SECRET      string `mapstructure:"OAUTH_GITHUB_SECRET"`
+	OAUTH_MICROSOFT_KEY      string `mapstructure:"OAUTH_MICROSOFT_KEY"`
+	OAUTH_MICROSOFT_SECRET   string `mapstructure:"OAUTH_MICROSOFT_SECRET"`
+	OAUTH_GITLAB_KEY         string `mapstructure:"OAUTH_GITLAB_KEY"`
+	OAUTH_GITLAB_SECRET      string `mapstructure:"OAUTH_GITLAB_SECRET"`
+	OAUTH_OIDC_NAME          string `mapstructure:"OAUTH_OIDC_NAME"`
+	OAUTH_OIDC_KEY           string `mapstructure:"OAUTH_OIDC_KEY"`
+	OAUTH_OIDC_SECRET        string `mapstructure:"OAUTH_OIDC_SECRET"`
+	OAUTH_OIDC_DISCOVERY_URL string `mapstructure:"OAUTH_OIDC_DISCOVERY_URL"`
+
+	HMAC_SECRET        string `mapstructure:"HMAC_SECRET"`
+	CENTRIFUGO_API_URL string `mapstructure:"CENTRIFUGO_API_URL"`
+	CENTRIFUGO_API_KEY string `mapstructure:"CENTRIFUGO_API_KEY"`
 
 	MAIL_SERVER   string `mapstructure:"MAIL_SERVER"`
 	MAIL_PASSWORD string `mapstructure:"MAIL_PASSWORD"`
@@ -63,24 +98,35 @@ type BaseConfig struct {
 	REDIS_PORT string `mapstructure:"REDIS_PORT"`
 	REDIS_HOST string `mapstructure:"REDIS_HOST"`
 	REDIS_DB   string `mapstructure:"REDIS_DB"`
+
+	SMS_DRIVER string `mapstructure:"SMS_DRIVER"`
 }
 
 func (config *BaseConfig) SetupConfigurationn() *Configuration {
 	trustedProxies := []string{}
 	exemptFromThrottle := []string{}
+	blocklist := []string{}
+	linkAllowlist := []string{}
+	linkDenylist := []string{}
 	json.Unmarshal([]byte(config.TRUSTED_PROXIES), &trustedProxies)
 	json.Unmarshal([]byte(config.EXEMPT_FROM_THROTTLE), &exemptFromThrottle)
+	json.Unmarshal([]byte(config.MODERATION_BLOCKLIST), &blocklist)
+	json.Unmarshal([]byte(config.MODERATION_LINK_ALLOWLIST), &linkAllowlist)
+	json.Unmarshal([]byte(config.MODERATION_LINK_DENYLIST), &linkDenylist)
 	if config.SERVER_PORT == "" {
 		config.SERVER_PORT = os.Getenv("PORT")
 	}
 	return &Configuration{
 		Server: ServerConfiguration{
-			Port:                      config.SERVER_PORT,
-			Secret:                    config.SERVER_SECRET,
-			AccessTokenExpireDuration: config.SERVER_ACCESSTOKENEXPIREDURATION,
-			RequestPerSecond:          config.REQUEST_PER_SECOND,
-			TrustedProxies:            trustedProxies,
-			ExemptFromThrottle:        exemptFromThrottle,
+			Port:                     config.SERVER_PORT,
+			Secret:                   config.SERVER_SECRET,
+			AccessTokenExpireMinutes: config.SERVER_ACCESSTOKENEXPIREMINUTES,
+			RefreshTokenExpireDays:   config.SERVER_REFRESHTOKENEXPIREDAYS,
+			JWTAlgorithm:             config.SERVER_JWTALGORITHM,
+			JWTKeyRotationDays:       config.SERVER_JWTKEYROTATIONDAYS,
+			SigningKeySecret:         config.SERVER_SIGNINGKEYSECRET,
+			TrustedProxies:           trustedProxies,
+			ExemptFromThrottle:       exemptFromThrottle,
 		},
 		App: App{
 			Name:                  config.APP_NAME,
@@ -89,6 +135,22 @@ func (config *BaseConfig) SetupConfigurationn() *Configuration {
 			MagicLinkDuration:     config.MAGIC_LINK_DURATION,
 			ResetPasswordDuration: config.RESET_PASSWORD_DURATION,
 		},
+		Retention: Retention{
+			DefaultDays: config.MESSAGE_RETENTION_DAYS,
+			BatchSize:   config.MESSAGE_RETENTION_BATCH,
+			Archive:     config.MESSAGE_RETENTION_ARCHIVE,
+		},
+		Storage: Storage{
+			LocalPath: config.STORAGE_LOCAL_PATH,
+		},
+		Moderation: Moderation{
+			Blocklist:       blocklist,
+			BlocklistAction: config.MODERATION_BLOCKLIST_ACTION,
+			LinkAllowlist:   linkAllowlist,
+			LinkDenylist:    linkDenylist,
+			SpamThreshold:   config.MODERATION_SPAM_THRESHOLD,
+			SpamWindow:      config.MODERATION_SPAM_WINDOW,
+		},
 		Database: Database{
 			DB_HOST:       config.DB_HOST,
 			DB_PORT:       config.DB_PORT,
@@ -117,8 +179,28 @@ func (config *BaseConfig) SetupConfigurationn() *Configuration {
 			BaseUrl: config.IPSTACK_BASE_URL,
 		},
 
+		OAuth: OAuth{
+			GoogleClientID:  config.OAUTH_GOOGLE_CLIENT_ID,
+			GitHubKey:       config.OAUTH_GITHUB_KEY,
+			GitHubSecret:    config.OAUTH_GITHUB_SECRET,
+			MicrosoftKey:    config.OAUTH_MICROSOFT_KEY,
+			MicrosoftSecret: config.OAUTH_MICROSOFT_SECRET,
+			GitLabKey:       config.OAUTH_GITLAB_KEY,
+			GitLabSecret:    config.OAUTH_GITLAB_SECRET,
+			OIDCName:        config.OAUTH_OIDC_NAME,
+			OIDCKey:         config.OAUTH_OIDC_KEY,
+			OIDCSecret:      config.OAUTH_OIDC_SECRET,
+			OIDCDiscovery:   config.OAUTH_OIDC_DISCOVERY_URL,
+		},
+
 		Centrifuge: Centrifuge{
 			Secret: config.HMAC_SECRET,
+			ApiUrl: config.CENTRIFUGO_API_URL,
+			ApiKey: config.CENTRIFUGO_API_KEY,
+		},
+
+		SMS: SMS{
+			Driver: config.SMS_DRIVER,
 		},
 
 		Mail: MAIL{
@@ -0,0 +1,10 @@
+package config
+
+type Moderation struct {
+	Blocklist       []string
+	BlocklistAction string
+	LinkAllowlist   []string
+	LinkDenylist    []string
+	SpamThreshold   int
+	SpamWindow      int
+}
@@ -0,0 +1,18 @@
+package config
+
+// OAuth holds the client credentials for redirect based social login. A
+// provider is only enabled when its client id is set. GoogleClientID is the
+// audience Google ID tokens must be issued for.
+type OAuth struct {
+	GoogleClientID  string
+	GitHubKey       string
+	GitHubSecret    string
+	MicrosoftKey    string
+	MicrosoftSecret string
+	GitLabKey       string
+	GitLabSecret    string
+	OIDCName        string
+	OIDCKey         string
+	OIDCSecret      string
+	OIDCDiscovery   string
+}
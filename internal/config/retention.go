@@ -0,0 +1,7 @@
+package config
+
+type Retention struct {
+	DefaultDays int
+	BatchSize   int
+	Archive     bool
+}
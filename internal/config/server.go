@@ -1,12 +1,15 @@
 package config
 
 type ServerConfiguration struct {
-	Port                      string
-	Secret                    string
-	AccessTokenExpireDuration int
-	RequestPerSecond          float64
-	TrustedProxies            []string
-	ExemptFromThrottle        []string
+	Port                     string
+	Secret                   string
+	AccessTokenExpireMinutes int
+	RefreshTokenExpireDays   int
+	JWTAlgorithm             string
+	JWTKeyRotationDays       int
+	SigningKeySecret         string
+	TrustedProxies           []string
+	ExemptFromThrottle       []string
 }
 
 type App struct {
@@ -0,0 +1,7 @@
+package config
+
+type SMS struct {
+	// Driver names the SMSSender codes are sent through; "log" writes them
+	// to the log and is meant for development. Empty disables texts.
+	Driver string
+}
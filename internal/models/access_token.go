@@ -3,23 +3,61 @@ package models
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/utility"
 )
 
+// AccessToken is one issued access token and the refresh token paired with
+// it. Refreshing rotates both into a new row of the same family, so a family
+// follows a login from start to finish.
 type AccessToken struct {
-	ID                        string    `gorm:"column:id; type:uuid; not null; primaryKey; unique;" json:"id"`
-	OwnerID                   string    `gorm:"column:owner_id; type:uuid; not null" json:"owner_id"`
-	IsLive                    bool      `gorm:"column:is_live; type:bool; default:false; not null" json:"is_live"`
-	LoginAccessToken          string    `gorm:"column:login_access_token; type:text" json:"-"`
-	LoginAccessTokenExpiresIn string    `gorm:"column:login_access_token_expires_in; type:varchar(250)" json:"-"`
-	CreatedAt                 time.Time `gorm:"column:created_at; autoCreateTime" json:"created_at"`
-	UpdatedAt                 time.Time `gorm:"column:updated_at; autoUpdateTime" json:"updated_at"`
+	ID                        string     `gorm:"column:id; type:uuid; not null; primaryKey; unique;" json:"id"`
+	OwnerID                   string     `gorm:"column:owner_id; type:uuid; not null" json:"owner_id"`
+	FamilyID                  string     `gorm:"column:family_id; type:uuid; index" json:"family_id"`
+	IsLive                    bool       `gorm:"column:is_live; type:bool; default:false; not null" json:"is_live"`
+	LoginAccessToken          string     `gorm:"column:login_access_token; type:text" json:"-"`
+	LoginAccessTokenExpiresIn string     `gorm:"column:login_access_token_expires_in; type:varchar(250)" json:"-"`
+	RefreshTokenHash          string     `gorm:"column:refresh_token_hash; type:varchar(64); index" json:"-"`
+	RefreshTokenExpiresAt     *time.Time `gorm:"column:refresh_token_expires_at" json:"-"`
+	RotatedAt                 *time.Time `gorm:"column:rotated_at" json:"-"`
+	IPAddress                 string     `gorm:"column:ip_address; type:varchar(64)" json:"ip_address"`
+	UserAgent                 string     `gorm:"column:user_agent; type:text" json:"user_agent"`
+	LastUsedAt                *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	LastUsedIP                string     `gorm:"column:last_used_ip; type:varchar(64)" json:"last_used_ip"`
+	CreatedAt                 time.Time  `gorm:"column:created_at; autoCreateTime" json:"created_at"`
+	UpdatedAt                 time.Time  `gorm:"column:updated_at; autoUpdateTime" json:"updated_at"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// ClientInfo describes the client a token is issued to.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Session is a token family as shown to its owner.
+type Session struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Location   string    `json:"location"`
+	Current    bool      `json:"current"`
+}
+
+// lastUsedResolution bounds how often Authorize writes last-used data for a
+// token, so ordinary requests stay read-only.
+const lastUsedResolution = 5 * time.Minute
+
 func (a *AccessToken) GetAccessTokens(db *gorm.DB) error {
 	err := postgresql.SelectFirstFromDb(db, &a)
 	if err != nil {
@@ -74,13 +112,31 @@ func (a *AccessToken) CreateAccessToken(db *gorm.DB, tokenData interface{}) erro
 	}
 
 	var (
-		access_token = tokenData.(map[string]string)["access_token"]
-		exp          = tokenData.(map[string]string)["exp"]
+		access_token  = tokenData.(map[string]string)["access_token"]
+		exp           = tokenData.(map[string]string)["exp"]
+		refresh_token = tokenData.(map[string]string)["refresh_token"]
+		refresh_exp   = tokenData.(map[string]string)["refresh_exp"]
 	)
 
 	a.IsLive = true
 	a.LoginAccessToken = access_token
 	a.LoginAccessTokenExpiresIn = exp
+
+	// a login starts a new family; rotations pass the family along
+	if a.FamilyID == "" {
+		a.FamilyID = a.ID
+	}
+
+	if refresh_token != "" {
+		refreshExp, err := strconv.ParseInt(refresh_exp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid refresh token expiry: %v", err.Error())
+		}
+		expiresAt := time.Unix(refreshExp, 0)
+
+		a.RefreshTokenHash = utility.HashToken(refresh_token)
+		a.RefreshTokenExpiresAt = &expiresAt
+	}
 	err := postgresql.CreateOneRecord(db, &a)
 	if err != nil {
 		return fmt.Errorf("user creation failed: %v", err.Error())
@@ -93,6 +149,149 @@ func (a *AccessToken) RevokeAccessToken(db *gorm.DB) error {
 		return fmt.Errorf("access token id not provided to revoke access token")
 	}
 	a.IsLive = false
-	_, err := postgresql.SaveAllFields(db, &a)
+	// callers usually only know the id, so only is_live is written
+	_, err := postgresql.UpdateFields(db, &AccessToken{}, map[string]interface{}{"is_live": false}, "id = ?", a.ID)
 	return err
 }
+
+func (a *AccessToken) GetByRefreshToken(db *gorm.DB, refreshToken string) (int, error) {
+	err, nilErr := postgresql.SelectOneFromDb(db, &a, "refresh_token_hash = ?", utility.HashToken(refreshToken))
+	if nilErr != nil {
+		return http.StatusUnauthorized, nilErr
+	}
+
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// Rotate retires the token so its refresh token can no longer be used. It
+// reports false when another request rotated it first.
+func (a *AccessToken) Rotate(db *gorm.DB) (bool, error) {
+	now := time.Now()
+
+	result := db.Model(&AccessToken{}).Where("id = ? AND rotated_at IS NULL", a.ID).
+		Updates(map[string]interface{}{"is_live": false, "rotated_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily ends every token issued for the same login.
+func (a *AccessToken) RevokeFamily(db *gorm.DB) error {
+	if a.ID == "" {
+		return fmt.Errorf("access token id not provided to revoke token family")
+	}
+	family := a.Family()
+	return db.Model(&AccessToken{}).Where("family_id = ? OR id = ?", family, family).Update("is_live", false).Error
+}
+
+// Family returns the token's family id, falling back to its own id for
+// tokens issued before families existed.
+func (a *AccessToken) Family() string {
+	if a.FamilyID == "" {
+		return a.ID
+	}
+	return a.FamilyID
+}
+
+// TouchLastUsed records use of the token from ip. Unless the address changed,
+// it writes at most once per lastUsedResolution.
+func (a *AccessToken) TouchLastUsed(db *gorm.DB, ip string) error {
+	now := time.Now()
+
+	if a.LastUsedAt != nil && now.Sub(*a.LastUsedAt) < lastUsedResolution && a.LastUsedIP == ip {
+		return nil
+	}
+
+	a.LastUsedAt = &now
+	a.LastUsedIP = ip
+	_, err := postgresql.UpdateFields(db, &AccessToken{}, map[string]interface{}{"last_used_at": now, "last_used_ip": ip}, "id = ?", a.ID)
+	return err
+}
+
+// GetLiveSessions returns the owner's live tokens, one per family, along with
+// when each family started.
+func (a *AccessToken) GetLiveSessions(db *gorm.DB, ownerID string) ([]AccessToken, map[string]time.Time, error) {
+	var (
+		tokens  []AccessToken
+		started []struct {
+			Family    string
+			CreatedAt time.Time
+		}
+		startedAt = map[string]time.Time{}
+	)
+
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "desc", &tokens, "owner_id = ? AND is_live = ?", ownerID, true)
+	if err != nil {
+		return tokens, startedAt, err
+	}
+
+	err = db.Model(&AccessToken{}).
+		Select("COALESCE(family_id, id) AS family, MIN(created_at) AS created_at").
+		Where("owner_id = ?", ownerID).
+		Group("COALESCE(family_id, id)").
+		Scan(&started).Error
+	if err != nil {
+		return tokens, startedAt, err
+	}
+
+	for _, s := range started {
+		startedAt[s.Family] = s.CreatedAt
+	}
+	return tokens, startedAt, nil
+}
+
+// RevokeSession revokes one of the owner's token families.
+func (a *AccessToken) RevokeSession(db *gorm.DB, ownerID, familyID string) (int, error) {
+	result := db.Model(&AccessToken{}).
+		Where("owner_id = ? AND is_live = ? AND (family_id = ? OR id = ?)", ownerID, true, familyID, familyID).
+		Update("is_live", false)
+	if result.Error != nil {
+		return http.StatusInternalServerError, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return http.StatusNotFound, fmt.Errorf("session not found")
+	}
+	return http.StatusOK, nil
+}
+
+// RevokeOtherSessions revokes every live token of the owner outside the
+// given family.
+func (a *AccessToken) RevokeOtherSessions(db *gorm.DB, ownerID, familyID string) (int64, error) {
+	result := db.Model(&AccessToken{}).
+		Where("owner_id = ? AND is_live = ? AND COALESCE(family_id, id) <> ?", ownerID, true, familyID).
+		Update("is_live", false)
+	return result.RowsAffected, result.Error
+}
+
+// SessionStartedAt returns when the login behind the token happened, which
+// stays fixed as the token is rotated.
+func (a *AccessToken) SessionStartedAt(db *gorm.DB) (time.Time, error) {
+	var started struct {
+		CreatedAt time.Time
+	}
+
+	err := db.Model(&AccessToken{}).
+		Select("MIN(created_at) AS created_at").
+		Where("family_id = ? OR id = ?", a.Family(), a.Family()).
+		Scan(&started).Error
+	return started.CreatedAt, err
+}
+
+// RevokeAllSessions ends every live token the owner has.
+func (a *AccessToken) RevokeAllSessions(db *gorm.DB, ownerID string) (int64, error) {
+	result := db.Model(&AccessToken{}).
+		Where("owner_id = ? AND is_live = ?", ownerID, true).
+		Update("is_live", false)
+	return result.RowsAffected, result.Error
+}
+
+// GetOwnerTokens returns every token the owner was ever issued, oldest first.
+func (a *AccessToken) GetOwnerTokens(db *gorm.DB, ownerID string) ([]AccessToken, error) {
+	var tokens []AccessToken
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "asc", &tokens, "owner_id = ?", ownerID)
+	return tokens, err
+}
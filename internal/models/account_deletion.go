@@ -0,0 +1,290 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// DeletedUsername replaces the author name on messages left behind by a
+// deleted account.
+const DeletedUsername = "Deleted user"
+
+// ScheduleDeletion marks the account to be erased once deleteAfter passes.
+func (u *User) ScheduleDeletion(db *gorm.DB, deleteAfter time.Time) error {
+	u.DeleteAfter = &deleteAfter
+	_, err := postgresql.UpdateFields(db, &User{}, map[string]interface{}{"delete_after": deleteAfter}, "id = ?", u.ID)
+	return err
+}
+
+func (u *User) CancelDeletion(db *gorm.DB) error {
+	u.DeleteAfter = nil
+	_, err := postgresql.UpdateFields(db, &User{}, map[string]interface{}{"delete_after": nil}, "id = ?", u.ID)
+	return err
+}
+
+// GetUsersDueForDeletion returns accounts whose grace period has ended.
+func GetUsersDueForDeletion(db *gorm.DB, limit int) ([]User, error) {
+	var users []User
+
+	err := db.Where("delete_after IS NOT NULL AND delete_after <= ?", time.Now()).Order("delete_after asc").Limit(limit).Find(&users).Error
+	if err != nil {
+		return users, err
+	}
+	return users, nil
+}
+
+// EraseAccount removes the user and everything that identifies them. Rooms
+// and workspaces they own pass to their longest standing member; those
+// nobody else is in are deleted. Their messages stay in place under
+// DeletedUsername so conversations still read, and room events and system
+// messages that name them have the name replaced too.
+func (u *User) EraseAccount(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := transferOwnedRooms(tx, u.ID); err != nil {
+			return err
+		}
+		if err := transferOwnedWorkspaces(tx, u.ID); err != nil {
+			return err
+		}
+		// must run while their room memberships still hold their usernames
+		if err := scrubRoomActivity(tx, u.ID); err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&UserRoom{}, &WorkspaceMember{}, &ScheduledMessage{}, &AccountExport{}, &APIToken{}, &EmailChange{}, &SecurityEvent{}, &UserIdentity{}, &TwoFactor{}, &RecoveryCode{}} {
+			if err := tx.Unscoped().Where("user_id = ?", u.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("owner_id = ?", u.ID).Delete(&AccessToken{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&PasswordReset{}, &MagicLink{}, &WorkspaceInvite{}} {
+			if err := tx.Unscoped().Where("email = ?", u.Email).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, model := range []interface{}{&Message{}, &ArchivedMessage{}} {
+			if err := tx.Model(model).Where("user_id = ?", u.ID).Update("username", DeletedUsername).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Where("userid = ?", u.ID).Delete(&Profile{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&User{}, "id = ?", u.ID).Error
+	})
+}
+
+func transferOwnedRooms(tx *gorm.DB, userID string) error {
+	var rooms []Room
+
+	err := tx.Where("owner_id = ?", userID).Find(&rooms).Error
+	if err != nil {
+		return err
+	}
+
+	for _, room := range rooms {
+		var heir UserRoom
+
+		err := tx.Where("room_id = ? AND user_id <> ?", room.ID, userID).Order("created_at asc").First(&heir).Error
+		if err == gorm.ErrRecordNotFound {
+			if err := room.Delete(tx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = postgresql.UpdateFields(tx, &Room{}, map[string]interface{}{"owner_id": heir.UserID}, "id = ?", room.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = recordRoomActivity(tx, room.ID, RoomEventOwnershipTransferred, heir.UserID, heir.Username, heir.UserID,
+			fmt.Sprintf("%v is now the room owner", heir.Username),
+			map[string]interface{}{"username": heir.Username})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func transferOwnedWorkspaces(tx *gorm.DB, userID string) error {
+	var workspaces []Workspace
+
+	err := tx.Where("owner_id = ?", userID).Find(&workspaces).Error
+	if err != nil {
+		return err
+	}
+
+	for _, workspace := range workspaces {
+		var heir WorkspaceMember
+
+		// admins are preferred over members, then the longest standing
+		err = tx.Where("workspace_id = ? AND user_id <> ?", workspace.ID, userID).
+			Order(fmt.Sprintf("CASE WHEN role = '%v' THEN 0 ELSE 1 END, created_at asc", WorkspaceRoleAdmin)).
+			First(&heir).Error
+		if err == gorm.ErrRecordNotFound {
+			if err := workspace.Delete(tx); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = postgresql.UpdateFields(tx, &Workspace{}, map[string]interface{}{"owner_id": heir.UserID}, "id = ?", workspace.ID)
+		if err != nil {
+			return err
+		}
+		_, err = postgresql.UpdateFields(tx, &WorkspaceMember{}, map[string]interface{}{"role": WorkspaceRoleOwner}, "workspace_id = ? AND user_id = ?", workspace.ID, heir.UserID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scrubRoomActivity replaces the user's room usernames in the events and
+// system messages where they are the actor or the target.
+func scrubRoomActivity(tx *gorm.DB, userID string) error {
+	var (
+		userRooms  []UserRoom
+		events     []RoomEvent
+		messageIDs []int
+		names      = map[string]bool{}
+	)
+
+	if err := tx.Where("user_id = ?", userID).Find(&userRooms).Error; err != nil {
+		return err
+	}
+	for _, userRoom := range userRooms {
+		names[userRoom.Username] = true
+	}
+
+	if err := tx.Where("actor_id = ? OR target_id = ?", userID, userID).Find(&events).Error; err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		messageIDs = append(messageIDs, event.MessageID)
+
+		keys := namedKeys(event, userID)
+		if len(keys) == 0 {
+			continue
+		}
+
+		metadata := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(event.Metadata), &metadata); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if name, ok := metadata[key].(string); ok {
+				names[name] = true
+				metadata[key] = DeletedUsername
+			}
+		}
+
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&RoomEvent{}).Where("id = ?", event.ID).Update("metadata", string(data)).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	// names the user went by in rooms they have since left only survive on
+	// the system messages they authored
+	var authored []string
+	if err := tx.Model(&Message{}).Where("id IN ? AND user_id = ?", messageIDs, userID).Pluck("username", &authored).Error; err != nil {
+		return err
+	}
+	for _, name := range authored {
+		names[name] = true
+	}
+
+	delete(names, "")
+	delete(names, DeletedUsername)
+
+	for _, model := range []interface{}{&Message{}, &ArchivedMessage{}} {
+		if err := scrubContent(tx, model, messageIDs, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namedKeys lists the metadata keys of event that hold the user's name.
+func namedKeys(event RoomEvent, userID string) []string {
+	var keys []string
+
+	if event.TargetID != nil && *event.TargetID == userID {
+		keys = append(keys, "username")
+	}
+	if event.ActorID == userID && event.Type == RoomEventUsernameChanged {
+		keys = append(keys, "old_username", "new_username")
+	}
+	return keys
+}
+
+// scrubContent replaces whole-word occurrences of names in the content of
+// the given messages, longest name first so one name cannot hide another.
+func scrubContent(tx *gorm.DB, model interface{}, ids []int, names map[string]bool) error {
+	var rows []struct {
+		ID      int
+		Content string
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	patterns := make([]*regexp.Regexp, 0, len(sorted))
+	for _, name := range sorted {
+		patterns = append(patterns, regexp.MustCompile(`(^|\s)`+regexp.QuoteMeta(name)+`(\s|$)`))
+	}
+
+	if err := tx.Model(model).Where("id IN ?", ids).Select("id", "content").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		content := row.Content
+		for _, pattern := range patterns {
+			content = pattern.ReplaceAllString(content, "${1}"+DeletedUsername+"${2}")
+		}
+		if content == row.Content {
+			continue
+		}
+		if err := tx.Model(model).Where("id = ?", row.ID).Update("content", content).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// AccountExport is a user's request for a copy of their own data. It moves
+// through the same statuses as a RoomExport.
+type AccountExport struct {
+	ID                string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	UserID            string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status            string     `gorm:"column:status; type:varchar(20); not null; default:pending; index" json:"status"`
+	FilePath          string     `gorm:"column:file_path; type:text" json:"-"`
+	DownloadTokenHash string     `gorm:"column:download_token_hash; type:varchar(64); index" json:"-"`
+	Error             string     `gorm:"column:error; type:text" json:"error,omitempty"`
+	ExpiresAt         *time.Time `gorm:"column:expires_at" json:"expires_at,omitempty"`
+	CompletedAt       *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	ClaimedAt         *time.Time `gorm:"column:claimed_at" json:"-"`
+	CreatedAt         time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+}
+
+func (e *AccountExport) CreateAccountExport(db *gorm.DB) error {
+	e.Status = ExportPending
+	return postgresql.CreateOneRecord(db, e)
+}
+
+// HasExportInProgress reports whether userID already has an export waiting
+// to be built.
+func HasExportInProgress(db *gorm.DB, userID string) bool {
+	return postgresql.CheckExists(db, &AccountExport{}, "user_id = ? AND status IN ?", userID, []string{ExportPending, ExportProcessing})
+}
+
+func (e *AccountExport) GetAccountExportByToken(db *gorm.DB, id, tokenHash string) (AccountExport, error) {
+	var export AccountExport
+
+	err := db.Where("id = ? AND download_token_hash = ? AND status = ? AND expires_at > ?", id, tokenHash, ExportCompleted, time.Now()).First(&export).Error
+	if err != nil {
+		return export, err
+	}
+	return export, nil
+}
+
+// GetPendingAccountExports returns exports waiting to be built, including
+// those whose claim has timed out.
+func (e *AccountExport) GetPendingAccountExports(db *gorm.DB, limit int) ([]AccountExport, error) {
+	var exports []AccountExport
+
+	err := db.Order("created_at asc").
+		Where("status = ? OR (status = ? AND claimed_at <= ?)", ExportPending, ExportProcessing, time.Now().Add(-exportClaimTimeout)).
+		Limit(limit).Find(&exports).Error
+	if err != nil {
+		return exports, err
+	}
+	return exports, nil
+}
+
+// Claim moves a pending export, or one whose claim has timed out, to
+// processing so only one worker builds it.
+func (e *AccountExport) Claim(db *gorm.DB) (bool, error) {
+	now := time.Now()
+
+	result := db.Model(&AccountExport{}).
+		Where("id = ? AND (status = ? OR (status = ? AND claimed_at <= ?))",
+			e.ID, ExportPending, ExportProcessing, now.Add(-exportClaimTimeout)).
+		Updates(map[string]interface{}{"status": ExportProcessing, "claimed_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (e *AccountExport) Update(db *gorm.DB) error {
+	_, err := postgresql.SaveAllFields(db, e)
+	return err
+}
+
+// GetExpiredAccountExports returns completed exports whose link has expired.
+func (e *AccountExport) GetExpiredAccountExports(db *gorm.DB, now time.Time, limit int) ([]AccountExport, error) {
+	var exports []AccountExport
+
+	err := db.Where("status = ? AND expires_at <= ?", ExportCompleted, now).Limit(limit).Find(&exports).Error
+	return exports, err
+}
+
+// MarkExpired records that the export's file has been deleted.
+func (e *AccountExport) MarkExpired(db *gorm.DB) error {
+	return db.Model(&AccountExport{}).
+		Where("id = ?", e.ID).
+		Updates(map[string]interface{}{"status": ExportExpired, "file_path": "", "download_token_hash": ""}).Error
+}
+
+// EachUserMessage streams the messages userID wrote, in id order, batchSize
+// rows at a time.
+func EachUserMessage(db *gorm.DB, userID string, batchSize int, fn func(Message) error) error {
+	var (
+		batch  []Message
+		lastID int
+	)
+
+	for {
+		batch = batch[:0]
+		err := db.Where("user_id = ? AND id > ?", userID, lastID).Order("id asc").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			return err
+		}
+
+		for _, message := range batch {
+			if err := fn(message); err != nil {
+				return err
+			}
+			lastID = message.ID
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+func GetUserAccountExports(db *gorm.DB, userID string) ([]AccountExport, error) {
+	var exports []AccountExport
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "desc", &exports, "user_id = ?", userID)
+	return exports, err
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// APITokenPrefix marks personal access tokens so Authorize can tell them
+// apart from session JWTs.
+const APITokenPrefix = "tlx_"
+
+const (
+	ScopeRoomsRead       = "rooms:read"
+	ScopeRoomsWrite      = "rooms:write"
+	ScopeMessagesRead    = "messages:read"
+	ScopeMessagesWrite   = "messages:write"
+	ScopeWorkspacesRead  = "workspaces:read"
+	ScopeWorkspacesWrite = "workspaces:write"
+	ScopeAdmin           = "admin"
+)
+
+// APIToken is a personal access token for scripts and bots. Only its hash is
+// stored; Prefix is kept so users can tell their tokens apart.
+type APIToken struct {
+	ID         string     `gorm:"column:id; type:uuid; not null; primaryKey; unique;" json:"id"`
+	UserID     string     `gorm:"column:user_id; type:uuid; not null; index" json:"user_id"`
+	Name       string     `gorm:"column:name; type:varchar(100); not null" json:"name"`
+	Prefix     string     `gorm:"column:prefix; type:varchar(16); not null" json:"prefix"`
+	TokenHash  string     `gorm:"column:token_hash; type:varchar(64); not null; uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"column:scopes; type:text; serializer:json; not null" json:"scopes"`
+	AllowedIPs []string   `gorm:"column:allowed_ips; type:text; serializer:json" json:"allowed_ips"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	LastUsedIP string     `gorm:"column:last_used_ip; type:varchar(64)" json:"last_used_ip"`
+	CreatedAt  time.Time  `gorm:"column:created_at; autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at; autoUpdateTime" json:"updated_at"`
+}
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=rooms:read rooms:write messages:read messages:write workspaces:read workspaces:write admin"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+	AllowedIPs    []string `json:"allowed_ips" validate:"omitempty,dive,ip|cidr"`
+}
+
+func (t *APIToken) CreateAPIToken(db *gorm.DB) error {
+	return postgresql.CreateOneRecord(db, t)
+}
+
+func (t *APIToken) GetByTokenHash(db *gorm.DB, hash string) (int, error) {
+	err, nilErr := postgresql.SelectOneFromDb(db, &t, "token_hash = ?", hash)
+	if nilErr != nil {
+		return http.StatusUnauthorized, nilErr
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func GetUserAPITokens(db *gorm.DB, userID string) ([]APIToken, error) {
+	var tokens []APIToken
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "desc", &tokens, "user_id = ?", userID)
+	return tokens, err
+}
+
+func DeleteAPIToken(db *gorm.DB, userID, tokenID string) (int, error) {
+	result := db.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&APIToken{})
+	if result.Error != nil {
+		return http.StatusInternalServerError, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return http.StatusNotFound, errors.New("api token not found")
+	}
+	return http.StatusOK, nil
+}
+
+func (t *APIToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether ip matches one of the token's addresses or
+// ranges. A token without restrictions may be used from anywhere.
+func (t *APIToken) AllowsIP(ip string) bool {
+	if len(t.AllowedIPs) == 0 {
+		return true
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, allowed := range t.AllowedIPs {
+		if strings.Contains(allowed, "/") {
+			_, network, err := net.ParseCIDR(allowed)
+			if err == nil && network.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if other := net.ParseIP(allowed); other != nil && other.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// TouchLastUsed records use of the token from ip, at most once per
+// lastUsedResolution unless the address changed.
+func (t *APIToken) TouchLastUsed(db *gorm.DB, ip string) error {
+	now := time.Now()
+
+	if t.LastUsedAt != nil && now.Sub(*t.LastUsedAt) < lastUsedResolution && t.LastUsedIP == ip {
+		return nil
+	}
+
+	t.LastUsedAt = &now
+	t.LastUsedIP = ip
+	_, err := postgresql.UpdateFields(db, &APIToken{}, map[string]interface{}{"last_used_at": now, "last_used_ip": ip}, "id = ?", t.ID)
+	return err
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// EmailChange is a request to move an account to a new address. The new
+// address confirms it and the old one can revert it; only the hashes of
+// both tokens are stored.
+type EmailChange struct {
+	ID               string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	UserID           string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	OldEmail         string     `gorm:"column:old_email; type:varchar(255); not null" json:"old_email"`
+	NewEmail         string     `gorm:"column:new_email; type:varchar(255); not null" json:"new_email"`
+	ConfirmTokenHash string     `gorm:"column:confirm_token_hash; type:varchar(64); not null; uniqueIndex" json:"-"`
+	RevertTokenHash  string     `gorm:"column:revert_token_hash; type:varchar(64); not null; uniqueIndex" json:"-"`
+	ExpiresAt        time.Time  `gorm:"column:expires_at; not null" json:"expires_at"`
+	RevertExpiresAt  time.Time  `gorm:"column:revert_expires_at; not null" json:"-"`
+	ConfirmedAt      *time.Time `gorm:"column:confirmed_at" json:"confirmed_at,omitempty"`
+	RevertedAt       *time.Time `gorm:"column:reverted_at" json:"reverted_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (e *EmailChange) CreateEmailChange(db *gorm.DB) error {
+	return postgresql.CreateOneRecord(db, e)
+}
+
+// CancelPendingEmailChanges drops the user's unconfirmed requests so only the
+// latest link works.
+func CancelPendingEmailChanges(db *gorm.DB, userID string) error {
+	return db.Where("user_id = ? AND confirmed_at IS NULL AND reverted_at IS NULL", userID).Delete(&EmailChange{}).Error
+}
+
+// GetPendingByConfirmToken finds the user's unconfirmed, unexpired change for
+// token.
+func (e *EmailChange) GetPendingByConfirmToken(db *gorm.DB, userID, tokenHash string) (int, error) {
+	err := db.Where("user_id = ? AND confirm_token_hash = ? AND confirmed_at IS NULL AND reverted_at IS NULL AND expires_at > ?", userID, tokenHash, time.Now()).First(e).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusUnauthorized, errors.New("invalid or expired token")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func (e *EmailChange) GetByRevertToken(db *gorm.DB, tokenHash string) (int, error) {
+	err := db.Where("revert_token_hash = ? AND reverted_at IS NULL AND revert_expires_at > ?", tokenHash, time.Now()).First(e).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return http.StatusUnauthorized, errors.New("invalid or expired token")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// EmailTaken reports whether another account already uses email.
+func EmailTaken(db *gorm.DB, email, exceptUserID string) bool {
+	return postgresql.CheckExists(db, &User{}, "LOWER(email) = ? AND id <> ?", strings.ToLower(email), exceptUserID)
+}
+
+// SetEmail moves the account to email, which the caller has just proven the
+// user controls.
+func (u *User) SetEmail(db *gorm.DB, email string) error {
+	u.Email = email
+	u.IsVerified = true
+	_, err := postgresql.UpdateFields(db, &User{}, map[string]interface{}{"email": email, "is_verified": true}, "id = ?", u.ID)
+	return err
+}
+
+func (e *EmailChange) MarkConfirmed(db *gorm.DB) error {
+	now := time.Now()
+	e.ConfirmedAt = &now
+	_, err := postgresql.UpdateFields(db, &EmailChange{}, map[string]interface{}{"confirmed_at": now}, "id = ?", e.ID)
+	return err
+}
+
+func (e *EmailChange) MarkReverted(db *gorm.DB) error {
+	now := time.Now()
+	e.RevertedAt = &now
+	_, err := postgresql.UpdateFields(db, &EmailChange{}, map[string]interface{}{"reverted_at": now}, "id = ?", e.ID)
+	return err
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+const (
+	ImportPending    = "pending"
+	ImportProcessing = "processing"
+	ImportCompleted  = "completed"
+	ImportFailed     = "failed"
+
+	ImportProviderSlack   = "slack"
+	ImportProviderDiscord = "discord"
+
+	ImportRefUser    = "user"
+	ImportRefRoom    = "room"
+	ImportRefMessage = "message"
+)
+
+// ImportRef maps an object from an external export to the record it was
+// imported as, which is what lets an import be re-run safely.
+type ImportRef struct {
+	Provider   string    `gorm:"column:provider; type:varchar(20); primaryKey" json:"provider"`
+	Kind       string    `gorm:"column:kind; type:varchar(20); primaryKey" json:"kind"`
+	ExternalID string    `gorm:"column:external_id; type:varchar(255); primaryKey" json:"external_id"`
+	InternalID string    `gorm:"column:internal_id; type:varchar(255); not null" json:"internal_id"`
+	CreatedAt  time.Time `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+}
+
+type ImportJob struct {
+	ID              string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	Provider        string     `gorm:"column:provider; type:varchar(20); not null" json:"provider"`
+	RequestedBy     string     `gorm:"type:uuid;not null" json:"requested_by"`
+	FilePath        string     `gorm:"column:file_path; type:text; not null" json:"-"`
+	Status          string     `gorm:"column:status; type:varchar(20); not null; default:pending; index" json:"status"`
+	Error           string     `gorm:"column:error; type:text" json:"error,omitempty"`
+	RoomsCreated    int        `gorm:"column:rooms_created; not null; default:0" json:"rooms_created"`
+	UsersCreated    int        `gorm:"column:users_created; not null; default:0" json:"users_created"`
+	MessagesCreated int        `gorm:"column:messages_created; not null; default:0" json:"messages_created"`
+	MessagesSkipped int        `gorm:"column:messages_skipped; not null; default:0" json:"messages_skipped"`
+	CompletedAt     *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+}
+
+func (j *ImportJob) CreateImportJob(db *gorm.DB) error {
+	j.Status = ImportPending
+
+	err := postgresql.CreateOneRecord(db, j)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (j *ImportJob) GetImportJobByID(db *gorm.DB, id string) (ImportJob, int, error) {
+	var job ImportJob
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &job, "id = ?", id)
+	if nilErr != nil {
+		return job, http.StatusNotFound, errors.New("import not found")
+	}
+	if err != nil {
+		return job, http.StatusInternalServerError, err
+	}
+
+	return job, http.StatusOK, nil
+}
+
+func (j *ImportJob) GetPendingImportJobs(db *gorm.DB, limit int) ([]ImportJob, error) {
+	var jobs []ImportJob
+
+	err := db.Order("created_at asc").Where("status = ?", ImportPending).Limit(limit).Find(&jobs).Error
+	if err != nil {
+		return jobs, err
+	}
+	return jobs, nil
+}
+
+// Claim moves a pending import to processing so only one worker runs it.
+func (j *ImportJob) Claim(db *gorm.DB) (bool, error) {
+	result := db.Model(&ImportJob{}).
+		Where("id = ? AND status = ?", j.ID, ImportPending).
+		Update("status", ImportProcessing)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (j *ImportJob) Update(db *gorm.DB) error {
+	_, err := postgresql.SaveAllFields(db, j)
+	return err
+}
+
+func (r *ImportRef) CreateImportRef(db *gorm.DB) error {
+	err := postgresql.CreateOneRecord(db, r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ImportRef) GetImportRef(db *gorm.DB, provider, kind, externalID string) (ImportRef, error) {
+	var ref ImportRef
+
+	err := db.Where("provider = ? AND kind = ? AND external_id = ?", provider, kind, externalID).First(&ref).Error
+	if err != nil {
+		return ref, err
+	}
+	return ref, nil
+}
+
+// GetImportRefs returns the internal ids already recorded for externalIDs,
+// keyed by external id.
+func (r *ImportRef) GetImportRefs(db *gorm.DB, provider, kind string, externalIDs []string) (map[string]string, error) {
+	var (
+		refs   []ImportRef
+		result = make(map[string]string, len(externalIDs))
+	)
+
+	if len(externalIDs) == 0 {
+		return result, nil
+	}
+
+	err := db.Where("provider = ? AND kind = ? AND external_id IN ?", provider, kind, externalIDs).Find(&refs).Error
+	if err != nil {
+		return result, err
+	}
+
+	for _, ref := range refs {
+		result[ref.ExternalID] = ref.InternalID
+	}
+	return result, nil
+}
@@ -12,10 +12,11 @@ import (
 type Message struct {
 	ID        int       `gorm:"column:id; type:serial; primaryKey" json:"id"`
 	Content   string    `gorm:"column:content; type:text; not null" json:"content"`
-	RoomID    string    `gorm:"type:uuid;not null" json:"room_id"`
+	RoomID    string    `gorm:"type:uuid;not null;index:idx_message_room_created" json:"room_id"`
 	UserID    string    `gorm:"type:uuid;not null" json:"user_id"`
 	Username  string    `gorm:"column:username; type:varchar(255)" json:"username"`
-	CreatedAt time.Time `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	Type      string    `gorm:"column:type; type:varchar(30); not null; default:message" json:"type"`
+	CreatedAt time.Time `gorm:"column:created_at; not null; autoCreateTime; index:idx_message_room_created" json:"created_at"`
 }
 
 type CreateMessageRequest struct {
@@ -13,6 +13,26 @@ func AuthMigrationModels() []interface{} {
 		models.Message{},
 		models.MagicLink{},
 		models.PasswordReset{},
+		models.ScheduledMessage{},
+		models.ArchivedMessage{},
+		models.RoomExport{},
+		models.ImportRef{},
+		models.ImportJob{},
+		models.RoomWordFilter{},
+		models.MessageReport{},
+		models.RoomEvent{},
+		models.Workspace{},
+		models.WorkspaceMember{},
+		models.WorkspaceInvite{},
+		models.WorkspaceDomain{},
+		models.TwoFactor{},
+		models.RecoveryCode{},
+		models.UserIdentity{},
+		models.APIToken{},
+		models.AccountExport{},
+		models.EmailChange{},
+		models.SecurityEvent{},
+		models.SigningKey{},
 	} // an array of db models, example: User{}
 }
 
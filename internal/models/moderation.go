@@ -0,0 +1,185 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+const (
+	FilterActionBlock = "block"
+	FilterActionMask  = "mask"
+	FilterActionAllow = "allow"
+
+	ReportSourceUser   = "user"
+	ReportSourceFilter = "filter"
+
+	ReportOpen      = "open"
+	ReportDismissed = "dismissed"
+	ReportRemoved   = "removed"
+)
+
+// RoomWordFilter overrides the global blocklist for one room: block and mask
+// add a word, allow exempts a globally blocked one.
+type RoomWordFilter struct {
+	ID        string    `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	RoomID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_room_word" json:"room_id"`
+	Word      string    `gorm:"column:word; type:varchar(255); not null; uniqueIndex:idx_room_word" json:"word"`
+	Action    string    `gorm:"column:action; type:varchar(10); not null" json:"action"`
+	CreatedBy string    `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+}
+
+// MessageReport keeps a copy of the reported content so the queue still makes
+// sense after the message is removed or purged.
+type MessageReport struct {
+	ID         string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	MessageID  int        `gorm:"column:message_id; not null; index" json:"message_id"`
+	RoomID     string     `gorm:"type:uuid;not null;index" json:"room_id"`
+	ReporterID *string    `gorm:"type:uuid" json:"reporter_id"`
+	AuthorID   string     `gorm:"type:uuid;not null" json:"author_id"`
+	Content    string     `gorm:"column:content; type:text; not null" json:"content"`
+	Source     string     `gorm:"column:source; type:varchar(10); not null" json:"source"`
+	Reason     string     `gorm:"column:reason; type:text; not null" json:"reason"`
+	Status     string     `gorm:"column:status; type:varchar(20); not null; default:open; index" json:"status"`
+	ResolvedBy *string    `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at" json:"resolved_at,omitempty"`
+	Note       string     `gorm:"column:note; type:text" json:"note,omitempty"`
+	CreatedAt  time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+}
+
+type CreateWordFilterRequest struct {
+	Word   string `json:"word" validate:"required,max=255"`
+	Action string `json:"action" validate:"required,oneof=block mask allow"`
+}
+
+type CreateReportRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+type ResolveReportRequest struct {
+	Action string `json:"action" validate:"required,oneof=dismiss remove"`
+	Note   string `json:"note" validate:"max=1000"`
+}
+
+func (f *RoomWordFilter) CreateWordFilter(db *gorm.DB) error {
+	f.Word = strings.ToLower(strings.TrimSpace(f.Word))
+
+	exists := postgresql.CheckExists(db, &RoomWordFilter{}, "room_id = ? AND word = ?", f.RoomID, f.Word)
+	if exists {
+		return errors.New("word filter already exists")
+	}
+
+	err := postgresql.CreateOneRecord(db, f)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f *RoomWordFilter) GetWordFiltersByRoomID(db *gorm.DB, roomID string) ([]RoomWordFilter, error) {
+	var filters []RoomWordFilter
+
+	err := postgresql.SelectAllFromDbOrderBy(db, "word", "asc", &filters, "room_id = ?", roomID)
+	if err != nil {
+		return filters, err
+	}
+	return filters, nil
+}
+
+func (f *RoomWordFilter) DeleteWordFilter(db *gorm.DB, id, roomID string) (int, error) {
+	var filter RoomWordFilter
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &filter, "id = ? AND room_id = ?", id, roomID)
+	if nilErr != nil {
+		return http.StatusNotFound, errors.New("word filter not found")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = postgresql.DeleteRecordFromDb(db, &filter)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func (r *MessageReport) CreateReport(db *gorm.DB) error {
+	r.Status = ReportOpen
+
+	err := postgresql.CreateOneRecord(db, r)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetModerationQueue lists reports with the given status, newest first. An
+// empty roomID returns the queue across every room.
+func (r *MessageReport) GetModerationQueue(db *gorm.DB, roomID, status string) ([]MessageReport, error) {
+	var reports []MessageReport
+
+	query := db.Where("status = ?", status)
+	if roomID != "" {
+		query = query.Where("room_id = ?", roomID)
+	}
+
+	err := query.Order("created_at desc").Find(&reports).Error
+	if err != nil {
+		return reports, err
+	}
+	return reports, nil
+}
+
+func (r *MessageReport) GetReportByID(db *gorm.DB, id string) (MessageReport, int, error) {
+	var report MessageReport
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &report, "id = ?", id)
+	if nilErr != nil {
+		return report, http.StatusNotFound, errors.New("report not found")
+	}
+	if err != nil {
+		return report, http.StatusInternalServerError, err
+	}
+	return report, http.StatusOK, nil
+}
+
+// Resolve closes the report and, for removals, deletes the reported message
+// and closes every other open report against it.
+func (r *MessageReport) Resolve(db *gorm.DB, req ResolveReportRequest, userID string) error {
+	now := time.Now()
+
+	status := ReportDismissed
+	if req.Action == "remove" {
+		status = ReportRemoved
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&MessageReport{}).Where("id = ?", r.ID)
+		if status == ReportRemoved {
+			query = tx.Model(&MessageReport{}).Where("message_id = ? AND status = ?", r.MessageID, ReportOpen)
+		}
+
+		err := query.Updates(map[string]interface{}{
+			"status":      status,
+			"resolved_by": userID,
+			"resolved_at": now,
+			"note":        req.Note,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if status == ReportRemoved {
+			return tx.Where("id = ?", r.MessageID).Delete(&Message{}).Error
+		}
+		return nil
+	})
+}
@@ -17,7 +17,7 @@ type NotificationRecord struct {
 
 type SendOTP struct {
 	Email    string `json:"email"  validate:"required"`
-	OtpToken int    `json:"otp_token"  validate:"required"`
+	OtpToken string `json:"otp_token"  validate:"required"`
 }
 
 type SendWelcomeMail struct {
@@ -45,6 +45,63 @@ type SendSqueeze struct {
 	FirstName string `json:"first_name" validate:"required"`
 	LastName  string `json:"last_name" validate:"required"`
 }
+type SendRoomExportReady struct {
+	Email        string `json:"email"  validate:"required"`
+	RoomName     string `json:"room_name"`
+	DownloadLink string `json:"download_link"  validate:"required"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+type SendWorkspaceInvite struct {
+	Email         string `json:"email"  validate:"required"`
+	WorkspaceName string `json:"workspace_name"`
+	InviteLink    string `json:"invite_link"  validate:"required"`
+	ExpiresAt     string `json:"expires_at"`
+}
+
+type SendAccountLocked struct {
+	Email       string `json:"email"  validate:"required"`
+	IPAddress   string `json:"ip_address"`
+	LockedUntil string `json:"locked_until"`
+}
+
+type SendAccountExportReady struct {
+	Email        string `json:"email"  validate:"required"`
+	DownloadLink string `json:"download_link"  validate:"required"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+type SendAccountDeletion struct {
+	Email       string `json:"email"  validate:"required"`
+	DeleteAfter string `json:"delete_after"`
+}
+
+// SendEmailChangeConfirm goes to an address no account uses yet, so it
+// carries the name to greet instead of looking the user up.
+type SendEmailChangeConfirm struct {
+	Email       string `json:"email"  validate:"required"`
+	FirstName   string `json:"first_name"`
+	ConfirmLink string `json:"confirm_link"  validate:"required"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+type SendEmailChangeNotice struct {
+	Email      string `json:"email"  validate:"required"`
+	FirstName  string `json:"first_name"`
+	NewEmail   string `json:"new_email"`
+	RevertLink string `json:"revert_link"  validate:"required"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+type SendNewSignIn struct {
+	Email     string `json:"email"  validate:"required"`
+	IPAddress string `json:"ip_address"`
+	Location  string `json:"location"`
+	UserAgent string `json:"user_agent"`
+	Time      string `json:"time"`
+	NotMeLink string `json:"not_me_link"  validate:"required"`
+}
+
 type SendContactUsMail struct {
 	Name    string `json:"name"  validate:"required"`
 	Email   string `json:"email" `
@@ -52,6 +109,40 @@ type SendContactUsMail struct {
 	Message string `json:"message" validate:"required"`
 }
 
+// QueuedNotification is what admins see of a pending notification. The
+// payload is left out since it can carry login links and codes.
+type QueuedNotification struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// PeekNotificationQueue returns the next limit notifications due to be sent
+// and how many are waiting in total.
+func PeekNotificationQueue(rdb *redis.Client, limit int64) ([]QueuedNotification, int64, error) {
+	entries, length, err := dbRedis.PeekQueue(rdb, limit)
+	if err != nil {
+		return nil, length, err
+	}
+
+	queued := make([]QueuedNotification, 0, len(entries))
+	for _, entry := range entries {
+		var (
+			rec  NotificationRecord
+			data struct {
+				Email string `json:"email"`
+			}
+		)
+
+		if err := json.Unmarshal([]byte(entry), &rec); err != nil {
+			continue
+		}
+		_ = json.Unmarshal([]byte(rec.Data), &data)
+
+		queued = append(queued, QueuedNotification{Name: rec.Name, Email: data.Email})
+	}
+	return queued, length, nil
+}
+
 func (n *NotificationRecord) PushToQueue(rdb *redis.Client) error {
 	err := dbRedis.PushToQueue(rdb, &n)
 
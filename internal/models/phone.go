@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// A profile's PhoneE164 is its Phone in international form, for sending
+// texts to. Once PhoneVerifiedAt is set the number can be signed in with.
+
+type PhoneCodeRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,max=30"`
+}
+
+type PhoneCodeVerifyRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,max=30"`
+	Code        string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// GetProfileByVerifiedPhone returns the profile that verified phone, given
+// in E.164 form.
+func GetProfileByVerifiedPhone(db *gorm.DB, phone string) (Profile, error) {
+	var profile Profile
+
+	err, _ := postgresql.SelectOneFromDb(db, &profile, "phone_e164 = ? AND phone_verified_at IS NOT NULL", phone)
+	return profile, err
+}
+
+// PhoneVerifiedByOther reports whether a user other than exceptUserID has
+// verified phone.
+func PhoneVerifiedByOther(db *gorm.DB, phone, exceptUserID string) bool {
+	return postgresql.CheckExists(db, &Profile{}, "phone_e164 = ? AND phone_verified_at IS NOT NULL AND userid <> ?", phone, exceptUserID)
+}
+
+// SetVerifiedPhone stores phone as the user's verified number.
+func (p *Profile) SetVerifiedPhone(db *gorm.DB, userID, phone, phoneE164 string) error {
+	now := time.Now()
+
+	p.Phone = phone
+	p.PhoneE164 = phoneE164
+	p.PhoneVerifiedAt = &now
+	return p.UpdateByUserID(db, userID, map[string]interface{}{"phone": phone, "phone_e164": phoneE164, "phone_verified_at": now})
+}
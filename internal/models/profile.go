@@ -8,15 +8,17 @@ import (
 )
 
 type Profile struct {
-	ID        string         `gorm:"type:uuid;primary_key" json:"profile_id"`
-	FirstName string         `gorm:"column:first_name; type:text; not null" json:"first_name"`
-	LastName  string         `gorm:"column:last_name; type:text;not null" json:"last_name"`
-	Phone     string         `gorm:"type:varchar(255)" json:"phone"`
-	AvatarURL string         `gorm:"type:varchar(255)" json:"avatar_url"`
-	Userid    string         `gorm:"type:uuid;" json:"user_id"`
-	CreatedAt time.Time      `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time      `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              string         `gorm:"type:uuid;primary_key" json:"profile_id"`
+	FirstName       string         `gorm:"column:first_name; type:text; not null" json:"first_name"`
+	LastName        string         `gorm:"column:last_name; type:text;not null" json:"last_name"`
+	Phone           string         `gorm:"type:varchar(255)" json:"phone"`
+	PhoneE164       string         `gorm:"column:phone_e164; type:varchar(20); index; uniqueIndex:idx_profile_verified_phone,where:phone_verified_at IS NOT NULL AND deleted_at IS NULL" json:"-"`
+	PhoneVerifiedAt *time.Time     `gorm:"column:phone_verified_at" json:"phone_verified_at"`
+	AvatarURL       string         `gorm:"type:varchar(255)" json:"avatar_url"`
+	Userid          string         `gorm:"type:uuid;" json:"user_id"`
+	CreatedAt       time.Time      `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (p *Profile) GetUserProfile(db *gorm.DB, userID string) (Profile, error) {
@@ -28,3 +30,13 @@ func (p *Profile) GetUserProfile(db *gorm.DB, userID string) (Profile, error) {
 	}
 	return profile, nil
 }
+
+func PhoneTaken(db *gorm.DB, phone, exceptUserID string) bool {
+	return postgresql.CheckExists(db, &Profile{}, "phone = ? AND userid <> ?", phone, exceptUserID)
+}
+
+// UpdateByUserID writes updates to the user's profile.
+func (p *Profile) UpdateByUserID(db *gorm.DB, userID string, updates map[string]interface{}) error {
+	_, err := postgresql.UpdateFields(db, &Profile{}, updates, "userid = ?", userID)
+	return err
+}
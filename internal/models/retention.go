@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+type ArchivedMessage struct {
+	ID         int       `gorm:"column:id; primaryKey; autoIncrement:false" json:"id"`
+	Content    string    `gorm:"column:content; type:text; not null" json:"content"`
+	RoomID     string    `gorm:"type:uuid;not null;index" json:"room_id"`
+	UserID     string    `gorm:"type:uuid;not null" json:"user_id"`
+	Username   string    `gorm:"column:username; type:varchar(255)" json:"username"`
+	Type       string    `gorm:"column:type; type:varchar(30); not null; default:message" json:"type"`
+	CreatedAt  time.Time `gorm:"column:created_at; not null" json:"created_at"`
+	ArchivedAt time.Time `gorm:"column:archived_at; not null; autoCreateTime" json:"archived_at"`
+}
+
+type UpdateRoomRetentionRequest struct {
+	RetentionDays *int  `json:"retention_days" validate:"omitempty,min=0"`
+	LegalHold     *bool `json:"legal_hold"`
+}
+
+// UpdateRetention lets the room owner set the retention period. Legal hold
+// can only be set or lifted by platform admins, since a hold the room's
+// owner could lift would not hold anything.
+func (r *Room) UpdateRetention(db *gorm.DB, req UpdateRoomRetentionRequest, roomID, userID string) (Room, int, error) {
+	var room Room
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomID)
+	if !exists {
+		return room, http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	isAdmin := IsPlatformAdmin(db, userID)
+	if room.OwnerId != userID && !isAdmin {
+		return room, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	if req.LegalHold != nil && !isAdmin {
+		return room, http.StatusForbidden, errors.New("only platform admins can change legal hold")
+	}
+
+	updates := map[string]interface{}{}
+	if req.RetentionDays != nil {
+		updates["retention_days"] = *req.RetentionDays
+	}
+	if req.LegalHold != nil {
+		updates["legal_hold"] = *req.LegalHold
+	}
+
+	if len(updates) > 0 {
+		_, err := postgresql.UpdateFields(db, &Room{}, updates, "id = ?", roomID)
+		if err != nil {
+			return room, http.StatusInternalServerError, err
+		}
+	}
+
+	updatedRoom := Room{}
+	err := db.First(&updatedRoom, "id = ?", roomID).Error
+	if err != nil {
+		return room, http.StatusInternalServerError, err
+	}
+	return updatedRoom, http.StatusOK, nil
+}
+
+// GetRoomsForRetention returns every room that is subject to purging: rooms
+// on legal hold are skipped, as are rooms without their own retention period
+// when no global default is configured.
+func (r *Room) GetRoomsForRetention(db *gorm.DB, defaultDays int) ([]Room, error) {
+	var rooms []Room
+
+	query := db.Select("id", "retention_days").Where("legal_hold = ?", false)
+	if defaultDays <= 0 {
+		query = query.Where("retention_days > 0")
+	}
+
+	err := query.Find(&rooms).Error
+	if err != nil {
+		return rooms, err
+	}
+	return rooms, nil
+}
+
+// PurgeMessagesBefore removes at most batchSize messages of the room created
+// before cutoff, copying them into archived_messages first when archive is set.
+// Each call runs in its own short transaction so the messages table is never
+// locked for longer than a single batch.
+func (r *Room) PurgeMessagesBefore(db *gorm.DB, cutoff time.Time, batchSize int, archive bool) (int64, error) {
+	var purged int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []int
+
+		err := tx.Model(&Message{}).
+			Where("room_id = ? AND created_at < ?", r.ID, cutoff).
+			Order("id asc").
+			Limit(batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if archive {
+			err = tx.Exec(`INSERT INTO archived_messages (id, content, room_id, user_id, username, type, created_at, archived_at)
+				SELECT id, content, room_id, user_id, username, type, created_at, ? FROM messages WHERE id IN ?
+				ON CONFLICT (id) DO NOTHING`, time.Now(), ids).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Where("id IN ?", ids).Delete(&Message{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		purged = result.RowsAffected
+		return nil
+	})
+
+	return purged, err
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -12,20 +13,26 @@ import (
 )
 
 type Room struct {
-	ID          string    `gorm:"type:uuid;primary_key" json:"room_id"`
-	Name        string    `gorm:"column:name;unique type:text; not null" json:"name"`
-	Description string    `gorm:"column:description; type:text; not null" json:"description"`
-	OwnerId     string    `gorm:"column:owner_id; type:uuid" json:"owner_id"`
-	Users       []User    `gorm:"many2many:user_rooms;" json:"users"`
-	UserCount   int64     `gorm:"-" json:"user_count"`
-	CreatedAt   time.Time `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
-	DeletedAt   time.Time `gorm:"column: deleted_at; not null; autoDeleteTime" json:"deleted_at"`
+	ID               string    `gorm:"type:uuid;primary_key" json:"room_id"`
+	WorkspaceID      *string   `gorm:"type:uuid;index;uniqueIndex:idx_workspace_room_name" json:"workspace_id"`
+	Name             string    `gorm:"column:name; type:text; not null; uniqueIndex:idx_workspace_room_name; uniqueIndex:idx_room_name_no_workspace,where:workspace_id IS NULL" json:"name"`
+	Description      string    `gorm:"column:description; type:text; not null" json:"description"`
+	OwnerId          string    `gorm:"column:owner_id; type:uuid" json:"owner_id"`
+	Users            []User    `gorm:"many2many:user_rooms;" json:"users"`
+	UserCount        int64     `gorm:"-" json:"user_count"`
+	RetentionDays    int       `gorm:"column:retention_days; type:int; not null; default:0" json:"retention_days"`
+	LegalHold        bool      `gorm:"column:legal_hold; type:bool; not null; default:false" json:"legal_hold"`
+	SlowModeInterval int       `gorm:"column:slow_mode_interval; type:int; not null; default:0" json:"slow_mode_interval"`
+	SlowModeBurst    int       `gorm:"column:slow_mode_burst; type:int; not null; default:1" json:"slow_mode_burst"`
+	CreatedAt        time.Time `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	DeletedAt        time.Time `gorm:"column: deleted_at; not null; autoDeleteTime" json:"deleted_at"`
 }
 
 type UserRoom struct {
 	RoomID    string    `gorm:"type:uuid;primaryKey;not null" json:"room_id"`
 	UserID    string    `gorm:"type:uuid;primaryKey;not null" json:"user_id"`
 	Username  string    `gorm:"column:username; type:varchar(255)" json:"username"`
+	Role      string    `gorm:"column:role; type:varchar(20); not null; default:member" json:"role"`
 	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime" json:"created_at"`
 	DeletedAt time.Time `gorm:"index" json:"deleted_at"`
 }
@@ -34,6 +41,7 @@ type CreateRoomRequest struct {
 	Username    string `json:"username" validate:"required"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	WorkspaceID string `json:"-"`
 }
 
 type GetRoomRequest struct {
@@ -55,7 +63,35 @@ type UpdateRoomUserNameReq struct {
 	Username string `json:"username" validate:"required"`
 }
 
+// InWorkspace scopes a room query to one workspace. An empty workspaceID
+// selects the rooms that belong to no workspace.
+func InWorkspace(db *gorm.DB, workspaceID string) *gorm.DB {
+	if workspaceID == "" {
+		return db.Where("workspace_id IS NULL")
+	}
+	return db.Where("workspace_id = ?", workspaceID)
+}
+
+// NameTaken reports whether another room in the same workspace already uses
+// name.
+func (r *Room) NameTaken(db *gorm.DB, name string) bool {
+	var workspaceID string
+	if r.WorkspaceID != nil {
+		workspaceID = *r.WorkspaceID
+	}
+
+	query := InWorkspace(db, workspaceID)
+	if r.ID != "" {
+		query = query.Where("id <> ?", r.ID)
+	}
+	return postgresql.CheckExists(query, &Room{}, "name = ?", name)
+}
+
 func (r *Room) CreateRoom(db *gorm.DB) error {
+	if r.NameTaken(db, r.Name) {
+		return errors.New("room name already exists")
+	}
+
 	err := postgresql.CreateOneRecord(db, r)
 	if err != nil {
 		return err
@@ -81,15 +117,15 @@ func (r *Room) GetRoomUsersByID(db *gorm.DB, roomID string) ([]UserRoom, error)
 	return users, nil
 }
 
-func (r *Room) GetRoomByName(db *gorm.DB, name string) (Room, error) {
+func (r *Room) GetRoomByName(db *gorm.DB, workspaceID, name string) (Room, error) {
 	var room Room
 
-	exists := postgresql.CheckExists(db, &room, "name= ?", name)
+	exists := postgresql.CheckExists(InWorkspace(db, workspaceID), &room, "name= ?", name)
 	if !exists {
 		return room, errors.New("room not found")
 	}
 
-	err, _ := postgresql.SelectOneFromDb(db, &room, "name= ?", name)
+	err, _ := postgresql.SelectOneFromDb(InWorkspace(db, workspaceID), &room, "name= ?", name)
 	if err != nil {
 		return room, err
 	}
@@ -116,13 +152,13 @@ func (r *Room) GetRoomByID(db *gorm.DB, roomID string) (Room, error) {
 	return room, nil
 }
 
-func (r *Room) GetRooms(db *gorm.DB) ([]Room, error) {
+func (r *Room) GetRooms(db *gorm.DB, workspaceID string) ([]Room, error) {
 	var (
 		rooms []Room
 		ur    UserRoom
 	)
 
-	err := postgresql.SelectAllFromDb(db.Preload("Users"), "", &rooms, "")
+	err := postgresql.SelectAllFromDb(InWorkspace(db, workspaceID).Preload("Users"), "", &rooms, "")
 	if err != nil {
 		return rooms, err
 	}
@@ -170,29 +206,30 @@ func (r *Room) GetRoomMessages(db *gorm.DB, userID, roomID string) ([]Message, e
 	return messages, nil
 }
 
-func (r *Room) AddUserToRoom(db *gorm.DB, req JoinRoomRequest) error {
+func (r *Room) AddUserToRoom(db *gorm.DB, req JoinRoomRequest) (RoomActivity, error) {
 
 	var (
-		user   User
-		room   Room
-		userID = req.UserID
-		roomID = req.RoomID
+		user     User
+		room     Room
+		activity RoomActivity
+		userID   = req.UserID
+		roomID   = req.RoomID
 	)
 
 	exists := postgresql.CheckExists(db, &user, "id = ?", userID)
 	if !exists {
-		return errors.New("user does not exist")
+		return activity, errors.New("user does not exist")
 	}
 
 	exists = postgresql.CheckExists(db, &room, "id = ?", roomID)
 	if !exists {
-		return errors.New("room does not exist")
+		return activity, errors.New("room does not exist")
 	}
 
 	var userRoom UserRoom
 	exist := postgresql.CheckExists(db, &userRoom, "room_id = ? AND user_id = ?", roomID, userID)
 	if exist {
-		return errors.New("user already in room")
+		return activity, errors.New("user already in room")
 	}
 
 	userRoom = UserRoom{
@@ -201,49 +238,91 @@ func (r *Room) AddUserToRoom(db *gorm.DB, req JoinRoomRequest) error {
 		Username: req.Username,
 	}
 
-	err := postgresql.CreateOneRecord(db, &userRoom)
-	if err != nil {
-		return errors.New("could not add user to room")
-	}
-	return nil
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := postgresql.CreateOneRecord(tx, &userRoom)
+		if err != nil {
+			return errors.New("could not add user to room")
+		}
+
+		activity, err = recordRoomActivity(tx, roomID, RoomEventMemberJoined, userID, req.Username, "",
+			fmt.Sprintf("%v joined the room", req.Username), nil)
+		return err
+	})
+	return activity, err
 }
 
-func (r *Room) RemoveUserFromRoom(db *gorm.DB, roomID, userID string) error {
-	var userRoom UserRoom
+// RemoveUserFromRoom removes userID from the room. When actorID is someone
+// else the removal is recorded as a kick rather than the user leaving.
+func (r *Room) RemoveUserFromRoom(db *gorm.DB, roomID, userID, actorID string) (RoomActivity, error) {
+	var (
+		userRoom UserRoom
+		actor    UserRoom
+		activity RoomActivity
+	)
 
 	exist := postgresql.CheckExists(db, &userRoom, "room_id = ? AND user_id = ?", roomID, userID)
 	if !exist {
-		return errors.New("user not in room")
+		return activity, errors.New("user not in room")
 	}
 
-	err := postgresql.DeleteRecordFromDb(db, &userRoom)
-	if err != nil {
-		return errors.New("could not remove user from room")
+	actor = userRoom
+	if actorID != userID {
+		exist = postgresql.CheckExists(db, &actor, "room_id = ? AND user_id = ?", roomID, actorID)
+		if !exist {
+			return activity, errors.New("user not in room")
+		}
 	}
-	return nil
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := postgresql.DeleteRecordFromDb(tx, &userRoom)
+		if err != nil {
+			return errors.New("could not remove user from room")
+		}
+
+		if actorID == userID {
+			activity, err = recordRoomActivity(tx, roomID, RoomEventMemberLeft, userID, userRoom.Username, "",
+				fmt.Sprintf("%v left the room", userRoom.Username), nil)
+			return err
+		}
+
+		activity, err = recordRoomActivity(tx, roomID, RoomEventMemberKicked, actorID, actor.Username, userID,
+			fmt.Sprintf("%v removed %v from the room", actor.Username, userRoom.Username),
+			map[string]interface{}{"username": userRoom.Username})
+		return err
+	})
+	return activity, err
 }
 
-func (r *UserRoom) UpdateUsername(db *gorm.DB, req UpdateRoomUserNameReq, roomId, userId string) error {
+func (r *UserRoom) UpdateUsername(db *gorm.DB, req UpdateRoomUserNameReq, roomId, userId string) (RoomActivity, error) {
 
-	var userRoom UserRoom
+	var (
+		userRoom UserRoom
+		activity RoomActivity
+	)
 
 	query := "room_id = ? AND user_id = ?"
 
 	exist := postgresql.CheckExists(db, &userRoom, query, roomId, userId)
 	if !exist {
-		return errors.New("user not in room")
+		return activity, errors.New("user not in room")
 	}
 
-	result, err := postgresql.UpdateFields(db, &r, req, query, roomId, userId)
-	if err != nil {
-		return err
-	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result, err := postgresql.UpdateFields(tx, &r, req, query, roomId, userId)
+		if err != nil {
+			return err
+		}
 
-	if result.RowsAffected == 0 {
-		return errors.New("failed to update username")
-	}
+		if result.RowsAffected == 0 {
+			return errors.New("failed to update username")
+		}
 
-	return nil
+		activity, err = recordRoomActivity(tx, roomId, RoomEventUsernameChanged, userId, req.Username, "",
+			fmt.Sprintf("%v is now known as %v", userRoom.Username, req.Username),
+			map[string]interface{}{"old_username": userRoom.Username, "new_username": req.Username})
+		return err
+	})
+	return activity, err
 }
 
 func (c *Room) Delete(db *gorm.DB) error {
@@ -274,33 +353,121 @@ func (c *UserRoom) UserInRoom(db *gorm.DB, roomID, userID string) error {
 	return nil
 }
 
-func (r *Room) UpdateRoom(db *gorm.DB, req UpdateRoomRequest, roomID string, userId string) (Room, int, error) {
-	var room Room
+func (r *Room) UpdateRoom(db *gorm.DB, req UpdateRoomRequest, roomID string, userId string) (Room, []RoomActivity, int, error) {
+	var (
+		room       Room
+		owner      UserRoom
+		activities []RoomActivity
+	)
 	room.ID = roomID
 
 	exists := postgresql.CheckExists(db, &room, "id = ?", roomID)
 	if !exists {
-		return room, http.StatusNotFound, errors.New("room does not exist")
+		return room, activities, http.StatusNotFound, errors.New("room does not exist")
 	}
 
 	if room.OwnerId != userId {
-		return room, http.StatusUnauthorized, errors.New("user not authorized")
+		return room, activities, http.StatusUnauthorized, errors.New("user not authorized")
 	}
 
+	if req.Name != room.Name && room.NameTaken(db, req.Name) {
+		return room, activities, http.StatusConflict, errors.New("room name already exists")
+	}
+
+	postgresql.CheckExists(db, &owner, "room_id = ? AND user_id = ?", roomID, userId)
+
+	var (
+		oldName        = room.Name
+		oldDescription = room.Description
+	)
+
 	room.Name = req.Name
 	room.Description = req.Description
 
-	_, err := postgresql.SaveAllFields(db, room)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := postgresql.SaveAllFields(tx, room)
+		if err != nil {
+			return err
+		}
+
+		if room.Name != oldName {
+			activity, err := recordRoomActivity(tx, roomID, RoomEventRoomRenamed, userId, owner.Username, "",
+				fmt.Sprintf("%v renamed the room to %v", owner.Username, room.Name),
+				map[string]interface{}{"old_name": oldName, "new_name": room.Name})
+			if err != nil {
+				return err
+			}
+			activities = append(activities, activity)
+		}
+
+		if room.Description != oldDescription {
+			activity, err := recordRoomActivity(tx, roomID, RoomEventDescriptionChanged, userId, owner.Username, "",
+				fmt.Sprintf("%v changed the room description", owner.Username),
+				map[string]interface{}{"old_description": oldDescription, "new_description": room.Description})
+			if err != nil {
+				return err
+			}
+			activities = append(activities, activity)
+		}
+		return nil
+	})
 	if err != nil {
-		return room, http.StatusInternalServerError, nil
+		return room, nil, http.StatusInternalServerError, err
 	}
 
 	updatedRoom := Room{}
 	err = db.First(&updatedRoom, "id = ?", roomID).Error
 	if err != nil {
-		return room, http.StatusInternalServerError, err
+		return room, activities, http.StatusInternalServerError, err
+	}
+	return updatedRoom, activities, http.StatusOK, nil
+}
+
+// TransferOwnership hands the room to another member. Only the current owner
+// can do this.
+func (r *Room) TransferOwnership(db *gorm.DB, roomID, userID, newOwnerID string) (RoomActivity, int, error) {
+	var (
+		room     Room
+		owner    UserRoom
+		target   UserRoom
+		activity RoomActivity
+	)
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomID)
+	if !exists {
+		return activity, http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	if room.OwnerId != userID {
+		return activity, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	if newOwnerID == userID {
+		return activity, http.StatusBadRequest, errors.New("user already owns the room")
+	}
+
+	exists = postgresql.CheckExists(db, &target, "room_id = ? AND user_id = ?", roomID, newOwnerID)
+	if !exists {
+		return activity, http.StatusBadRequest, errors.New("new owner must be a member of the room")
+	}
+
+	postgresql.CheckExists(db, &owner, "room_id = ? AND user_id = ?", roomID, userID)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := postgresql.UpdateFields(tx, &Room{}, map[string]interface{}{"owner_id": newOwnerID}, "id = ?", roomID)
+		if err != nil {
+			return err
+		}
+
+		activity, err = recordRoomActivity(tx, roomID, RoomEventOwnershipTransferred, userID, owner.Username, newOwnerID,
+			fmt.Sprintf("%v made %v the room owner", owner.Username, target.Username),
+			map[string]interface{}{"username": target.Username})
+		return err
+	})
+	if err != nil {
+		return activity, http.StatusInternalServerError, err
 	}
-	return updatedRoom, http.StatusOK, nil
+	return activity, http.StatusOK, nil
 }
 
 func (r *UserRoom) CheckUser(db *gorm.DB, userID, roomID string) (bool, string) {
@@ -317,13 +484,13 @@ func (r *UserRoom) CheckUser(db *gorm.DB, userID, roomID string) (bool, string)
 	return true, "user in room"
 }
 
-func (r *Room) SearchRoomsByName(db *gorm.DB, c *gin.Context, name string) ([]Room, postgresql.PaginationResponse, error) {
+func (r *Room) SearchRoomsByName(db *gorm.DB, c *gin.Context, workspaceID, name string) ([]Room, postgresql.PaginationResponse, error) {
 	var rooms []Room
 
 	pagination := postgresql.GetPagination(c)
 
 	paginationResponse, err := postgresql.SelectAllFromDbOrderByPaginated(
-		db.Preload("Users"),
+		InWorkspace(db, workspaceID).Preload("Users"),
 		"created_at",
 		"desc",
 		pagination,
@@ -344,3 +511,52 @@ func (r *Room) SearchRoomsByName(db *gorm.DB, c *gin.Context, name string) ([]Ro
 
 	return rooms, paginationResponse, nil
 }
+
+// ListAllRooms pages through rooms across every workspace, for platform
+// admins. search matches the room name when set.
+func (r *Room) ListAllRooms(db *gorm.DB, c *gin.Context, search string) ([]Room, postgresql.PaginationResponse, error) {
+	var (
+		rooms      []Room
+		ur         UserRoom
+		pagination = postgresql.GetPagination(c)
+		query      = "1 = 1"
+		args       []interface{}
+	)
+
+	if search != "" {
+		query = "name ILIKE ?"
+		args = append(args, "%"+search+"%")
+	}
+
+	paginationResponse, err := postgresql.SelectAllFromDbOrderByPaginated(db, "created_at", "desc", pagination, &rooms, query, args...)
+	if err != nil {
+		return nil, paginationResponse, err
+	}
+
+	for i, room := range rooms {
+		count, _ := ur.CountRoomUsers(db, room.ID)
+		rooms[i].UserCount = count
+	}
+	return rooms, paginationResponse, nil
+}
+
+// RoomMembership is one of a user's rooms as listed in their data export.
+type RoomMembership struct {
+	RoomID   string    `json:"room_id"`
+	RoomName string    `json:"room_name"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+func GetUserRoomMemberships(db *gorm.DB, userID string) ([]RoomMembership, error) {
+	var memberships []RoomMembership
+
+	err := db.Table("user_rooms").
+		Select("user_rooms.room_id, rooms.name AS room_name, user_rooms.username, user_rooms.role, user_rooms.created_at AS joined_at").
+		Joins("JOIN rooms ON rooms.id = user_rooms.room_id").
+		Where("user_rooms.user_id = ?", userID).
+		Order("user_rooms.created_at asc").
+		Scan(&memberships).Error
+	return memberships, err
+}
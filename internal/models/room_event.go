@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// Message types. Ordinary messages are MessageTypeMessage; every room event
+// type doubles as the type of the system message it leaves in the timeline.
+const (
+	MessageTypeMessage = "message"
+
+	RoomEventMemberJoined         = "member_joined"
+	RoomEventMemberLeft           = "member_left"
+	RoomEventMemberKicked         = "member_kicked"
+	RoomEventUsernameChanged      = "username_changed"
+	RoomEventRoomRenamed          = "room_renamed"
+	RoomEventDescriptionChanged   = "description_changed"
+	RoomEventOwnershipTransferred = "ownership_transferred"
+)
+
+// RoomEvent is the audit record behind a system message. Unlike the message it
+// is not subject to retention and keeps the structured details of the change.
+type RoomEvent struct {
+	ID        string    `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	RoomID    string    `gorm:"type:uuid;not null;index" json:"room_id"`
+	Type      string    `gorm:"column:type; type:varchar(30); not null; index" json:"type"`
+	ActorID   string    `gorm:"type:uuid;not null" json:"actor_id"`
+	TargetID  *string   `gorm:"type:uuid" json:"target_id,omitempty"`
+	MessageID int       `gorm:"column:message_id" json:"message_id"`
+	Metadata  string    `gorm:"column:metadata; type:jsonb; not null; default:'{}'" json:"metadata"`
+	CreatedAt time.Time `gorm:"column:created_at; not null; autoCreateTime; index" json:"created_at"`
+}
+
+// RoomActivity pairs the system message and audit event written for a change.
+type RoomActivity struct {
+	Message Message
+	Event   RoomEvent
+}
+
+type TransferOwnershipRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+// recordRoomActivity writes the system message and room event for a change.
+// It should run in the same transaction as the change itself.
+func recordRoomActivity(tx *gorm.DB, roomID, eventType, actorID, actorName, targetID, content string, metadata map[string]interface{}) (RoomActivity, error) {
+	var activity RoomActivity
+
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return activity, err
+	}
+
+	activity.Message = Message{
+		Content:  content,
+		RoomID:   roomID,
+		UserID:   actorID,
+		Username: actorName,
+		Type:     eventType,
+	}
+
+	err = postgresql.CreateOneRecord(tx, &activity.Message)
+	if err != nil {
+		return activity, err
+	}
+
+	activity.Event = RoomEvent{
+		ID:        utility.GenerateUUID(),
+		RoomID:    roomID,
+		Type:      eventType,
+		ActorID:   actorID,
+		MessageID: activity.Message.ID,
+		Metadata:  string(data),
+	}
+	if targetID != "" {
+		activity.Event.TargetID = &targetID
+	}
+
+	err = postgresql.CreateOneRecord(tx, &activity.Event)
+	if err != nil {
+		return activity, err
+	}
+
+	return activity, nil
+}
+
+func (e *RoomEvent) GetRoomEvents(db *gorm.DB, c *gin.Context, roomID, eventType string) ([]RoomEvent, postgresql.PaginationResponse, error) {
+	var (
+		events     []RoomEvent
+		pagination = postgresql.GetPagination(c)
+		query      = "room_id = ?"
+		args       = []interface{}{roomID}
+	)
+
+	if eventType != "" {
+		query += " AND type = ?"
+		args = append(args, eventType)
+	}
+
+	paginationResponse, err := postgresql.SelectAllFromDbOrderByPaginated(db, "created_at", "desc", pagination, &events, query, args...)
+	if err != nil {
+		return events, paginationResponse, err
+	}
+	return events, paginationResponse, nil
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+const (
+	ExportPending    = "pending"
+	ExportProcessing = "processing"
+	ExportCompleted  = "completed"
+	ExportFailed     = "failed"
+	// ExportExpired exports had their file deleted once the link expired.
+	ExportExpired = "expired"
+
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+	ExportFormatHTML = "html"
+	ExportFormatMbox = "mbox"
+)
+
+// exportClaimTimeout is how long a worker has to finish an export it claimed.
+// After that the claim is treated as abandoned and another worker builds it.
+const exportClaimTimeout = time.Hour
+
+type RoomExport struct {
+	ID                string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	RoomID            string     `gorm:"type:uuid;not null;index" json:"room_id"`
+	RequestedBy       string     `gorm:"type:uuid;not null" json:"requested_by"`
+	Format            string     `gorm:"column:format; type:varchar(10); not null" json:"format"`
+	Status            string     `gorm:"column:status; type:varchar(20); not null; default:pending; index" json:"status"`
+	FilePath          string     `gorm:"column:file_path; type:text" json:"-"`
+	DownloadTokenHash string     `gorm:"column:download_token_hash; type:varchar(64); index" json:"-"`
+	Error             string     `gorm:"column:error; type:text" json:"error,omitempty"`
+	ExpiresAt         *time.Time `gorm:"column:expires_at" json:"expires_at,omitempty"`
+	CompletedAt       *time.Time `gorm:"column:completed_at" json:"completed_at,omitempty"`
+	ClaimedAt         *time.Time `gorm:"column:claimed_at" json:"-"`
+	CreatedAt         time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+}
+
+type CreateRoomExportRequest struct {
+	Format string `json:"format" validate:"required,oneof=json csv html mbox"`
+}
+
+func (e *RoomExport) CreateRoomExport(db *gorm.DB) error {
+	e.Status = ExportPending
+
+	err := postgresql.CreateOneRecord(db, e)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *RoomExport) GetRoomExportByID(db *gorm.DB, id, roomID, userID string) (RoomExport, int, error) {
+	var export RoomExport
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &export, "id = ? AND room_id = ?", id, roomID)
+	if nilErr != nil {
+		return export, http.StatusNotFound, errors.New("export not found")
+	}
+	if err != nil {
+		return export, http.StatusInternalServerError, err
+	}
+
+	if export.RequestedBy != userID {
+		return export, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	return export, http.StatusOK, nil
+}
+
+func (e *RoomExport) GetRoomExportByToken(db *gorm.DB, id, tokenHash string) (RoomExport, error) {
+	var export RoomExport
+
+	err := db.Where("id = ? AND download_token_hash = ? AND status = ? AND expires_at > ?", id, tokenHash, ExportCompleted, time.Now()).First(&export).Error
+	if err != nil {
+		return export, err
+	}
+	return export, nil
+}
+
+// GetPendingRoomExports returns exports waiting to be built, including those
+// whose claim has timed out.
+func (e *RoomExport) GetPendingRoomExports(db *gorm.DB, limit int) ([]RoomExport, error) {
+	var exports []RoomExport
+
+	err := db.Order("created_at asc").
+		Where("status = ? OR (status = ? AND claimed_at <= ?)", ExportPending, ExportProcessing, time.Now().Add(-exportClaimTimeout)).
+		Limit(limit).Find(&exports).Error
+	if err != nil {
+		return exports, err
+	}
+	return exports, nil
+}
+
+// Claim moves a pending export, or one whose claim has timed out, to
+// processing so only one worker builds it.
+func (e *RoomExport) Claim(db *gorm.DB) (bool, error) {
+	now := time.Now()
+
+	result := db.Model(&RoomExport{}).
+		Where("id = ? AND (status = ? OR (status = ? AND claimed_at <= ?))",
+			e.ID, ExportPending, ExportProcessing, now.Add(-exportClaimTimeout)).
+		Updates(map[string]interface{}{"status": ExportProcessing, "claimed_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (e *RoomExport) Update(db *gorm.DB) error {
+	_, err := postgresql.SaveAllFields(db, e)
+	return err
+}
+
+// GetExpiredRoomExports returns completed exports whose link has expired.
+func (e *RoomExport) GetExpiredRoomExports(db *gorm.DB, now time.Time, limit int) ([]RoomExport, error) {
+	var exports []RoomExport
+
+	err := db.Where("status = ? AND expires_at <= ?", ExportCompleted, now).Limit(limit).Find(&exports).Error
+	return exports, err
+}
+
+// MarkExpired records that the export's file has been deleted.
+func (e *RoomExport) MarkExpired(db *gorm.DB) error {
+	return db.Model(&RoomExport{}).
+		Where("id = ?", e.ID).
+		Updates(map[string]interface{}{"status": ExportExpired, "file_path": "", "download_token_hash": ""}).Error
+}
+
+// EachRoomMessage streams the room's messages in id order, batchSize rows at a
+// time, so large rooms never have to be held in memory.
+func EachRoomMessage(db *gorm.DB, roomID string, batchSize int, fn func(Message) error) error {
+	var (
+		batch  []Message
+		lastID int
+	)
+
+	for {
+		batch = batch[:0]
+		err := db.Where("room_id = ? AND id > ?", roomID, lastID).Order("id asc").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			return err
+		}
+
+		for _, message := range batch {
+			if err := fn(message); err != nil {
+				return err
+			}
+			lastID = message.ID
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+const (
+	ScheduledMessagePending    = "pending"
+	ScheduledMessageProcessing = "processing"
+	ScheduledMessageSent       = "sent"
+	ScheduledMessageCancelled  = "cancelled"
+)
+
+// scheduledMessageClaimTimeout is how long a worker has to post a message it
+// claimed. After that the claim is treated as abandoned and the message is
+// picked up again.
+const scheduledMessageClaimTimeout = 10 * time.Minute
+
+type ScheduledMessage struct {
+	ID        string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	Content   string     `gorm:"column:content; type:text; not null" json:"content"`
+	RoomID    string     `gorm:"type:uuid;not null;index" json:"room_id"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	SendAt    time.Time  `gorm:"column:send_at; not null; index" json:"send_at"`
+	Status    string     `gorm:"column:status; type:varchar(20); not null; default:pending; index" json:"status"`
+	Reason    string     `gorm:"column:reason; type:text" json:"reason,omitempty"`
+	ClaimedAt *time.Time `gorm:"column:claimed_at" json:"-"`
+	CreatedAt time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+}
+
+type CreateScheduledMessageRequest struct {
+	Content string    `json:"content" validate:"required"`
+	SendAt  time.Time `json:"send_at" validate:"required"`
+}
+
+type UpdateScheduledMessageRequest struct {
+	Content string     `json:"content"`
+	SendAt  *time.Time `json:"send_at"`
+}
+
+func (s *ScheduledMessage) CreateScheduledMessage(db *gorm.DB) error {
+	var userRoom UserRoom
+
+	exist := postgresql.CheckExists(db, &userRoom, "room_id = ? AND user_id = ?", s.RoomID, s.UserID)
+	if !exist {
+		return errors.New("user not in room")
+	}
+
+	s.Status = ScheduledMessagePending
+
+	err := postgresql.CreateOneRecord(db, s)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ScheduledMessage) GetScheduledMessagesByRoomID(db *gorm.DB, userID, roomID string) ([]ScheduledMessage, error) {
+	var messages []ScheduledMessage
+
+	err := postgresql.SelectAllFromDbOrderBy(db, "send_at", "asc", &messages, "room_id = ? AND user_id = ? AND status = ?", roomID, userID, ScheduledMessagePending)
+	if err != nil {
+		return messages, err
+	}
+	return messages, nil
+}
+
+func (s *ScheduledMessage) GetScheduledMessageByID(db *gorm.DB, id, roomID, userID string) (ScheduledMessage, int, error) {
+	var message ScheduledMessage
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &message, "id = ? AND room_id = ?", id, roomID)
+	if nilErr != nil {
+		return message, http.StatusNotFound, errors.New("scheduled message not found")
+	}
+	if err != nil {
+		return message, http.StatusInternalServerError, err
+	}
+
+	if message.UserID != userID {
+		return message, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	if message.Status != ScheduledMessagePending {
+		return message, http.StatusBadRequest, errors.New("scheduled message is no longer pending")
+	}
+
+	return message, http.StatusOK, nil
+}
+
+// UpdatePending saves the content and send time while the message is still
+// pending. It returns false when a worker claimed the message since it was
+// read.
+func (s *ScheduledMessage) UpdatePending(db *gorm.DB) (bool, error) {
+	result := db.Model(&ScheduledMessage{}).
+		Where("id = ? AND status = ?", s.ID, ScheduledMessagePending).
+		Updates(map[string]interface{}{"content": s.Content, "send_at": s.SendAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetDueScheduledMessages returns pending messages whose send_at has passed,
+// and messages whose claim has timed out, oldest first.
+func (s *ScheduledMessage) GetDueScheduledMessages(db *gorm.DB, now time.Time, limit int) ([]ScheduledMessage, error) {
+	var messages []ScheduledMessage
+
+	err := db.Order("send_at asc").
+		Where("(status = ? AND send_at <= ?) OR (status = ? AND claimed_at <= ?)",
+			ScheduledMessagePending, now, ScheduledMessageProcessing, now.Add(-scheduledMessageClaimTimeout)).
+		Limit(limit).Find(&messages).Error
+	if err != nil {
+		return messages, err
+	}
+	return messages, nil
+}
+
+// Claim moves a pending message, or one whose claim has timed out, to
+// processing. It returns false when another worker got to it first. A worker
+// that dies after posting but before marking the message sent leaves it to
+// be posted again once the claim times out.
+func (s *ScheduledMessage) Claim(db *gorm.DB) (bool, error) {
+	now := time.Now()
+
+	result := db.Model(&ScheduledMessage{}).
+		Where("id = ? AND (status = ? OR (status = ? AND claimed_at <= ?))",
+			s.ID, ScheduledMessagePending, ScheduledMessageProcessing, now.Add(-scheduledMessageClaimTimeout)).
+		Updates(map[string]interface{}{"status": ScheduledMessageProcessing, "claimed_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Reschedule puts a claimed message back in the queue to be sent at sendAt.
+func (s *ScheduledMessage) Reschedule(db *gorm.DB, sendAt time.Time) error {
+	return db.Model(&ScheduledMessage{}).
+		Where("id = ?", s.ID).
+		Updates(map[string]interface{}{"status": ScheduledMessagePending, "send_at": sendAt, "claimed_at": nil}).Error
+}
+
+func (s *ScheduledMessage) SetStatus(db *gorm.DB, status, reason string) error {
+	return db.Model(&ScheduledMessage{}).
+		Where("id = ?", s.ID).
+		Updates(map[string]interface{}{"status": status, "reason": reason}).Error
+}
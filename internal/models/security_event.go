@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+const (
+	SecurityEventLogin                    = "login"
+	SecurityEventLoginFailed              = "login_failed"
+	SecurityEventPasswordChanged          = "password_changed"
+	SecurityEventPasswordReset            = "password_reset"
+	SecurityEventTwoFactorEnabled         = "two_factor_enabled"
+	SecurityEventTwoFactorDisabled        = "two_factor_disabled"
+	SecurityEventTwoFactorFailed          = "two_factor_failed"
+	SecurityEventRecoveryCodesRegenerated = "recovery_codes_regenerated"
+	SecurityEventSignInReported           = "sign_in_reported"
+)
+
+// SecurityEvent is one entry in a user's login and account security history.
+// Location is filled in after the fact, since resolving it calls out to
+// ipstack.
+type SecurityEvent struct {
+	ID               string    `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	UserID           string    `gorm:"type:uuid;not null;index:idx_security_event_user" json:"user_id"`
+	Type             string    `gorm:"column:type; type:varchar(40); not null" json:"type"`
+	IPAddress        string    `gorm:"column:ip_address; type:varchar(64)" json:"ip_address"`
+	UserAgent        string    `gorm:"column:user_agent; type:text" json:"user_agent"`
+	Location         string    `gorm:"column:location; type:varchar(255)" json:"location"`
+	Country          string    `gorm:"column:country; type:varchar(2)" json:"country"`
+	LocationResolved bool      `gorm:"column:location_resolved; type:bool; not null; default:false; index" json:"-"`
+	DeviceHash       string    `gorm:"column:device_hash; type:varchar(64)" json:"-"`
+	SessionID        string    `gorm:"column:session_id; type:varchar(36)" json:"session_id,omitempty"`
+	CreatedAt        time.Time `gorm:"column:created_at; not null; autoCreateTime; index:idx_security_event_user" json:"created_at"`
+}
+
+func (e *SecurityEvent) CreateSecurityEvent(db *gorm.DB) error {
+	return postgresql.CreateOneRecord(db, e)
+}
+
+func GetUserSecurityEvents(db *gorm.DB, c *gin.Context, userID string) ([]SecurityEvent, postgresql.PaginationResponse, error) {
+	var events []SecurityEvent
+
+	pagination := postgresql.GetPagination(c)
+	paginationResponse, err := postgresql.SelectAllFromDbOrderByPaginated(db, "created_at", "desc", pagination, &events, "user_id = ?", userID)
+	return events, paginationResponse, err
+}
+
+// GetUnresolvedSecurityEvents returns the oldest events still waiting for a
+// location.
+func GetUnresolvedSecurityEvents(db *gorm.DB, limit int) ([]SecurityEvent, error) {
+	var events []SecurityEvent
+
+	err := db.Where("location_resolved = ?", false).Order("created_at asc").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (e *SecurityEvent) SetLocation(db *gorm.DB, location, country string) error {
+	e.Location = location
+	e.Country = country
+	e.LocationResolved = true
+	_, err := postgresql.UpdateFields(db, &SecurityEvent{}, map[string]interface{}{"location": location, "country": country, "location_resolved": true}, "id = ?", e.ID)
+	return err
+}
+
+// loginSeenBefore reports whether one of the user's logins before e matches
+// query.
+func (e *SecurityEvent) loginSeenBefore(db *gorm.DB, query string, args ...interface{}) bool {
+	args = append([]interface{}{e.UserID, SecurityEventLogin, e.CreatedAt, e.ID}, args...)
+	return postgresql.CheckExists(db, &SecurityEvent{}, "user_id = ? AND type = ? AND created_at <= ? AND id <> ? AND "+query, args...)
+}
+
+// IsUnfamiliarLogin reports whether a login came from a device or a country
+// the user had not logged in from before. A first login is never unfamiliar,
+// and countries are only compared once an earlier login has one.
+func (e *SecurityEvent) IsUnfamiliarLogin(db *gorm.DB) bool {
+	if !e.loginSeenBefore(db, "1 = 1") {
+		return false
+	}
+
+	if e.DeviceHash != "" && !e.loginSeenBefore(db, "device_hash = ?", e.DeviceHash) {
+		return true
+	}
+
+	if e.Country != "" && e.loginSeenBefore(db, "country <> ''") && !e.loginSeenBefore(db, "country = ?", e.Country) {
+		return true
+	}
+	return false
+}
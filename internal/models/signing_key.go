@@ -0,0 +1,127 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// SigningKey is a key pair tokens are signed with, identified in token
+// headers by its ID as the kid. The newest key whose ActiveFrom has passed
+// signs; every key that has not expired is published and verifies, so
+// tokens outlive the rotation that retired their key.
+type SigningKey struct {
+	ID         string     `gorm:"type:uuid;primaryKey;unique;not null" json:"kid"`
+	Algorithm  string     `gorm:"column:algorithm; type:varchar(10); not null" json:"algorithm"`
+	PrivateKey string     `gorm:"column:private_key; type:text; not null" json:"-"`
+	PublicKey  string     `gorm:"column:public_key; type:text; not null" json:"public_key"`
+	ActiveFrom time.Time  `gorm:"column:active_from; not null; index" json:"active_from"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at; index" json:"expires_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+}
+
+// signingKeyLock is the Postgres advisory lock held while keys are created or
+// retired, so instances racing to rotate do not each add a key.
+const signingKeyLock = 4_713_020_531
+
+// LockSigningKeys takes the signing key lock, holding it until tx ends.
+func LockSigningKeys(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLock).Error
+}
+
+// NewSigningKey generates a key for algorithm that starts signing at
+// activeFrom, with the private half encrypted under secret.
+func NewSigningKey(algorithm string, activeFrom time.Time, secret string) (SigningKey, error) {
+	privateKey, publicKey, err := utility.GenerateSigningKey(algorithm)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	privateKey, err = utility.SealPrivateSigningKey(privateKey, secret)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	return SigningKey{
+		ID:         utility.GenerateUUID(),
+		Algorithm:  algorithm,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+		ActiveFrom: activeFrom,
+	}, nil
+}
+
+func (k *SigningKey) CreateSigningKey(db *gorm.DB) error {
+	return postgresql.CreateOneRecord(db, k)
+}
+
+// GetCurrentSigningKey returns the key tokens should be signed with at now.
+func GetCurrentSigningKey(db *gorm.DB, now time.Time) (SigningKey, error) {
+	var key SigningKey
+
+	err := db.Where("active_from <= ? AND (expires_at IS NULL OR expires_at > ?)", now, now).
+		Order("active_from desc").First(&key).Error
+	return key, err
+}
+
+// HasPendingSigningKey reports whether a key is published but not yet
+// signing.
+func HasPendingSigningKey(db *gorm.DB, now time.Time) bool {
+	return postgresql.CheckExists(db, &SigningKey{}, "active_from > ?", now)
+}
+
+// GetSigningKeyByID returns a key tokens may still be verified with.
+func GetSigningKeyByID(db *gorm.DB, id string, now time.Time) (SigningKey, error) {
+	var key SigningKey
+
+	err, _ := postgresql.SelectOneFromDb(db, &key, "id = ? AND (expires_at IS NULL OR expires_at > ?)", id, now)
+	return key, err
+}
+
+// GetPublishedSigningKeys returns every key that verifies at now, including
+// ones that have yet to start signing.
+func GetPublishedSigningKeys(db *gorm.DB, now time.Time) ([]SigningKey, error) {
+	var keys []SigningKey
+	err := postgresql.SelectAllFromDbOrderBy(db, "active_from", "desc", &keys, "expires_at IS NULL OR expires_at > ?", now)
+	return keys, err
+}
+
+// ExpireSigningKeys sets when the keys next replaces stop verifying. Only
+// keys that became active before next are touched, and keys that already
+// have an expiry are left alone.
+func ExpireSigningKeys(db *gorm.DB, next SigningKey, expiresAt time.Time) error {
+	_, err := postgresql.UpdateFields(db, &SigningKey{}, map[string]interface{}{"expires_at": expiresAt},
+		"id <> ? AND active_from < ? AND expires_at IS NULL", next.ID, next.ActiveFrom)
+	return err
+}
+
+// SealPlaintextSigningKeys encrypts private keys that were stored before
+// keys were encrypted.
+func SealPlaintextSigningKeys(db *gorm.DB, secret string) error {
+	var keys []SigningKey
+
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "asc", &keys, "private_key NOT LIKE ?", utility.SealedSigningKeyPrefix+"%")
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		sealed, err := utility.SealPrivateSigningKey(key.PrivateKey, secret)
+		if err != nil {
+			return err
+		}
+		_, err = postgresql.UpdateFields(db, &SigningKey{}, map[string]interface{}{"private_key": sealed}, "id = ?", key.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredSigningKeys removes keys that expired before before.
+func DeleteExpiredSigningKeys(db *gorm.DB, before time.Time) error {
+	return db.Where("expires_at < ?", before).Delete(&SigningKey{}).Error
+}
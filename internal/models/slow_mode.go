@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+const (
+	RoomRoleMember    = "member"
+	RoomRoleModerator = "moderator"
+)
+
+type UpdateSlowModeRequest struct {
+	Interval *int `json:"interval" validate:"omitempty,min=0,max=86400"`
+	Burst    *int `json:"burst" validate:"omitempty,min=1,max=1000"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=member moderator"`
+}
+
+// IsRoomModerator reports whether userID may moderate roomID; the room owner
+// always can.
+func (r *Room) IsRoomModerator(db *gorm.DB, roomID, userID string) (bool, error) {
+	var room Room
+
+	err := db.Select("owner_id").First(&room, "id = ?", roomID).Error
+	if err != nil {
+		return false, err
+	}
+	if room.OwnerId == userID {
+		return true, nil
+	}
+
+	return postgresql.CheckExists(db, &UserRoom{}, "room_id = ? AND user_id = ? AND role = ?", roomID, userID, RoomRoleModerator), nil
+}
+
+func (r *Room) UpdateSlowMode(db *gorm.DB, req UpdateSlowModeRequest, roomID, userID string) (Room, int, error) {
+	var room Room
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomID)
+	if !exists {
+		return room, http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	isModerator, err := room.IsRoomModerator(db, roomID, userID)
+	if err != nil {
+		return room, http.StatusInternalServerError, err
+	}
+	if !isModerator {
+		return room, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	updates := map[string]interface{}{}
+	if req.Interval != nil {
+		updates["slow_mode_interval"] = *req.Interval
+	}
+	if req.Burst != nil {
+		updates["slow_mode_burst"] = *req.Burst
+	}
+
+	if len(updates) > 0 {
+		_, err := postgresql.UpdateFields(db, &Room{}, updates, "id = ?", roomID)
+		if err != nil {
+			return room, http.StatusInternalServerError, err
+		}
+	}
+
+	updatedRoom := Room{}
+	err = db.First(&updatedRoom, "id = ?", roomID).Error
+	if err != nil {
+		return room, http.StatusInternalServerError, err
+	}
+	return updatedRoom, http.StatusOK, nil
+}
+
+func (u *UserRoom) UpdateRole(db *gorm.DB, req UpdateMemberRoleRequest, roomID, memberID, userID string) (UserRoom, int, error) {
+	var (
+		room     Room
+		userRoom UserRoom
+	)
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomID)
+	if !exists {
+		return userRoom, http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	if room.OwnerId != userID {
+		return userRoom, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	exists = postgresql.CheckExists(db, &userRoom, "room_id = ? AND user_id = ?", roomID, memberID)
+	if !exists {
+		return userRoom, http.StatusNotFound, errors.New("user not in room")
+	}
+
+	_, err := postgresql.UpdateFields(db, &UserRoom{}, map[string]interface{}{"role": req.Role}, "room_id = ? AND user_id = ?", roomID, memberID)
+	if err != nil {
+		return userRoom, http.StatusInternalServerError, err
+	}
+
+	userRoom.Role = req.Role
+	return userRoom, http.StatusOK, nil
+}
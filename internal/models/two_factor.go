@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// TwoFactor holds a user's TOTP secret. The row is created unconfirmed at
+// enrollment and only takes effect once Enabled is set by a valid code.
+type TwoFactor struct {
+	ID           string     `gorm:"column:id; type:uuid; not null; primaryKey; unique;" json:"id"`
+	UserID       string     `gorm:"column:user_id; type:uuid; not null; uniqueIndex" json:"user_id"`
+	Secret       string     `gorm:"column:secret; type:varchar(64); not null" json:"-"`
+	Enabled      bool       `gorm:"column:enabled; type:bool; default:false; not null" json:"enabled"`
+	LastUsedStep int64      `gorm:"column:last_used_step; default:0; not null" json:"-"`
+	EnabledAt    *time.Time `gorm:"column:enabled_at" json:"enabled_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at; autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at; autoUpdateTime" json:"updated_at"`
+}
+
+// RecoveryCode is a single use code that stands in for a TOTP code when the
+// user has lost their authenticator. Only its hash is stored.
+type RecoveryCode struct {
+	ID        string     `gorm:"column:id; type:uuid; not null; primaryKey; unique;" json:"id"`
+	UserID    string     `gorm:"column:user_id; type:uuid; not null; index" json:"user_id"`
+	CodeHash  string     `gorm:"column:code_hash; type:varchar(64); not null; index" json:"-"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at; autoCreateTime" json:"created_at"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// NormalizeRecoveryCode strips the formatting users are likely to type so
+// "ABCD-1234" and "abcd 1234" match the same stored hash.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func IsTwoFactorEnabled(db *gorm.DB, userID string) bool {
+	return postgresql.CheckExists(db, &TwoFactor{}, "user_id = ? AND enabled = ?", userID, true)
+}
+
+func (t *TwoFactor) GetByUserID(db *gorm.DB, userID string) (int, error) {
+	err, nilErr := postgresql.SelectOneFromDb(db, &t, "user_id = ?", userID)
+	if nilErr != nil {
+		return http.StatusNotFound, errors.New("two-factor authentication is not set up")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// SaveSecret starts a new enrollment, replacing any earlier unconfirmed one.
+func (t *TwoFactor) SaveSecret(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND enabled = ?", t.UserID, false).Delete(&TwoFactor{}).Error
+		if err != nil {
+			return err
+		}
+		return postgresql.CreateOneRecord(tx, t)
+	})
+}
+
+// Enable confirms the enrollment at the given TOTP step and stores a fresh
+// set of recovery codes.
+func (t *TwoFactor) Enable(db *gorm.DB, step int64, codes []string) error {
+	now := time.Now()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"enabled": true, "enabled_at": now, "last_used_step": step}
+		_, err := postgresql.UpdateFields(tx, &TwoFactor{}, updates, "id = ?", t.ID)
+		if err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, t.UserID, codes)
+	})
+}
+
+// MarkStepUsed records step as spent. It reports false when that step, or a
+// later one, was already used, so a code cannot be replayed within its window.
+func (t *TwoFactor) MarkStepUsed(db *gorm.DB, step int64) (bool, error) {
+	result := db.Model(&TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", t.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (t *TwoFactor) ReplaceRecoveryCodes(db *gorm.DB, codes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, t.UserID, codes)
+	})
+}
+
+// Disable removes the secret and every recovery code for the user.
+func (t *TwoFactor) Disable(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", t.UserID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", t.UserID).Delete(&TwoFactor{}).Error
+	})
+}
+
+// UseRecoveryCode spends code if it is one of userID's unused recovery codes.
+func UseRecoveryCode(db *gorm.DB, userID, code string) (bool, error) {
+	result := db.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utility.HashToken(NormalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	records := make([]RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, RecoveryCode{
+			ID:       utility.GenerateUUID(),
+			UserID:   userID,
+			CodeHash: utility.HashToken(NormalizeRecoveryCode(code)),
+		})
+	}
+	return tx.Create(&records).Error
+}
@@ -3,22 +3,37 @@ package models
 import (
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
 )
 
+// Platform roles. They are separate from room and workspace roles and only
+// decide access to the admin API.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID         string         `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
-	Name       string         `gorm:"column:name; type:varchar(255)" json:"name"`
-	Email      string         `gorm:"column:email; type:varchar(255)" json:"email"`
-	IsVerified bool           `gorm:"column:is_verified; type:bool" json:"is_verified"`
-	Profile    Profile        `gorm:"foreignKey:Userid;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"profile"`
-	Rooms      []Room         `gorm:"many2many:user_rooms;" json:"rooms"`
-	Password   string         `gorm:"column:password; type:text; not null" json:"-"`
-	CreatedAt  time.Time      `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
-	UpdatedAt  time.Time      `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	Name            string     `gorm:"column:name; type:varchar(255)" json:"name"`
+	Email           string     `gorm:"column:email; type:varchar(255)" json:"email"`
+	IsVerified      bool       `gorm:"column:is_verified; type:bool" json:"is_verified"`
+	Role            string     `gorm:"column:role; type:varchar(20); default:user; not null" json:"role"`
+	SuspendedAt     *time.Time `gorm:"column:suspended_at" json:"suspended_at"`
+	SuspendedReason string     `gorm:"column:suspended_reason; type:text" json:"suspended_reason,omitempty"`
+	DeleteAfter     *time.Time `gorm:"column:delete_after; index" json:"delete_after,omitempty"`
+	// MustResetPassword is set when the user reports a login that wasn't
+	// them; their password stops working until it is reset by email.
+	MustResetPassword bool           `gorm:"column:must_reset_password; type:bool; not null; default:false" json:"must_reset_password"`
+	Profile           Profile        `gorm:"foreignKey:Userid;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"profile"`
+	Rooms             []Room         `gorm:"many2many:user_rooms;" json:"rooms"`
+	Password          string         `gorm:"column:password; type:text; not null" json:"-"`
+	CreatedAt         time.Time      `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type CreateUserRequestModel struct {
@@ -30,11 +45,32 @@ type CreateUserRequestModel struct {
 	PhoneNumber string `json:"phone_number"`
 }
 
+// UpdateUserRequestModel is a partial update; fields left out are kept.
 type UpdateUserRequestModel struct {
-	FirstName   string `json:"first_name" validate:"required"`
-	LastName    string `json:"last_name" validate:"required"`
-	UserName    string `json:"username" validate:"required"`
-	PhoneNumber string `json:"phone_number"`
+	FirstName   *string `json:"first_name" validate:"omitempty,min=1,max=100"`
+	LastName    *string `json:"last_name" validate:"omitempty,min=1,max=100"`
+	UserName    *string `json:"username" validate:"omitempty,min=3,max=50"`
+	PhoneNumber *string `json:"phone_number" validate:"omitempty,max=30"`
+}
+
+type AvatarCropRequest struct {
+	X    int `form:"x" validate:"min=0"`
+	Y    int `form:"y" validate:"min=0"`
+	Size int `form:"size" validate:"min=0"`
+}
+
+// PublicProfile is what anyone may see of an account.
+type PublicProfile struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	AvatarURL string    `json:"avatar_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
 }
 
 type LoginRequestModel struct {
@@ -81,6 +117,14 @@ func (u *User) Update(db *gorm.DB) error {
 	return err
 }
 
+// RequirePasswordReset stops the password from being used to log in until it
+// is reset.
+func (u *User) RequirePasswordReset(db *gorm.DB) error {
+	u.MustResetPassword = true
+	_, err := postgresql.UpdateFields(db, &User{}, map[string]interface{}{"must_reset_password": true}, "id = ?", u.ID)
+	return err
+}
+
 func (u *User) DeleteAUser(db *gorm.DB) error {
 
 	err := postgresql.DeleteRecordFromDb(db, u)
@@ -117,3 +161,61 @@ func (u *User) GetUserWithProfile(db *gorm.DB, userID string) (User, error) {
 
 	return user, nil
 }
+
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+func IsPlatformAdmin(db *gorm.DB, userID string) bool {
+	return postgresql.CheckExists(db, &User{}, "id = ? AND role = ?", userID, RoleAdmin)
+}
+
+func IsUserSuspended(db *gorm.DB, userID string) bool {
+	return postgresql.CheckExists(db, &User{}, "id = ? AND suspended_at IS NOT NULL", userID)
+}
+
+// SearchUsers pages through every user, matching search against the name
+// and email when it is set.
+func (u *User) SearchUsers(db *gorm.DB, c *gin.Context, search string) ([]User, postgresql.PaginationResponse, error) {
+	var (
+		users      []User
+		pagination = postgresql.GetPagination(c)
+		query      = "1 = 1"
+		args       []interface{}
+	)
+
+	if search != "" {
+		query = "name ILIKE ? OR email ILIKE ?"
+		args = append(args, "%"+search+"%", "%"+search+"%")
+	}
+
+	paginationResponse, err := postgresql.SelectAllFromDbOrderByPaginated(db.Preload("Profile"), "created_at", "desc", pagination, &users, query, args...)
+	return users, paginationResponse, err
+}
+
+// SetSuspended suspends the user with reason, or lifts the suspension when
+// suspend is false.
+func (u *User) SetSuspended(db *gorm.DB, suspend bool, reason string) error {
+	updates := map[string]interface{}{"suspended_at": nil, "suspended_reason": ""}
+	if suspend {
+		updates = map[string]interface{}{"suspended_at": time.Now(), "suspended_reason": reason}
+	}
+
+	_, err := postgresql.UpdateFields(db, &User{}, updates, "id = ?", u.ID)
+	return err
+}
+
+func (u *User) PublicProfile() PublicProfile {
+	return PublicProfile{
+		ID:        u.ID,
+		Username:  u.Name,
+		FirstName: u.Profile.FirstName,
+		LastName:  u.Profile.LastName,
+		AvatarURL: u.Profile.AvatarURL,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+func UsernameTaken(db *gorm.DB, username, exceptUserID string) bool {
+	return postgresql.CheckExists(db, &User{}, "name = ? AND id <> ?", username, exceptUserID)
+}
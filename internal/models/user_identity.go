@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// UserIdentity links an account to a login at an outside provider. The
+// provider's subject, not the email address, is what identifies the login.
+type UserIdentity struct {
+	ID         string     `gorm:"column:id; type:uuid; not null; primaryKey; unique;" json:"id"`
+	UserID     string     `gorm:"column:user_id; type:uuid; not null; index" json:"user_id"`
+	Provider   string     `gorm:"column:provider; type:varchar(64); not null; uniqueIndex:idx_user_identity_subject" json:"provider"`
+	Subject    string     `gorm:"column:subject; type:varchar(255); not null; uniqueIndex:idx_user_identity_subject" json:"-"`
+	Email      string     `gorm:"column:email; type:varchar(255)" json:"email"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at; autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at; autoUpdateTime" json:"updated_at"`
+}
+
+type ReauthRequest struct {
+	Password string `json:"password"`
+}
+
+type LinkIdentityRequest struct {
+	Password string `json:"password"`
+	IDToken  string `json:"id_token"`
+}
+
+func (i *UserIdentity) GetByProviderSubject(db *gorm.DB, provider, subject string) (int, error) {
+	err, nilErr := postgresql.SelectOneFromDb(db, &i, "provider = ? AND subject = ?", provider, subject)
+	if nilErr != nil {
+		return http.StatusNotFound, errors.New("identity not found")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func (i *UserIdentity) CreateIdentity(db *gorm.DB) error {
+	return postgresql.CreateOneRecord(db, i)
+}
+
+func (i *UserIdentity) TouchLastUsed(db *gorm.DB) error {
+	now := time.Now()
+	i.LastUsedAt = &now
+	_, err := postgresql.UpdateFields(db, &UserIdentity{}, map[string]interface{}{"last_used_at": now}, "id = ?", i.ID)
+	return err
+}
+
+func GetUserIdentities(db *gorm.DB, userID string) ([]UserIdentity, error) {
+	var identities []UserIdentity
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "asc", &identities, "user_id = ?", userID)
+	return identities, err
+}
+
+func HasIdentityForProvider(db *gorm.DB, userID, provider string) bool {
+	return postgresql.CheckExists(db, &UserIdentity{}, "user_id = ? AND provider = ?", userID, provider)
+}
+
+// DeleteUserIdentity removes one of the user's identities, refusing to leave
+// an account without a password and without any linked login.
+func DeleteUserIdentity(db *gorm.DB, userID, identityID string, hasPassword bool) (int, error) {
+	var (
+		identity UserIdentity
+		count    int64
+	)
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &identity, "id = ? AND user_id = ?", identityID, userID)
+	if nilErr != nil {
+		return http.StatusNotFound, errors.New("identity not found")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = db.Model(&UserIdentity{}).Where("user_id = ?", userID).Count(&count).Error
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !hasPassword && count <= 1 {
+		return http.StatusBadRequest, errors.New("set a password before removing your last linked login")
+	}
+
+	err = postgresql.DeleteRecordFromDb(db, &identity)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,420 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+type Workspace struct {
+	ID          string            `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	Name        string            `gorm:"column:name; type:varchar(255); not null" json:"name"`
+	Description string            `gorm:"column:description; type:text" json:"description"`
+	OwnerID     string            `gorm:"type:uuid;not null;index" json:"owner_id"`
+	Domains     []WorkspaceDomain `gorm:"foreignKey:WorkspaceID" json:"domains"`
+	Role        string            `gorm:"-" json:"role,omitempty"`
+	CreatedAt   time.Time         `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time         `gorm:"column:updated_at; null; autoUpdateTime" json:"updated_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID string    `gorm:"type:uuid;primaryKey;not null" json:"workspace_id"`
+	UserID      string    `gorm:"type:uuid;primaryKey;not null;index" json:"user_id"`
+	Role        string    `gorm:"column:role; type:varchar(20); not null; default:member" json:"role"`
+	CreatedAt   time.Time `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+}
+
+// WorkspaceInvite is a pending invitation for an email address. The invite is
+// only usable by an account with that address.
+type WorkspaceInvite struct {
+	ID          string     `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	WorkspaceID string     `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Email       string     `gorm:"column:email; type:varchar(255); not null; index" json:"email"`
+	Role        string     `gorm:"column:role; type:varchar(20); not null; default:member" json:"role"`
+	Token       string     `gorm:"column:token; type:varchar(255); not null; uniqueIndex" json:"-"`
+	InvitedBy   string     `gorm:"type:uuid;not null" json:"invited_by"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at; not null" json:"expires_at"`
+	AcceptedAt  *time.Time `gorm:"column:accepted_at" json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+}
+
+// WorkspaceDomain lets users with a verified address on the domain join the
+// workspace without an invite.
+type WorkspaceDomain struct {
+	ID          string    `gorm:"type:uuid;primaryKey;unique;not null" json:"id"`
+	WorkspaceID string    `gorm:"type:uuid;not null;uniqueIndex:idx_workspace_domain" json:"workspace_id"`
+	Domain      string    `gorm:"column:domain; type:varchar(255); not null; uniqueIndex:idx_workspace_domain; index" json:"domain"`
+	CreatedBy   string    `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time `gorm:"column:created_at; not null; autoCreateTime" json:"created_at"`
+}
+
+type CreateWorkspaceRequest struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name        string `json:"name" validate:"omitempty,max=255"`
+	Description string `json:"description"`
+}
+
+type CreateWorkspaceInviteRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"omitempty,oneof=admin member"`
+}
+
+type AcceptWorkspaceInviteRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin member"`
+}
+
+type CreateWorkspaceDomainRequest struct {
+	Domain string `json:"domain" validate:"required,fqdn"`
+}
+
+// publicEmailDomains are mail providers anyone can sign up with. Owning an
+// address on one says nothing about who you work for, so they cannot be used
+// for auto-join.
+var publicEmailDomains = map[string]bool{
+	"aol.com":        true,
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"gmx.com":        true,
+	"gmx.net":        true,
+	"hotmail.com":    true,
+	"icloud.com":     true,
+	"live.com":       true,
+	"mail.com":       true,
+	"mail.ru":        true,
+	"me.com":         true,
+	"msn.com":        true,
+	"outlook.com":    true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"qq.com":         true,
+	"yahoo.com":      true,
+	"yandex.com":     true,
+	"zoho.com":       true,
+}
+
+// IsPublicEmailDomain reports whether domain belongs to a shared mail
+// provider.
+func IsPublicEmailDomain(domain string) bool {
+	return publicEmailDomains[strings.ToLower(strings.TrimSpace(domain))]
+}
+
+// EmailDomain returns the lower-cased domain part of an email address.
+func EmailDomain(email string) string {
+	_, domain, found := strings.Cut(email, "@")
+	if !found {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(domain))
+}
+
+// CreateWorkspace stores the workspace and makes its creator the owner.
+func (w *Workspace) CreateWorkspace(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := postgresql.CreateOneRecord(tx, w)
+		if err != nil {
+			return err
+		}
+
+		member := WorkspaceMember{WorkspaceID: w.ID, UserID: w.OwnerID, Role: WorkspaceRoleOwner}
+		return postgresql.CreateOneRecord(tx, &member)
+	})
+}
+
+// Delete removes the workspace together with its rooms, members, invites
+// and domains.
+func (w *Workspace) Delete(db *gorm.DB) error {
+	var rooms []Room
+
+	if err := db.Where("workspace_id = ?", w.ID).Find(&rooms).Error; err != nil {
+		return err
+	}
+	for _, room := range rooms {
+		if err := room.Delete(db); err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{&WorkspaceMember{}, &WorkspaceInvite{}, &WorkspaceDomain{}} {
+		if err := db.Where("workspace_id = ?", w.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return db.Delete(&Workspace{}, "id = ?", w.ID).Error
+}
+
+func (w *Workspace) GetWorkspaceByID(db *gorm.DB, id string) (Workspace, int, error) {
+	var workspace Workspace
+
+	err, nilErr := postgresql.SelectOneFromDb(db.Preload("Domains"), &workspace, "id = ?", id)
+	if nilErr != nil {
+		return workspace, http.StatusNotFound, errors.New("workspace not found")
+	}
+	if err != nil {
+		return workspace, http.StatusInternalServerError, err
+	}
+	return workspace, http.StatusOK, nil
+}
+
+// GetUserWorkspaces lists every workspace userID belongs to, with the user's
+// role in each.
+func (w *Workspace) GetUserWorkspaces(db *gorm.DB, userID string) ([]Workspace, error) {
+	var (
+		workspaces []Workspace
+		members    []WorkspaceMember
+	)
+
+	err := db.Where("user_id = ?", userID).Find(&members).Error
+	if err != nil {
+		return workspaces, err
+	}
+	if len(members) == 0 {
+		return workspaces, nil
+	}
+
+	roles := make(map[string]string, len(members))
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		roles[m.WorkspaceID] = m.Role
+		ids = append(ids, m.WorkspaceID)
+	}
+
+	err = db.Preload("Domains").Where("id IN ?", ids).Order("name asc").Find(&workspaces).Error
+	if err != nil {
+		return workspaces, err
+	}
+
+	for i := range workspaces {
+		workspaces[i].Role = roles[workspaces[i].ID]
+	}
+	return workspaces, nil
+}
+
+func (w *Workspace) UpdateWorkspace(db *gorm.DB, req UpdateWorkspaceRequest) error {
+	updates := map[string]interface{}{}
+	if req.Name != "" {
+		updates["name"] = req.Name
+	}
+	if req.Description != "" {
+		updates["description"] = req.Description
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	_, err := postgresql.UpdateFields(db, &Workspace{}, updates, "id = ?", w.ID)
+	return err
+}
+
+// GetMemberRole returns userID's role in the workspace, or an empty string
+// when they are not a member.
+func (m *WorkspaceMember) GetMemberRole(db *gorm.DB, workspaceID, userID string) (string, error) {
+	var member WorkspaceMember
+
+	err := db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+func (m *WorkspaceMember) GetWorkspaceMembers(db *gorm.DB, workspaceID string) ([]WorkspaceMember, error) {
+	var members []WorkspaceMember
+
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "asc", &members, "workspace_id = ?", workspaceID)
+	if err != nil {
+		return members, err
+	}
+	return members, nil
+}
+
+func (m *WorkspaceMember) UpdateRole(db *gorm.DB, workspaceID, userID, role string) (int, error) {
+	result, err := postgresql.UpdateFields(db, &WorkspaceMember{}, map[string]interface{}{"role": role}, "workspace_id = ? AND user_id = ?", workspaceID, userID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if result.RowsAffected == 0 {
+		return http.StatusNotFound, errors.New("user not in workspace")
+	}
+	return http.StatusOK, nil
+}
+
+// RemoveMember takes userID out of the workspace and every room in it.
+func (m *WorkspaceMember) RemoveMember(db *gorm.DB, workspaceID, userID string) (int, error) {
+	var member WorkspaceMember
+
+	exists := postgresql.CheckExists(db, &member, "workspace_id = ? AND user_id = ?", workspaceID, userID)
+	if !exists {
+		return http.StatusNotFound, errors.New("user not in workspace")
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND room_id IN (?)", userID, tx.Model(&Room{}).Select("id").Where("workspace_id = ?", workspaceID)).
+			Delete(&UserRoom{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&WorkspaceMember{}).Error
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// AutoJoinWorkspaces adds a verified user to every workspace that allows
+// their email domain. Existing memberships are left as they are.
+func AutoJoinWorkspaces(db *gorm.DB, user User) error {
+	var domains []WorkspaceDomain
+
+	domain := EmailDomain(user.Email)
+	if !user.IsVerified || domain == "" || IsPublicEmailDomain(domain) {
+		return nil
+	}
+
+	err := db.Where("domain = ?", domain).Find(&domains).Error
+	if err != nil {
+		return err
+	}
+
+	for _, d := range domains {
+		member := WorkspaceMember{WorkspaceID: d.WorkspaceID, UserID: user.ID, Role: WorkspaceRoleMember}
+		err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateInvite stores the invite, replacing any pending invite for the same
+// address so only the latest link works.
+func (i *WorkspaceInvite) CreateInvite(db *gorm.DB) error {
+	i.Email = strings.ToLower(i.Email)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("workspace_id = ? AND email = ? AND accepted_at IS NULL", i.WorkspaceID, i.Email).Delete(&WorkspaceInvite{}).Error
+		if err != nil {
+			return err
+		}
+		return postgresql.CreateOneRecord(tx, i)
+	})
+}
+
+func (i *WorkspaceInvite) GetPendingInvites(db *gorm.DB, workspaceID string) ([]WorkspaceInvite, error) {
+	var invites []WorkspaceInvite
+
+	err := postgresql.SelectAllFromDbOrderBy(db, "created_at", "desc", &invites, "workspace_id = ? AND accepted_at IS NULL AND expires_at > ?", workspaceID, time.Now())
+	if err != nil {
+		return invites, err
+	}
+	return invites, nil
+}
+
+func (i *WorkspaceInvite) DeleteInvite(db *gorm.DB, id, workspaceID string) (int, error) {
+	var invite WorkspaceInvite
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &invite, "id = ? AND workspace_id = ? AND accepted_at IS NULL", id, workspaceID)
+	if nilErr != nil {
+		return http.StatusNotFound, errors.New("invite not found")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = postgresql.DeleteRecordFromDb(db, &invite)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// Accept redeems the invite for user, whose email must match the invited
+// address.
+func (i *WorkspaceInvite) Accept(db *gorm.DB, workspaceID, token string, user User) (WorkspaceMember, int, error) {
+	var (
+		invite WorkspaceInvite
+		member WorkspaceMember
+	)
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &invite, "workspace_id = ? AND token = ? AND accepted_at IS NULL", workspaceID, token)
+	if nilErr != nil {
+		return member, http.StatusNotFound, errors.New("invalid or expired invite")
+	}
+	if err != nil {
+		return member, http.StatusInternalServerError, err
+	}
+
+	if time.Now().After(invite.ExpiresAt) {
+		return member, http.StatusNotFound, errors.New("invalid or expired invite")
+	}
+
+	if !strings.EqualFold(invite.Email, user.Email) {
+		return member, http.StatusForbidden, errors.New("invite was sent to a different email address")
+	}
+
+	member = WorkspaceMember{WorkspaceID: workspaceID, UserID: user.ID, Role: invite.Role}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&WorkspaceInvite{}).Where("id = ?", invite.ID).Update("accepted_at", time.Now()).Error
+	})
+	if err != nil {
+		return member, http.StatusInternalServerError, err
+	}
+	return member, http.StatusOK, nil
+}
+
+func (d *WorkspaceDomain) CreateDomain(db *gorm.DB) error {
+	d.Domain = strings.ToLower(d.Domain)
+
+	exists := postgresql.CheckExists(db, &WorkspaceDomain{}, "workspace_id = ? AND domain = ?", d.WorkspaceID, d.Domain)
+	if exists {
+		return errors.New("domain already added")
+	}
+
+	return postgresql.CreateOneRecord(db, d)
+}
+
+func (d *WorkspaceDomain) DeleteDomain(db *gorm.DB, id, workspaceID string) (int, error) {
+	var domain WorkspaceDomain
+
+	err, nilErr := postgresql.SelectOneFromDb(db, &domain, "id = ? AND workspace_id = ?", id, workspaceID)
+	if nilErr != nil {
+		return http.StatusNotFound, errors.New("domain not found")
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = postgresql.DeleteRecordFromDb(db, &domain)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
@@ -30,6 +30,15 @@ func main() {
 	db := storage.Connection()
 
 	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "send-notifications")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "send-scheduled-messages")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "purge-expired-messages")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "process-room-exports")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "purge-expired-exports")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "process-imports")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "process-account-exports")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "purge-deleted-accounts")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "resolve-event-locations")
+	cronjobs.StartCronJob(request.ExternalRequest{Logger: logger}, *storage.DB, "rotate-signing-keys")
 
 	if configuration.Database.Migrate {
 		migrations.RunAllMigrations(db)
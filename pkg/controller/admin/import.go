@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/services/importer"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) CreateImport(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	// leave room for the other form fields around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, importer.MaxUploadBytes+1<<20)
+
+	var tooLarge *http.MaxBytesError
+	if err := c.Request.ParseMultipartForm(1 << 20); errors.As(err, &tooLarge) {
+		rd := utility.BuildErrorResponse(http.StatusRequestEntityTooLarge, "error", "export file is too large", err, nil)
+		c.JSON(http.StatusRequestEntityTooLarge, rd)
+		return
+	}
+
+	provider := c.PostForm("provider")
+	if provider == "" {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "provider is required", errors.New("provider is required"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	upload, err := c.FormFile("file")
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "export file is required", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	result, code, err := importer.CreateImportJob(base.Db.Postgresql, provider, upload, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("import queued successfully")
+	rd := utility.BuildSuccessResponse(code, "import queued successfully", result)
+	c.JSON(code, rd)
+}
+
+func (base *Controller) GetImport(c *gin.Context) {
+	importId := c.Param("importId")
+
+	if _, err := uuid.Parse(importId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid import id format", errors.New("failed to parse import id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	result, code, err := importer.GetImportJob(base.Db.Postgresql, importId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("import retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "import retrieved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
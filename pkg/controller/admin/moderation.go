@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/moderation"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) GetModerationQueue(c *gin.Context) {
+	result, code, err := moderation.GetModerationQueue(base.Db.Postgresql, "", c.Query("status"))
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("moderation queue retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "moderation queue retrieved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) ResolveReport(c *gin.Context) {
+	var req models.ResolveReportRequest
+
+	reportId := c.Param("reportId")
+
+	if _, err := uuid.Parse(reportId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid report id format", errors.New("failed to parse report id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	adminId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	result, code, err := moderation.ResolveReport(base.Db.Postgresql, req, "", reportId, adminId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("report resolved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "report resolved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/services/admin"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) ListRooms(c *gin.Context) {
+	rooms, paginationResponse, code, err := admin.ListRooms(base.Db.Postgresql, c)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	paginationData := map[string]interface{}{
+		"current_page": paginationResponse.CurrentPage,
+		"total_pages":  paginationResponse.TotalPagesCount,
+		"page_size":    paginationResponse.PageCount,
+		"total_items":  len(rooms),
+	}
+
+	base.Logger.Info("rooms retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "rooms retrieved successfully", rooms, paginationData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) DeleteRoom(c *gin.Context) {
+	roomId := c.Param("roomId")
+
+	if _, err := uuid.Parse(roomId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid room id format", errors.New("failed to parse room id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	code, err := admin.DeleteRoom(base.Db.Postgresql, roomId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("room deleted successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "room deleted successfully", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) GetNotificationQueue(c *gin.Context) {
+	respData, code, err := admin.GetNotificationQueue(base.Db.Redis)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("notification queue retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "notification queue retrieved successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
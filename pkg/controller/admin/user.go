@@ -0,0 +1,163 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/admin"
+	"github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// userIdParam reads and checks :userId, answering the request itself when
+// it is malformed.
+func userIdParam(c *gin.Context) (string, bool) {
+	userId := c.Param("userId")
+
+	if _, err := uuid.Parse(userId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid user id format", errors.New("failed to parse user id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return "", false
+	}
+	return userId, true
+}
+
+func (base *Controller) ListUsers(c *gin.Context) {
+	users, paginationResponse, code, err := admin.ListUsers(base.Db.Postgresql, c)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	paginationData := map[string]interface{}{
+		"current_page": paginationResponse.CurrentPage,
+		"total_pages":  paginationResponse.TotalPagesCount,
+		"page_size":    paginationResponse.PageCount,
+		"total_items":  len(users),
+	}
+
+	base.Logger.Info("users retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "users retrieved successfully", users, paginationData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) SuspendUser(c *gin.Context) {
+	var req models.SuspendUserRequest
+
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	adminId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	err := c.ShouldBind(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	err = base.Validator.Struct(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed",
+			utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	code, err := admin.SuspendUser(base.Db.Postgresql, req, userId, adminId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user suspended")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user suspended", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) UnsuspendUser(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	code, err := admin.UnsuspendUser(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user unsuspended")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user unsuspended", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) ForceLogout(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	revoked, code, err := admin.ForceLogout(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user signed out of all sessions")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user signed out of all sessions", gin.H{"revoked": revoked})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) VerifyUserEmail(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	code, err := admin.VerifyUserEmail(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user email verified")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user email verified", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) UnlockUser(c *gin.Context) {
+	userId, ok := userIdParam(c)
+	if !ok {
+		return
+	}
+
+	code, err := auth.UnlockAccount(base.Db.Postgresql, base.Db.Redis, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user account unlocked")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user account unlocked", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
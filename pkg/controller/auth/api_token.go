@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) CreateAPIToken(c *gin.Context) {
+	var req models.CreateAPITokenRequest
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	err := c.ShouldBind(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	err = base.Validator.Struct(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed",
+			utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	respData, code, err := service.CreateAPIToken(base.Db.Postgresql, req, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("api token created successfully")
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "copy the token now, it will not be shown again", respData)
+	c.JSON(http.StatusCreated, rd)
+}
+
+func (base *Controller) GetAPITokens(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	respData, code, err := service.GetAPITokens(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("api tokens retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "api tokens retrieved successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) RevokeAPIToken(c *gin.Context) {
+	tokenId := c.Param("tokenId")
+	if _, err := uuid.Parse(tokenId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid token id format", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	code, err := service.RevokeAPIToken(base.Db.Postgresql, userId, tokenId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("api token revoked successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "api token revoked successfully", nil)
+	c.JSON(http.StatusOK, rd)
+}
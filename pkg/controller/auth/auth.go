@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -82,7 +84,7 @@ func (base *Controller) CreateAdmin(c *gin.Context) {
 		return
 	}
 
-	respData, code, err := auth.CreateAdmin(reqData, base.Db.Postgresql)
+	respData, code, err := auth.CreateAdmin(reqData, base.Db.Postgresql, clientInfo(c))
 	if err != nil {
 		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", err.Error(), err, nil)
 		c.JSON(http.StatusBadRequest, rd)
@@ -111,10 +113,18 @@ func (base *Controller) LoginUser(c *gin.Context) {
 		return
 	}
 
-	respData, code, err := auth.LoginUser(req, base.Db.Postgresql)
+	respData, code, err := auth.LoginUser(req, base.Db.Postgresql, clientInfo(c), base.Logger)
 	if err != nil {
+		setRetryAfter(c, err)
 		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
-		c.JSON(http.StatusBadRequest, rd)
+		c.JSON(code, rd)
+		return
+	}
+
+	if respData["two_factor_required"] == true {
+		base.Logger.Info("two-factor challenge issued")
+		rd := utility.BuildSuccessResponse(http.StatusOK, "two-factor authentication required", respData)
+		c.JSON(http.StatusOK, rd)
 		return
 	}
 
@@ -154,3 +164,11 @@ func (base *Controller) LogoutUser(c *gin.Context) {
 	rd := utility.BuildSuccessResponse(http.StatusOK, "user logout successfully", respData)
 	c.JSON(http.StatusOK, rd)
 }
+
+// setRetryAfter tells throttled clients when they may try again.
+func setRetryAfter(c *gin.Context, err error) {
+	var lockoutErr *auth.LockoutError
+	if errors.As(err, &lockoutErr) {
+		c.Header("Retry-After", strconv.Itoa(lockoutErr.RetryAfterSeconds()))
+	}
+}
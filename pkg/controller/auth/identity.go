@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) GetIdentities(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	respData, code, err := service.GetIdentities(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("identities retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "identities retrieved successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) LinkIdentity(c *gin.Context) {
+	var (
+		req      = models.LinkIdentityRequest{}
+		provider = c.Param("provider")
+	)
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+	accessUuid, _ := userClaims["access_uuid"].(string)
+
+	err := c.ShouldBind(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	respData, code, err := service.LinkIdentity(base.Db.Postgresql, req, provider, userId, accessUuid, c.ClientIP())
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	if _, redirect := respData["authorize_url"]; redirect {
+		base.Logger.Info("identity link started")
+		rd := utility.BuildSuccessResponse(http.StatusOK, "continue at the provider to link your account", respData)
+		c.JSON(http.StatusOK, rd)
+		return
+	}
+
+	base.Logger.Info("identity linked successfully")
+	rd := utility.BuildSuccessResponse(code, "identity linked successfully", respData)
+	c.JSON(code, rd)
+}
+
+func (base *Controller) UnlinkIdentity(c *gin.Context) {
+	var (
+		req        = models.ReauthRequest{}
+		identityId = c.Param("identityId")
+	)
+
+	if _, err := uuid.Parse(identityId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid identity id format", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+	accessUuid, _ := userClaims["access_uuid"].(string)
+
+	err := c.ShouldBind(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	code, err := service.UnlinkIdentity(base.Db.Postgresql, req, identityId, userId, accessUuid, c.ClientIP())
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("identity unlinked successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "identity unlinked successfully", nil)
+	c.JSON(http.StatusOK, rd)
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// GetJWKS serves the key set as a bare JWKS document, the shape other
+// services and Centrifugo expect, rather than in the usual envelope.
+func (base *Controller) GetJWKS(c *gin.Context) {
+	jwks, code, err := service.GetJWKS(base.Db.Postgresql)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(service.JWKSMaxAge.Seconds())))
+	c.JSON(http.StatusOK, jwks)
+}
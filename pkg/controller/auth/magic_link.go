@@ -66,8 +66,9 @@ func (base *Controller) VerifyMagicLink(c *gin.Context) {
 		return
 	}
 
-	respData, code, err := service.VerifyMagicLinkToken(req, base.Db.Postgresql)
+	respData, code, err := service.VerifyMagicLinkToken(req, base.Db.Postgresql, clientInfo(c), base.Logger)
 	if err != nil {
+		setRetryAfter(c, err)
 		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
 		c.JSON(code, rd)
 		return
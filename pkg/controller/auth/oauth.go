@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// oauthProvider checks :provider is registered and passes it on to gothic,
+// which reads it from the query string.
+func oauthProvider(c *gin.Context) bool {
+	provider := c.Param("provider")
+
+	if _, err := goth.GetProvider(provider); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusNotFound, "error", "unsupported login provider", err, nil)
+		c.JSON(http.StatusNotFound, rd)
+		return false
+	}
+
+	query := c.Request.URL.Query()
+	query.Set("provider", provider)
+	c.Request.URL.RawQuery = query.Encode()
+	return true
+}
+
+// oauthLinkSession is the cookie that ties a link token to the browser that
+// started the link, so a link URL handed to someone else cannot attach their
+// provider login to the sender's account.
+const oauthLinkSession = "_oauth_link"
+
+// takeOAuthLinkToken returns the link token this browser started with and
+// clears it.
+func takeOAuthLinkToken(c *gin.Context) string {
+	session, err := gothic.Store.Get(c.Request, oauthLinkSession)
+	if err != nil || session.IsNew {
+		return ""
+	}
+
+	token, _ := session.Values["link_token"].(string)
+	session.Options.MaxAge = -1
+	_ = session.Save(c.Request, c.Writer)
+	return token
+}
+
+func (base *Controller) BeginOAuth(c *gin.Context) {
+	if !oauthProvider(c) {
+		return
+	}
+
+	// a link started from settings rides along as the OAuth state, so the
+	// callback can tell it apart from a login
+	if linkToken := c.Query("link_token"); linkToken != "" {
+		session, _ := gothic.Store.New(c.Request, oauthLinkSession)
+		session.Values["link_token"] = linkToken
+		if err := session.Save(c.Request, c.Writer); err != nil {
+			rd := utility.BuildErrorResponse(http.StatusInternalServerError, "error", "unable to start linking", err.Error(), nil)
+			c.JSON(http.StatusInternalServerError, rd)
+			return
+		}
+
+		query := c.Request.URL.Query()
+		query.Set("state", linkToken)
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	gothic.BeginAuthHandler(c.Writer, c.Request)
+}
+
+func (base *Controller) OAuthCallback(c *gin.Context) {
+	if !oauthProvider(c) {
+		return
+	}
+
+	boundToken := takeOAuthLinkToken(c)
+
+	gothUser, err := gothic.CompleteUserAuth(c.Writer, c.Request)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnauthorized, "error", "unable to complete login with provider", err.Error(), nil)
+		c.JSON(http.StatusUnauthorized, rd)
+		return
+	}
+
+	state := c.Query("state")
+	if userId, linking := service.GetOAuthLinkUser(state); linking {
+		if subtle.ConstantTimeCompare([]byte(boundToken), []byte(state)) != 1 {
+			err := errors.New("link was started in a different browser")
+			rd := utility.BuildErrorResponse(http.StatusUnauthorized, "error", err.Error(), err, nil)
+			c.JSON(http.StatusUnauthorized, rd)
+			return
+		}
+
+		identity, code, err := service.LinkOAuthIdentity(gothUser, base.Db.Postgresql, userId, state)
+		if err != nil {
+			rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+			c.JSON(code, rd)
+			return
+		}
+
+		base.Logger.Info("identity linked successfully")
+		rd := utility.BuildSuccessResponse(http.StatusOK, "identity linked successfully", identity)
+		c.JSON(http.StatusOK, rd)
+		return
+	}
+
+	respData, code, err := service.OAuthLogin(gothUser, base.Db.Postgresql, clientInfo(c), base.Logger)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	if respData["two_factor_required"] == true {
+		base.Logger.Info("two-factor challenge issued")
+		rd := utility.BuildSuccessResponse(http.StatusOK, "two-factor authentication required", respData)
+		c.JSON(http.StatusOK, rd)
+		return
+	}
+
+	base.Logger.Info("user login successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user login successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
@@ -98,8 +98,9 @@ func (base *Controller) VerifyResetToken(c *gin.Context) {
 		return
 	}
 
-	respData, code, err := service.VerifyPasswordResetToken(req, base.Db.Postgresql)
+	respData, code, err := service.VerifyPasswordResetToken(req, base.Db.Postgresql, clientInfo(c))
 	if err != nil {
+		setRetryAfter(c, err)
 		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
 		c.JSON(code, rd)
 		return
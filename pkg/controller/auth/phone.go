@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// bindPhoneRequest reads and validates a phone request body, writing the
+// error response itself when it cannot.
+func (base *Controller) bindPhoneRequest(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBind(req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return false
+	}
+
+	err = base.Validator.Struct(req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed",
+			utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return false
+	}
+	return true
+}
+
+func (base *Controller) RequestPhoneVerification(c *gin.Context) {
+	var req models.PhoneCodeRequest
+
+	if !base.bindPhoneRequest(c, &req) {
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	code, err := service.RequestPhoneVerification(base.Db.Postgresql, userId, req, clientInfo(c))
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("phone verification code sent")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "a verification code has been sent to your phone", nil)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) ConfirmPhoneVerification(c *gin.Context) {
+	var req models.PhoneCodeVerifyRequest
+
+	if !base.bindPhoneRequest(c, &req) {
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	respData, code, err := service.ConfirmPhoneVerification(base.Db.Postgresql, userId, req, clientInfo(c))
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("phone number verified")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "phone number verified", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) RequestPhoneLogin(c *gin.Context) {
+	var req models.PhoneCodeRequest
+
+	if !base.bindPhoneRequest(c, &req) {
+		return
+	}
+
+	code, err := service.RequestPhoneLogin(base.Db.Postgresql, req, clientInfo(c), base.Logger)
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	rd := utility.BuildSuccessResponse(http.StatusOK, "if the number is verified on an account, a sign-in code has been sent to it", nil)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) VerifyPhoneLogin(c *gin.Context) {
+	var req models.PhoneCodeVerifyRequest
+
+	if !base.bindPhoneRequest(c, &req) {
+		return
+	}
+
+	respData, code, err := service.VerifyPhoneLogin(base.Db.Postgresql, req, clientInfo(c), base.Logger)
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user login successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user login successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+func (base *Controller) GetSessions(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+	accessUuid, _ := userClaims["access_uuid"].(string)
+
+	respData, code, err := service.GetSessions(base.ExtReq, base.Db.Postgresql, userId, accessUuid)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("sessions retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "sessions retrieved successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) RevokeSession(c *gin.Context) {
+	sessionId := c.Param("sessionId")
+
+	if _, err := uuid.Parse(sessionId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid session id format", errors.New("failed to parse session id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	code, err := service.RevokeSession(base.Db.Postgresql, userId, sessionId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("session revoked successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "session revoked successfully", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) RevokeOtherSessions(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+	accessUuid, _ := userClaims["access_uuid"].(string)
+
+	revoked, code, err := service.RevokeOtherSessions(base.Db.Postgresql, userId, accessUuid)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("other sessions revoked successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "other sessions revoked successfully", gin.H{"revoked": revoked})
+	c.JSON(http.StatusOK, rd)
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	service "github.com/hngprojects/telex_be/services/auth"
+)
+
+// notMePage is what the "this wasn't me" link in a new sign-in email opens.
+// Mail scanners and link previews follow links with GET, so the page only
+// asks for confirmation and the form POSTs back to do the work.
+var notMePage = template.Must(template.New("not-me").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Telex - Secure your account</title></head>
+<body>
+{{if .Token}}
+<h1>Wasn't you?</h1>
+<p>We'll sign that session out and ask you to reset your password before you can sign in again.</p>
+<form method="POST" action="/api/v1/auth/not-me">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Secure my account</button>
+</form>
+{{else}}
+<p>{{.Message}}</p>
+{{end}}
+</body>
+</html>
+`))
+
+type notMeData struct {
+	Token   string
+	Message string
+}
+
+func renderNotMe(c *gin.Context, code int, data notMeData) {
+	c.Status(code)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := notMePage.Execute(c.Writer, data); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// ConfirmSignInReport is reached from the "this wasn't me" link in a new
+// sign-in email. It only shows the confirmation page; the link is used up
+// by ReportSignIn.
+func (base *Controller) ConfirmSignInReport(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		renderNotMe(c, http.StatusBadRequest, notMeData{Message: "This link is invalid or has expired."})
+		return
+	}
+
+	code, err := service.CheckSignInReport(token)
+	if err != nil {
+		renderNotMe(c, code, notMeData{Message: "This link is invalid or has expired."})
+		return
+	}
+
+	renderNotMe(c, http.StatusOK, notMeData{Token: token})
+}
+
+// ReportSignIn handles the confirmation form, so the link token is the only
+// credential.
+func (base *Controller) ReportSignIn(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		renderNotMe(c, http.StatusBadRequest, notMeData{Message: "This link is invalid or has expired."})
+		return
+	}
+
+	code, err := service.ReportSignIn(base.Db.Postgresql, base.ExtReq, token, clientInfo(c))
+	if err != nil {
+		base.Logger.Error("sign-in report failed: ", err.Error())
+		renderNotMe(c, code, notMeData{Message: "We couldn't secure your account: " + err.Error()})
+		return
+	}
+
+	base.Logger.Info("sign-in reported")
+
+	renderNotMe(c, http.StatusOK, notMeData{Message: "The session has been signed out. Check your email for a code to reset your password."})
+}
@@ -31,13 +31,20 @@ func (base *Controller) GoogleLogin(c *gin.Context) {
 		return
 	}
 
-	respData, code, err := auth.CreateGoogleUser(req, base.Db.Postgresql)
+	respData, code, err := auth.CreateGoogleUser(req, base.Db.Postgresql, clientInfo(c), base.Logger)
 	if err != nil {
 		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
 		c.JSON(code, rd)
 		return
 	}
 
+	if respData["two_factor_required"] == true {
+		base.Logger.Info("two-factor challenge issued")
+		rd := utility.BuildSuccessResponse(http.StatusOK, "two-factor authentication required", respData)
+		c.JSON(http.StatusOK, rd)
+		return
+	}
+
 	base.Logger.Info("user sign in successfully")
 
 	rd := utility.BuildSuccessResponse(http.StatusOK, "user sign in successfully", respData)
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) EnrollTwoFactor(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	respData, code, err := service.EnrollTwoFactor(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("two-factor enrollment started")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "scan the QR code and confirm with a code from your app", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) ConfirmTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+
+	userId, ok := base.bindTwoFactorCode(c, &req)
+	if !ok {
+		return
+	}
+
+	respData, code, err := service.ConfirmTwoFactor(base.Db.Postgresql, req, userId, clientInfo(c))
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("two-factor authentication enabled")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "two-factor authentication enabled", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+
+	userId, ok := base.bindTwoFactorCode(c, &req)
+	if !ok {
+		return
+	}
+
+	respData, code, err := service.RegenerateRecoveryCodes(base.Db.Postgresql, req, userId, clientInfo(c))
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("recovery codes regenerated")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "recovery codes regenerated", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) DisableTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+
+	userId, ok := base.bindTwoFactorCode(c, &req)
+	if !ok {
+		return
+	}
+
+	code, err := service.DisableTwoFactor(base.Db.Postgresql, req, userId, clientInfo(c))
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("two-factor authentication disabled")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "two-factor authentication disabled", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) VerifyTwoFactor(c *gin.Context) {
+	var req models.VerifyTwoFactorRequest
+
+	err := c.ShouldBind(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	err = base.Validator.Struct(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed",
+			utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	respData, code, err := service.VerifyTwoFactor(req, base.Db.Postgresql, clientInfo(c), base.Logger)
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user login successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user login successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+// bindTwoFactorCode reads a code request for the signed in user and writes
+// the error response itself when it cannot.
+func (base *Controller) bindTwoFactorCode(c *gin.Context, req *models.TwoFactorCodeRequest) (string, bool) {
+	err := c.ShouldBind(req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return "", false
+	}
+
+	err = base.Validator.Struct(req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed",
+			utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return "", false
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return "", false
+	}
+	return claims.(jwt.MapClaims)["user_id"].(string), true
+}
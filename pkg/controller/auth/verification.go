@@ -30,8 +30,9 @@ func (base *Controller) VerifyEmailToken(c *gin.Context) {
 		return
 	}
 
-	respData, code, err := auth.VerifyEmailToken(req, base.Db.Postgresql)
+	respData, code, err := auth.VerifyEmailToken(req, base.Db.Postgresql, clientInfo(c), base.Logger)
 	if err != nil {
+		setRetryAfter(c, err)
 		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
 		c.JSON(code, rd)
 		return
@@ -0,0 +1,118 @@
+package room
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/export"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) CreateRoomExport(c *gin.Context) {
+	var req models.CreateRoomExportRequest
+
+	roomId := c.Param("roomId")
+
+	if _, err := uuid.Parse(roomId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid room id format", errors.New("failed to parse room id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	result, code, err := export.RequestRoomExport(req, base.Db.Postgresql, roomId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("room export requested successfully")
+	rd := utility.BuildSuccessResponse(code, "room export requested successfully", result)
+	c.JSON(code, rd)
+}
+
+func (base *Controller) GetRoomExport(c *gin.Context) {
+	roomId := c.Param("roomId")
+	exportId := c.Param("exportId")
+
+	if _, err := uuid.Parse(roomId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid room id format", errors.New("failed to parse room id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if _, err := uuid.Parse(exportId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid export id format", errors.New("failed to parse export id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	result, code, err := export.GetRoomExport(base.Db.Postgresql, exportId, roomId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("room export retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "room export retrieved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+// DownloadRoomExport is reached from the emailed link, so it is authorised by
+// the export's download token rather than a bearer token.
+func (base *Controller) DownloadRoomExport(c *gin.Context) {
+	exportId := c.Param("exportId")
+	token := c.Query("token")
+
+	if _, err := uuid.Parse(exportId); err != nil || token == "" {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid download link", errors.New("invalid download link"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	filePath, fileName, code, err := export.GetExportFile(base.Db.Postgresql, exportId, token)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("room export downloaded")
+	c.FileAttachment(filePath, fileName)
+}
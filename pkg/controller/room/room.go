@@ -3,6 +3,7 @@ package room
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -53,6 +54,8 @@ func (base *Controller) CreateRoom(c *gin.Context) {
 		return
 	}
 
+	req.WorkspaceID = c.Param("workspaceId")
+
 	respData, code, err := room.CreateRoom(req, base.Db.Postgresql, userId)
 	if err != nil {
 		base.Logger.Info("error creating room")
@@ -68,7 +71,7 @@ func (base *Controller) CreateRoom(c *gin.Context) {
 
 func (base *Controller) GetRooms(c *gin.Context) {
 
-	respData, code, err := room.GetRooms(base.Db.Postgresql)
+	respData, code, err := room.GetRooms(base.Db.Postgresql, c.Param("workspaceId"))
 	if err != nil {
 		base.Logger.Info("error getting rooms")
 		rd := utility.BuildErrorResponse(code, "error",
@@ -174,10 +177,14 @@ func (base *Controller) AddRoomMsg(c *gin.Context) {
 
 	req.UserId = userClaims["user_id"].(string)
 
-	code, err := room.AddRoomMsg(req, base.Db.Postgresql)
+	code, err := room.AddRoomMsg(req, base.Db.Postgresql, base.Db.Redis, base.Logger)
 	if err != nil {
-		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", err.Error(), err, nil)
-		c.JSON(http.StatusBadRequest, rd)
+		var limitErr *room.PostingLimitError
+		if errors.As(err, &limitErr) {
+			c.Header("Retry-After", strconv.Itoa(limitErr.RetryAfterSeconds()))
+		}
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
 		return
 	}
 
@@ -223,7 +230,7 @@ func (base *Controller) JoinRoom(c *gin.Context) {
 	req.RoomID = room_id
 	req.UserID = user_id
 
-	code, err := room.JoinRoom(base.Db.Postgresql, req)
+	code, err := room.JoinRoom(base.ExtReq, base.Db.Postgresql, req)
 	if err != nil {
 		base.Logger.Info("error joining room")
 		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
@@ -257,7 +264,7 @@ func (base *Controller) LeaveRoom(c *gin.Context) {
 
 	user_id := userClaims["user_id"].(string)
 
-	code, err := room.LeaveRoom(base.Db.Postgresql, roomId, user_id)
+	code, err := room.LeaveRoom(base.ExtReq, base.Db.Postgresql, roomId, user_id)
 	if err != nil {
 		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", err.Error(), err, nil)
 		c.JSON(http.StatusBadRequest, rd)
@@ -306,7 +313,7 @@ func (base *Controller) UpdateUsername(c *gin.Context) {
 		return
 	}
 
-	code, err := room.UpdateUsername(req, base.Db.Postgresql, roomId, userId)
+	code, err := room.UpdateUsername(base.ExtReq, req, base.Db.Postgresql, roomId, userId)
 	if err != nil {
 		base.Logger.Info("error creating room")
 		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", err.Error(), err, nil)
@@ -354,7 +361,7 @@ func (base *Controller) DeleteRoom(c *gin.Context) {
 func (base *Controller) GetRoomByName(c *gin.Context) {
 	name := c.Params.ByName("roomName")
 
-	respData, code, err := room.GetRoomByName(base.Db.Postgresql, name)
+	respData, code, err := room.GetRoomByName(base.Db.Postgresql, c.Param("workspaceId"), name)
 	if err != nil {
 		base.Logger.Info("error getting room")
 		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
@@ -422,7 +429,7 @@ func (base *Controller) UpdateRoom(c *gin.Context) {
 		return
 	}
 
-	result, err := room.UpdateRoom(base.Db.Postgresql, req, id, userId)
+	result, err := room.UpdateRoom(base.ExtReq, base.Db.Postgresql, req, id, userId)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			rd := utility.BuildErrorResponse(http.StatusNotFound, "error", "Room not found", err, nil)
@@ -474,7 +481,7 @@ func (base *Controller) CheckUser(c *gin.Context) {
 func (base *Controller) SearchRoomByNames(c *gin.Context) {
 	name := c.Param("roomName")
 
-	rooms, paginationResponse, err := room.SearchRoomByNames(base.Db.Postgresql, c, name)
+	rooms, paginationResponse, err := room.SearchRoomByNames(base.Db.Postgresql, c, c.Param("workspaceId"), name)
 	if err != nil {
 		base.Logger.Info("error fetching rooms")
 		rd := utility.BuildErrorResponse(http.StatusNotFound, "error", "failed to fetch rooms", err, nil)
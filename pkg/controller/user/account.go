@@ -0,0 +1,124 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/services/export"
+	service "github.com/hngprojects/telex_be/services/user"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) RequestExport(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	respData, code, err := export.RequestAccountExport(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("account export requested successfully")
+	rd := utility.BuildSuccessResponse(code, "account export requested successfully, a download link will be emailed to you", respData)
+	c.JSON(code, rd)
+}
+
+// DownloadExport is reached from the emailed link, so it is authorised by
+// the export's download token rather than a bearer token.
+func (base *Controller) DownloadExport(c *gin.Context) {
+	exportId := c.Param("exportId")
+	token := c.Query("token")
+
+	if _, err := uuid.Parse(exportId); err != nil || token == "" {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid download link", errors.New("invalid download link"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	filePath, fileName, code, err := export.GetAccountExportFile(base.Db.Postgresql, exportId, token)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("account export downloaded")
+	c.FileAttachment(filePath, fileName)
+}
+
+func (base *Controller) DeleteMe(c *gin.Context) {
+	var req models.ReauthRequest
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+	accessUuid, _ := userClaims["access_uuid"].(string)
+
+	err := c.ShouldBind(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	respData, code, err := service.DeleteMe(base.Db.Postgresql, base.Db.Redis, req, userId, accessUuid, c.ClientIP())
+	if err != nil {
+		setRetryAfter(c, err)
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("account scheduled for deletion")
+	rd := utility.BuildSuccessResponse(code, "account scheduled for deletion", respData)
+	c.JSON(code, rd)
+}
+
+func (base *Controller) RestoreMe(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	respData, code, err := service.RestoreMe(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("account deletion cancelled")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "account deletion cancelled", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+// setRetryAfter tells clients locked out by a failed password check when
+// they may try again.
+func setRetryAfter(c *gin.Context, err error) {
+	var lockoutErr *auth.LockoutError
+	if errors.As(err, &lockoutErr) {
+		c.Header("Retry-After", strconv.Itoa(lockoutErr.RetryAfterSeconds()))
+	}
+}
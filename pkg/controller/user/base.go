@@ -0,0 +1,16 @@
+package user
+
+import (
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+type Controller struct {
+	Db        *storage.Database
+	Validator *validator.Validate
+	Logger    *utility.Logger
+	ExtReq    request.ExternalRequest
+}
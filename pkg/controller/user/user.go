@@ -0,0 +1,139 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	service "github.com/hngprojects/telex_be/services/user"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) GetMe(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	respData, code, err := service.GetMe(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("profile retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "profile retrieved successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) UpdateMe(c *gin.Context) {
+	var req models.UpdateUserRequestModel
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	err := c.ShouldBind(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	err = base.Validator.Struct(&req)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed",
+			utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	respData, code, err := service.UpdateMe(base.Db.Postgresql, req, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("profile updated successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "profile updated successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) UploadAvatar(c *gin.Context) {
+	var crop models.AvatarCropRequest
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userId := claims.(jwt.MapClaims)["user_id"].(string)
+
+	upload, err := c.FormFile("avatar")
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "avatar file is required", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	err = c.ShouldBind(&crop)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Failed to parse crop area", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	err = base.Validator.Struct(&crop)
+	if err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed",
+			utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	respData, code, err := service.UploadAvatar(base.Db.Postgresql, upload, crop, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("avatar updated successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "avatar updated successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) GetPublicProfile(c *gin.Context) {
+	userId := c.Param("userId")
+
+	if _, err := uuid.Parse(userId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid user id format", errors.New("failed to parse user id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	respData, code, err := service.GetPublicProfile(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("user profile retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "user profile retrieved successfully", respData)
+	c.JSON(http.StatusOK, rd)
+}
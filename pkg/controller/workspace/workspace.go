@@ -0,0 +1,506 @@
+package workspace
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/workspace"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func (base *Controller) CreateWorkspace(c *gin.Context) {
+	var req models.CreateWorkspaceRequest
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	result, code, err := workspace.CreateWorkspace(base.Db.Postgresql, req, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("workspace created successfully")
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "workspace created successfully", result)
+	c.JSON(http.StatusCreated, rd)
+}
+
+func (base *Controller) GetWorkspaces(c *gin.Context) {
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	result, code, err := workspace.GetWorkspaces(base.Db.Postgresql, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("workspaces retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "workspaces retrieved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) GetWorkspace(c *gin.Context) {
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	result, code, err := workspace.GetWorkspace(base.Db.Postgresql, workspaceId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("workspace retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "workspace retrieved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) UpdateWorkspace(c *gin.Context) {
+	var req models.UpdateWorkspaceRequest
+
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	result, code, err := workspace.UpdateWorkspace(base.Db.Postgresql, req, workspaceId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("workspace updated successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "workspace updated successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) GetMembers(c *gin.Context) {
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	result, code, err := workspace.GetMembers(base.Db.Postgresql, workspaceId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("workspace members retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "workspace members retrieved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) UpdateMemberRole(c *gin.Context) {
+	var req models.UpdateWorkspaceMemberRequest
+
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	memberId := c.Param("userId")
+
+	if _, err := uuid.Parse(memberId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid user id format", errors.New("failed to parse user id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	code, err := workspace.UpdateMemberRole(base.Db.Postgresql, req, workspaceId, memberId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("member role updated successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "member role updated successfully", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) RemoveMember(c *gin.Context) {
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	memberId := c.Param("userId")
+
+	if _, err := uuid.Parse(memberId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid user id format", errors.New("failed to parse user id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	code, err := workspace.RemoveMember(base.Db.Postgresql, workspaceId, memberId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("member removed successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "member removed successfully", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) CreateInvite(c *gin.Context) {
+	var req models.CreateWorkspaceInviteRequest
+
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	url := c.Request.Header.Get("Referer")
+
+	result, code, err := workspace.CreateInvite(base.Db.Postgresql, base.Db.Redis, req, workspaceId, userId, url)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("invite sent successfully")
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "invite sent successfully", result)
+	c.JSON(http.StatusCreated, rd)
+}
+
+func (base *Controller) GetInvites(c *gin.Context) {
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	result, code, err := workspace.GetInvites(base.Db.Postgresql, workspaceId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("invites retrieved successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "invites retrieved successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) DeleteInvite(c *gin.Context) {
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	inviteId := c.Param("inviteId")
+
+	if _, err := uuid.Parse(inviteId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid invite id format", errors.New("failed to parse invite id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	code, err := workspace.DeleteInvite(base.Db.Postgresql, workspaceId, inviteId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("invite revoked successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "invite revoked successfully", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) AcceptInvite(c *gin.Context) {
+	var req models.AcceptWorkspaceInviteRequest
+
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	result, code, err := workspace.AcceptInvite(base.Db.Postgresql, req, workspaceId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("invite accepted successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "invite accepted successfully", result)
+	c.JSON(http.StatusOK, rd)
+}
+
+func (base *Controller) CreateDomain(c *gin.Context) {
+	var req models.CreateWorkspaceDomainRequest
+
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "Invalid request body", err, nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	if err := base.Validator.Struct(&req); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusUnprocessableEntity, "error", "Validation failed", utility.ValidationResponse(err, base.Validator), nil)
+		c.JSON(http.StatusUnprocessableEntity, rd)
+		return
+	}
+
+	result, code, err := workspace.CreateDomain(base.Db.Postgresql, req, workspaceId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("domain added successfully")
+	rd := utility.BuildSuccessResponse(http.StatusCreated, "domain added successfully", result)
+	c.JSON(http.StatusCreated, rd)
+}
+
+func (base *Controller) DeleteDomain(c *gin.Context) {
+	workspaceId := c.Param("workspaceId")
+
+	if _, err := uuid.Parse(workspaceId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", errors.New("failed to parse workspace id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	domainId := c.Param("domainId")
+
+	if _, err := uuid.Parse(domainId); err != nil {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid domain id format", errors.New("failed to parse domain id"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+
+	claims, exists := c.Get("userClaims")
+	if !exists {
+		rd := utility.BuildErrorResponse(http.StatusBadRequest, "error", "unable to get user claims", errors.New("user not authorized"), nil)
+		c.JSON(http.StatusBadRequest, rd)
+		return
+	}
+	userClaims := claims.(jwt.MapClaims)
+	userId := userClaims["user_id"].(string)
+
+	code, err := workspace.DeleteDomain(base.Db.Postgresql, workspaceId, domainId, userId)
+	if err != nil {
+		rd := utility.BuildErrorResponse(code, "error", err.Error(), err, nil)
+		c.JSON(code, rd)
+		return
+	}
+
+	base.Logger.Info("domain removed successfully")
+	rd := utility.BuildSuccessResponse(http.StatusOK, "domain removed successfully", gin.H{})
+	c.JSON(http.StatusOK, rd)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// AdminOnly lets platform admins through. The role is read from the database
+// on every request so a demotion takes effect at once. It must run after
+// Authorize.
+func AdminOnly(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("userClaims").(jwt.MapClaims)
+		userID, _ := claims["user_id"].(string)
+
+		if !models.IsPlatformAdmin(db, userID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, utility.BuildErrorResponse(http.StatusForbidden, "error", "admin access required", "Forbidden", nil))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
@@ -33,6 +34,11 @@ func Authorize(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(tokenStr, models.APITokenPrefix) {
+			authorizeAPIToken(c, db, tokenStr)
+			return
+		}
+
 		token, err := TokenValid(tokenStr)
 		if err != nil {
 			r := utility.BuildErrorResponse(http.StatusUnauthorized, "error", "Token is invalid!", "Unauthorized", nil)
@@ -57,19 +63,37 @@ func Authorize(db *gorm.DB) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, utility.BuildErrorResponse(http.StatusUnauthorized, "error", "Token is invalid!", "Unauthorized", nil))
 			return
 		}
-		// check user session and also if token is valid in stored session
-
-		access_token = models.AccessToken{ID: accessID}
-		if code, err := access_token.GetByID(db); err != nil {
-			c.AbortWithStatusJSON(code, utility.BuildErrorResponse(http.StatusUnauthorized, "error", "Token is invalid!", "Unauthorized", nil))
-			return
+		// check user session and also if token is valid in stored session,
+		// from the cache when it holds the session
+
+		cached, generation, cacheErr := getCachedSession(accessID, userID)
+		if cached != nil && cached.OwnerID == userID && cached.TokenHash == utility.HashToken(tokenStr) {
+			access_token = models.AccessToken{ID: accessID, OwnerID: userID, IsLive: true, LoginAccessToken: tokenStr, LastUsedAt: cached.LastUsedAt, LastUsedIP: cached.LastUsedIP}
+		} else {
+			cached = nil
+
+			access_token = models.AccessToken{ID: accessID}
+			if code, err := access_token.GetByID(db); err != nil {
+				c.AbortWithStatusJSON(code, utility.BuildErrorResponse(http.StatusUnauthorized, "error", "Token is invalid!", "Unauthorized", nil))
+				return
+			}
+
+			// check if session is valid
+
+			if access_token.LoginAccessToken != tokenStr || userID != access_token.OwnerID || !access_token.IsLive {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, utility.BuildErrorResponse(http.StatusUnauthorized, "error", "Session is invalid!", "Unauthorized", nil))
+				return
+			}
 		}
 
-		// check if session is valid
+		// best effort: a failed write must not fail the request
+		lastUsedAt := access_token.LastUsedAt
+		_ = access_token.TouchLastUsed(db, c.ClientIP())
 
-		if access_token.LoginAccessToken != tokenStr || userID != access_token.OwnerID || !access_token.IsLive {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, utility.BuildErrorResponse(http.StatusUnauthorized, "error", "Session is invalid!", "Unauthorized", nil))
-			return
+		// refill on a miss, and on a hit when last-used data moved on so the
+		// next request does not write it again
+		if cacheErr == nil && (cached == nil || access_token.LastUsedAt != lastUsedAt) {
+			cacheSession(access_token, generation, tokenExpiry(claims))
 		}
 
 		c.Set("userClaims", claims)
@@ -80,6 +104,12 @@ func Authorize(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// tokenExpiry reads the exp claim TokenValid has already checked.
+func tokenExpiry(claims jwt.MapClaims) time.Time {
+	exp, _ := claims["exp"].(float64)
+	return time.Unix(int64(exp), 0)
+}
+
 func GetIdFromToken(c *gin.Context) (string, interface{}) {
 	var tokenStr string
 	bearerToken := c.GetHeader("Authorization")
@@ -15,9 +15,32 @@ import (
 )
 
 type TokenDetailDTO struct {
-	AccessUuid  string `json:"access_uuid"`
-	AccessToken string `json:"access_token"`
-	ExpiresAt   time.Time
+	AccessUuid       string `json:"access_uuid"`
+	AccessToken      string `json:"access_token"`
+	ExpiresAt        time.Time
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresAt time.Time
+}
+
+const (
+	defaultAccessTokenMinutes = 15
+	defaultRefreshTokenDays   = 30
+)
+
+// Access and Centrifugo tokens are signed with the same keys, so each names
+// its audience and only access tokens are accepted by the API.
+const (
+	AccessTokenAudience = "telex-api"
+	CentrifugoAudience  = "centrifugo"
+)
+
+// AccessTokenLifetime is how long an access token is valid for.
+func AccessTokenLifetime() time.Duration {
+	minutes := config.GetConfig().Server.AccessTokenExpireMinutes
+	if minutes <= 0 {
+		minutes = defaultAccessTokenMinutes
+	}
+	return time.Duration(minutes) * time.Minute
 }
 
 func CreateToken(user models.User) (*TokenDetailDTO, error) {
@@ -28,22 +51,33 @@ func CreateToken(user models.User) (*TokenDetailDTO, error) {
 		err       error
 	)
 
-	tokenData.ExpiresAt = time.Now().AddDate(0, 0, config.Server.AccessTokenExpireDuration) // token valid for env set days
+	// short lived access tokens are renewed with the refresh token
+	tokenData.ExpiresAt = time.Now().Add(AccessTokenLifetime())
 	tokenData.AccessUuid = user.ID
 	tokenData.AccessUuid = utility.GenerateUUID()
 
+	refreshDays := config.Server.RefreshTokenExpireDays
+	if refreshDays <= 0 {
+		refreshDays = defaultRefreshTokenDays
+	}
+	tokenData.RefreshExpiresAt = time.Now().AddDate(0, 0, refreshDays)
+
+	tokenData.RefreshToken, err = utility.GenerateSecureToken(32)
+	if err != nil {
+		return tokenData, err
+	}
+
 	//create token
 	userClaims := jwt.MapClaims{}
 
 	// specify user claims
+	userClaims["aud"] = AccessTokenAudience
 	userClaims["user_id"] = user.ID
 	userClaims["access_uuid"] = tokenData.AccessUuid
 	userClaims["exp"] = tokenData.ExpiresAt.Unix()
 	userClaims["authorised"] = true
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims)
-
-	tokenData.AccessToken, err = token.SignedString([]byte(config.Server.Secret))
+	tokenData.AccessToken, err = SignToken(userClaims)
 	if err != nil {
 		return tokenData, err
 	}
@@ -54,16 +88,25 @@ func CreateToken(user models.User) (*TokenDetailDTO, error) {
 // verify token
 
 func verifyToken(tokenString string) (*jwt.Token, error) {
-	config := config.GetConfig()
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, err := keys.verifying(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(config.Server.Secret), nil
+		return key.Public, nil
 	})
 	if err != nil {
 		return token, fmt.Errorf("Unauthorized")
 	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !claims.VerifyAudience(AccessTokenAudience, true) {
+		return nil, fmt.Errorf("Unauthorized")
+	}
 	return token, nil
 }
 
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// RateLimitPolicy is a sliding window limit declared on a route group. Hits
+// are counted under Name and the caller identity from Key, so groups sharing
+// a policy share the allowance.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int64
+	Window time.Duration
+	Key    func(c *gin.Context) string
+}
+
+var (
+	// AuthRateLimit is deliberately tight; it covers the /auth routes that
+	// take credentials from anonymous callers.
+	AuthRateLimit = RateLimitPolicy{Name: "auth", Limit: 20, Window: time.Minute, Key: ByIP}
+	// AccountRateLimit applies per user to signed in /auth routes, after
+	// Authorize.
+	AccountRateLimit = RateLimitPolicy{Name: "account", Limit: 60, Window: time.Minute, Key: ByUser}
+	// RefreshRateLimit applies per refresh token, so clients sharing an
+	// address do not share an allowance while renewing sessions.
+	RefreshRateLimit = RateLimitPolicy{Name: "refresh", Limit: 10, Window: time.Minute, Key: ByRefreshToken}
+	// MessageRateLimit applies per user, after Authorize.
+	MessageRateLimit = RateLimitPolicy{Name: "messages", Limit: 60, Window: time.Minute, Key: ByUser}
+	// AnonymousRateLimit covers routes that need no token.
+	AnonymousRateLimit = RateLimitPolicy{Name: "anonymous", Limit: 120, Window: time.Minute, Key: ByIP}
+)
+
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser keys on the signed in user and falls back to the IP when the
+// request carries no claims.
+func ByUser(c *gin.Context) string {
+	if claims, exists := c.Get("userClaims"); exists {
+		if userID, ok := claims.(jwt.MapClaims)["user_id"].(string); ok && userID != "" {
+			return "user:" + userID
+		}
+	}
+	return ByIP(c)
+}
+
+// refreshBodyLimit caps how much of a refresh request ByRefreshToken reads.
+const refreshBodyLimit = 1 << 16
+
+// ByRefreshToken keys on a hash of the refresh token in the request body and
+// falls back to the IP when there is none. The body is put back for the
+// handler to bind.
+func ByRefreshToken(c *gin.Context) string {
+	var req models.RefreshTokenRequest
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, refreshBodyLimit))
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || json.Unmarshal(body, &req) != nil || req.RefreshToken == "" {
+		return ByIP(c)
+	}
+	return "refresh:" + utility.HashToken(req.RefreshToken)
+}
+
+// RateLimit enforces policy with counters in Redis, so the limit holds across
+// instances. Responses carry RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset; IPs in ExemptFromThrottle skip the check, and if Redis is
+// unreachable requests are let through.
+func RateLimit(redisClient *redis.Client, policy RateLimitPolicy) gin.HandlerFunc {
+	exempt := config.GetConfig().Server.ExemptFromThrottle
+
+	return func(c *gin.Context) {
+		if redisClient == nil || isExemptIP(c.ClientIP(), exempt) {
+			c.Next()
+			return
+		}
+
+		key := fmt.Sprintf("ratelimit:%v:%v", policy.Name, policy.Key(c))
+
+		allowed, count, reset, err := rdb.SlidingWindow(redisClient, key, utility.GenerateUUID(), policy.Limit, policy.Window)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		remaining := policy.Limit - count
+		if !allowed || remaining < 0 {
+			remaining = 0
+		}
+		resetSeconds := strconv.Itoa(int(math.Ceil(reset.Seconds())))
+
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds())))
+		c.Header("RateLimit-Limit", strconv.FormatInt(policy.Limit, 10))
+		c.Header("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		c.Header("RateLimit-Reset", resetSeconds)
+
+		if !allowed {
+			c.Header("Retry-After", resetSeconds)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, utility.BuildErrorResponse(http.StatusTooManyRequests, "error", "too many requests, please slow down", "rate limit exceeded", nil))
+			return
+		}
+
+		c.Next()
+	}
+}
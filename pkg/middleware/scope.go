@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// routeScopes holds the scope each route declared through ScopedGroup, keyed
+// by method and full path. Routes missing from it only accept sessions.
+var routeScopes = struct {
+	sync.RWMutex
+	byRoute map[string]string
+}{byRoute: map[string]string{}}
+
+// ScopedGroup registers routes that API tokens may call when they carry the
+// route's scope. Session JWTs carry every scope. Routes registered on the
+// underlying group directly stay closed to API tokens.
+type ScopedGroup struct {
+	group *gin.RouterGroup
+}
+
+func Scoped(group *gin.RouterGroup) ScopedGroup {
+	return ScopedGroup{group: group}
+}
+
+func (s ScopedGroup) Handle(method, relativePath, scope string, handlers ...gin.HandlerFunc) {
+	s.group.Handle(method, relativePath, handlers...)
+
+	routeScopes.Lock()
+	routeScopes.byRoute[routeKey(method, joinPaths(s.group.BasePath(), relativePath))] = scope
+	routeScopes.Unlock()
+}
+
+func (s ScopedGroup) GET(relativePath, scope string, handlers ...gin.HandlerFunc) {
+	s.Handle(http.MethodGet, relativePath, scope, handlers...)
+}
+
+func (s ScopedGroup) POST(relativePath, scope string, handlers ...gin.HandlerFunc) {
+	s.Handle(http.MethodPost, relativePath, scope, handlers...)
+}
+
+func (s ScopedGroup) PATCH(relativePath, scope string, handlers ...gin.HandlerFunc) {
+	s.Handle(http.MethodPatch, relativePath, scope, handlers...)
+}
+
+func (s ScopedGroup) DELETE(relativePath, scope string, handlers ...gin.HandlerFunc) {
+	s.Handle(http.MethodDelete, relativePath, scope, handlers...)
+}
+
+// routeScope returns the scope the matched route declared, if any.
+func routeScope(c *gin.Context) (string, bool) {
+	routeScopes.RLock()
+	defer routeScopes.RUnlock()
+
+	scope, ok := routeScopes.byRoute[routeKey(c.Request.Method, c.FullPath())]
+	return scope, ok
+}
+
+func routeKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// joinPaths builds a route's full path the way gin does, so it matches
+// c.FullPath().
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
+// authorizeAPIToken is the half of Authorize that handles personal access
+// tokens. The claims it sets have no access_uuid, since there is no session.
+func authorizeAPIToken(c *gin.Context, db *gorm.DB, tokenStr string) {
+	var token models.APIToken
+
+	if _, err := token.GetByTokenHash(db, utility.HashToken(tokenStr)); err != nil || token.IsExpired() {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, utility.BuildErrorResponse(http.StatusUnauthorized, "error", "Token is invalid!", "Unauthorized", nil))
+		return
+	}
+
+	if models.IsUserSuspended(db, token.UserID) {
+		c.AbortWithStatusJSON(http.StatusForbidden, utility.BuildErrorResponse(http.StatusForbidden, "error", "this account has been suspended", "Forbidden", nil))
+		return
+	}
+
+	if !token.AllowsIP(c.ClientIP()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, utility.BuildErrorResponse(http.StatusForbidden, "error", "token may not be used from this address", "Forbidden", nil))
+		return
+	}
+
+	scope, declared := routeScope(c)
+	if !declared {
+		c.AbortWithStatusJSON(http.StatusForbidden, utility.BuildErrorResponse(http.StatusForbidden, "error", "this endpoint does not accept api tokens", "Forbidden", nil))
+		return
+	}
+
+	if !token.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, utility.BuildErrorResponse(http.StatusForbidden, "error", fmt.Sprintf("token is missing the %v scope", scope), "Forbidden", nil))
+		return
+	}
+
+	// best effort: a failed write must not fail the request
+	_ = token.TouchLastUsed(db, c.ClientIP())
+
+	c.Set("userClaims", jwt.MapClaims{
+		"authorised":   true,
+		"user_id":      token.UserID,
+		"access_uuid":  "",
+		"api_token_id": token.ID,
+	})
+
+	c.Next()
+}
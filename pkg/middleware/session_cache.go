@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+const (
+	// sessionCacheMaxTTL caps how long a session is trusted from the cache.
+	// Invalidation is best effort, so this also bounds how long a missed one
+	// can keep a revoked session alive.
+	sessionCacheMaxTTL = 5 * time.Minute
+	// sessionCacheTimeout keeps a slow Redis from slowing every request; on
+	// timeout the session is read from Postgres.
+	sessionCacheTimeout = 100 * time.Millisecond
+)
+
+// cachedSession is the part of an AccessToken Authorize needs. Generation
+// is the owner's generation when the row was read; invalidating the owner
+// changes it, which orphans every entry written before.
+type cachedSession struct {
+	OwnerID    string     `json:"owner_id"`
+	TokenHash  string     `json:"token_hash"`
+	Generation string     `json:"generation"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+}
+
+func sessionCacheKey(accessID string) string {
+	return "session:" + accessID
+}
+
+func sessionGenerationKey(ownerID string) string {
+	return "session:generation:" + ownerID
+}
+
+func sessionCacheClient() *redis.Client {
+	return storage.DB.Redis
+}
+
+// getCachedSession returns the cached entry for accessID, nil on a miss, and
+// the owner's current generation to store with a fresh entry. An error means
+// Redis is unavailable and nothing should be cached.
+func getCachedSession(accessID, ownerID string) (*cachedSession, string, error) {
+	client := sessionCacheClient()
+	if client == nil {
+		return nil, "", redis.ErrClosed
+	}
+
+	ctx, cancel := context.WithTimeout(rdb.Ctx, sessionCacheTimeout)
+	defer cancel()
+
+	values, err := client.MGet(ctx, sessionCacheKey(accessID), sessionGenerationKey(ownerID)).Result()
+	if err != nil {
+		return nil, "", err
+	}
+
+	generation, _ := values[1].(string)
+
+	raw, ok := values[0].(string)
+	if !ok {
+		return nil, generation, nil
+	}
+
+	var session cachedSession
+	if err := json.Unmarshal([]byte(raw), &session); err != nil || session.Generation != generation {
+		return nil, generation, nil
+	}
+	return &session, generation, nil
+}
+
+// cacheSession stores a live token until it expires, for at most
+// sessionCacheMaxTTL.
+func cacheSession(token models.AccessToken, generation string, expiresAt time.Time) {
+	client := sessionCacheClient()
+	if client == nil {
+		return
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl > sessionCacheMaxTTL {
+		ttl = sessionCacheMaxTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	session, err := json.Marshal(cachedSession{
+		OwnerID:    token.OwnerID,
+		TokenHash:  utility.HashToken(token.LoginAccessToken),
+		Generation: generation,
+		LastUsedAt: token.LastUsedAt,
+		LastUsedIP: token.LastUsedIP,
+	})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(rdb.Ctx, sessionCacheTimeout)
+	defer cancel()
+	_ = client.Set(ctx, sessionCacheKey(token.ID), session, ttl).Err()
+}
+
+// InvalidateSessionCache makes Authorize read the owner's sessions from
+// Postgres again. Call it after revoking any of their tokens or changing
+// their credentials.
+func InvalidateSessionCache(ownerID string) {
+	client := sessionCacheClient()
+	if client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(rdb.Ctx, sessionCacheTimeout)
+	defer cancel()
+
+	// the generation must outlive every entry written under the old one
+	_ = client.Set(ctx, sessionGenerationKey(ownerID), utility.GenerateUUID(), 2*sessionCacheMaxTTL).Err()
+}
@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// signingKeyRefresh is how long an instance trusts a cached key before
+// checking whether another instance has rotated it.
+const signingKeyRefresh = time.Minute
+
+// signingKey is a SigningKey decoded for use with jwt.
+type signingKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   crypto.PrivateKey
+	Public    crypto.PublicKey
+	ExpiresAt *time.Time
+	loadedAt  time.Time
+}
+
+func (k *signingKey) expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+func (k *signingKey) stale(now time.Time) bool {
+	return now.Sub(k.loadedAt) >= signingKeyRefresh
+}
+
+// keyring caches signing keys so signing and verifying a token do not read
+// the database every time.
+type keyring struct {
+	mu      sync.RWMutex
+	current *signingKey
+	byID    map[string]*signingKey
+}
+
+var keys = &keyring{byID: map[string]*signingKey{}}
+
+// SigningAlgorithm is the algorithm new signing keys are generated for.
+func SigningAlgorithm() string {
+	if config.GetConfig().Server.JWTAlgorithm == utility.SigningAlgEdDSA {
+		return utility.SigningAlgEdDSA
+	}
+	return utility.SigningAlgRS256
+}
+
+// SigningKeySecret is what private signing keys are encrypted with at rest.
+func SigningKeySecret() string {
+	return config.GetConfig().Server.SigningKeySecret
+}
+
+func decodeSigningKey(key models.SigningKey, now time.Time) (*signingKey, error) {
+	method := jwt.GetSigningMethod(key.Algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported signing algorithm %v", key.Algorithm)
+	}
+
+	privatePEM, err := utility.OpenPrivateSigningKey(key.PrivateKey, SigningKeySecret())
+	if err != nil {
+		return nil, err
+	}
+	private, err := utility.ParsePrivateSigningKey(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+	public, err := utility.ParsePublicSigningKey(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{ID: key.ID, Method: method, Private: private, Public: public, ExpiresAt: key.ExpiresAt, loadedAt: now}, nil
+}
+
+// signing returns the key to sign with, creating the first key when there
+// is none yet.
+func (k *keyring) signing() (*signingKey, error) {
+	now := time.Now()
+
+	k.mu.RLock()
+	current := k.current
+	k.mu.RUnlock()
+	if current != nil && !current.stale(now) && !current.expired(now) {
+		return current, nil
+	}
+
+	db := storage.DB.Postgresql
+	key, err := models.GetCurrentSigningKey(db, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		key, err = createFirstSigningKey(db, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := decodeSigningKey(key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.current = decoded
+	k.byID[decoded.ID] = decoded
+	k.mu.Unlock()
+	return decoded, nil
+}
+
+// createFirstSigningKey adds a key to sign with under the signing key lock,
+// unless another instance got there first.
+func createFirstSigningKey(db *gorm.DB, now time.Time) (models.SigningKey, error) {
+	var key models.SigningKey
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := models.LockSigningKeys(tx); err != nil {
+			return err
+		}
+
+		var err error
+		key, err = models.GetCurrentSigningKey(tx, now)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		key, err = models.NewSigningKey(SigningAlgorithm(), now, SigningKeySecret())
+		if err != nil {
+			return err
+		}
+		return key.CreateSigningKey(tx)
+	})
+	return key, err
+}
+
+// verifying returns the unexpired key with the given kid.
+func (k *keyring) verifying(kid string) (*signingKey, error) {
+	now := time.Now()
+
+	if _, err := uuid.Parse(kid); err != nil {
+		return nil, errors.New("unknown signing key")
+	}
+
+	k.mu.RLock()
+	cached, ok := k.byID[kid]
+	k.mu.RUnlock()
+	if ok && !cached.stale(now) {
+		if cached.expired(now) {
+			return nil, errors.New("signing key has expired")
+		}
+		return cached, nil
+	}
+
+	key, err := models.GetSigningKeyByID(storage.DB.Postgresql, kid, now)
+	if err != nil {
+		k.mu.Lock()
+		delete(k.byID, kid)
+		k.mu.Unlock()
+		return nil, errors.New("unknown signing key")
+	}
+
+	decoded, err := decodeSigningKey(key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.byID[kid] = decoded
+	k.mu.Unlock()
+	return decoded, nil
+}
+
+// SignToken signs claims with the current signing key, naming it in the kid
+// header.
+func SignToken(claims jwt.Claims) (string, error) {
+	key, err := keys.signing()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
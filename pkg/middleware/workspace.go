@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// RoomScope keeps room routes inside their workspace. Under
+// /workspaces/:workspaceId the caller must be a member of the workspace and
+// any :roomId must belong to it; on the top level /rooms routes only rooms
+// outside a workspace are reachable. It must run after Authorize.
+func RoomScope(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspaceID := c.Param("workspaceId")
+
+		if workspaceID != "" {
+			if _, err := uuid.Parse(workspaceID); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, utility.BuildErrorResponse(http.StatusBadRequest, "error", "invalid workspace id format", "failed to parse workspace id", nil))
+				return
+			}
+
+			claims := c.MustGet("userClaims").(jwt.MapClaims)
+			userID, _ := claims["user_id"].(string)
+
+			var member models.WorkspaceMember
+			role, err := member.GetMemberRole(db, workspaceID, userID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, utility.BuildErrorResponse(http.StatusInternalServerError, "error", "failed to check workspace membership", err.Error(), nil))
+				return
+			}
+			if role == "" {
+				c.AbortWithStatusJSON(http.StatusNotFound, utility.BuildErrorResponse(http.StatusNotFound, "error", "workspace not found", "user not in workspace", nil))
+				return
+			}
+		}
+
+		roomID := c.Param("roomId")
+		if roomID != "" {
+			if _, err := uuid.Parse(roomID); err != nil {
+				// the handlers report malformed room ids themselves
+				c.Next()
+				return
+			}
+
+			// unknown rooms are left to the handlers; a room in another
+			// workspace is reported as missing
+			var room models.Room
+			exists := postgresql.CheckExists(db.Select("id", "workspace_id"), &room, "id = ?", roomID)
+			if exists && !inScope(room, workspaceID) {
+				c.AbortWithStatusJSON(http.StatusNotFound, utility.BuildErrorResponse(http.StatusNotFound, "error", "room does not exist", "room not found", nil))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func inScope(room models.Room, workspaceID string) bool {
+	if room.WorkspaceID == nil {
+		return workspaceID == ""
+	}
+	return *room.WorkspaceID == workspaceID
+}
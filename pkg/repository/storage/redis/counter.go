@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// incrWindowScript increments a counter and starts its expiry on the first hit,
+// returning the new count and the milliseconds left in the window.
+var incrWindowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// IncrWindow counts a hit against key in a fixed window of length window.
+func IncrWindow(rdb *redis.Client, key string, window time.Duration) (int64, time.Duration, error) {
+	result, err := incrWindowScript.Run(Ctx, rdb, []string{key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return result[0], time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// slidingWindowScript keeps one sorted set entry per hit, scored by Redis
+// server time so every instance agrees on the clock. It returns whether the
+// hit was admitted, the hits now in the window and the milliseconds until the
+// oldest one leaves it.
+var slidingWindowScript = redis.NewScript(`
+local now = redis.call("TIME")
+now = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+local count = redis.call("ZCARD", KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call("PEXPIRE", KEYS[1], window)
+
+local reset = window
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// SlidingWindow records a hit against key if fewer than limit hits fall in
+// the trailing window. member must be unique per hit.
+func SlidingWindow(rdb *redis.Client, key, member string, limit int64, window time.Duration) (bool, int64, time.Duration, error) {
+	result, err := slidingWindowScript.Run(Ctx, rdb, []string{key}, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return result[0] == 1, result[1], time.Duration(result[2]) * time.Millisecond, nil
+}
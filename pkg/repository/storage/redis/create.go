@@ -16,6 +16,15 @@ func RedisSet(rdb *redis.Client, key string, value interface{}) error {
 	return rdb.Set(Ctx, key, serialized, 24*time.Hour).Err()
 }
 
+// RedisSetWithExpiry is RedisSet with a caller chosen lifetime.
+func RedisSetWithExpiry(rdb *redis.Client, key string, value interface{}, expiry time.Duration) error {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return rdb.Set(Ctx, key, serialized, expiry).Err()
+}
+
 func PushToQueue(rdb *redis.Client, value interface{}) error {
 	jsonValue, err := json.Marshal(value)
 	if err != nil {
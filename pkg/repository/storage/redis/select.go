@@ -27,3 +27,23 @@ func PopFromQueue(rdb *redis.Client) (interface{}, error) {
 
 	return response, nil
 }
+
+// PeekQueue returns up to n of the oldest entries in the queue without
+// removing them, oldest first, along with the queue length.
+func PeekQueue(rdb *redis.Client, n int64) ([]string, int64, error) {
+	length, err := rdb.LLen(Ctx, KeyName).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// entries are pushed on the left and popped from the right
+	entries, err := rdb.LRange(Ctx, KeyName, -n, -1).Result()
+	if err != nil {
+		return nil, length, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, length, nil
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/admin"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func Admin(r *gin.Engine, ApiVersion string, validator *validator.Validate, db *storage.Database, logger *utility.Logger) *gin.Engine {
+	extReq := request.ExternalRequest{Logger: logger, Test: false}
+	admin := admin.Controller{Db: db, Validator: validator, Logger: logger, ExtReq: extReq}
+
+	adminUrl := middleware.Scoped(r.Group(
+		fmt.Sprintf("%v/admin", ApiVersion),
+		middleware.Authorize(db.Postgresql),
+		middleware.AdminOnly(db.Postgresql),
+	))
+	{
+		adminUrl.POST("/imports", models.ScopeAdmin, admin.CreateImport)
+		adminUrl.GET("/imports/:importId", models.ScopeAdmin, admin.GetImport)
+
+		adminUrl.GET("/users", models.ScopeAdmin, admin.ListUsers)
+		adminUrl.POST("/users/:userId/suspend", models.ScopeAdmin, admin.SuspendUser)
+		adminUrl.POST("/users/:userId/unsuspend", models.ScopeAdmin, admin.UnsuspendUser)
+		adminUrl.POST("/users/:userId/logout", models.ScopeAdmin, admin.ForceLogout)
+		adminUrl.POST("/users/:userId/verify-email", models.ScopeAdmin, admin.VerifyUserEmail)
+		adminUrl.POST("/users/:userId/unlock", models.ScopeAdmin, admin.UnlockUser)
+
+		adminUrl.GET("/rooms", models.ScopeAdmin, admin.ListRooms)
+		adminUrl.DELETE("/rooms/:roomId", models.ScopeAdmin, admin.DeleteRoom)
+
+		adminUrl.GET("/reports", models.ScopeAdmin, admin.GetModerationQueue)
+		adminUrl.PATCH("/reports/:reportId", models.ScopeAdmin, admin.ResolveReport)
+
+		adminUrl.GET("/notifications/queue", models.ScopeAdmin, admin.GetNotificationQueue)
+	}
+	return r
+}
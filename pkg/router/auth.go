@@ -10,6 +10,8 @@ import (
 	"github.com/hngprojects/telex_be/pkg/controller/auth"
 	"github.com/hngprojects/telex_be/pkg/middleware"
 	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	service "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/services/send"
 	"github.com/hngprojects/telex_be/utility"
 )
 
@@ -17,7 +19,15 @@ func Auth(r *gin.Engine, ApiVersion string, validator *validator.Validate, db *s
 	extReq := request.ExternalRequest{Logger: logger, Test: false}
 	auth := auth.Controller{Db: db, Validator: validator, Logger: logger, ExtReq: extReq}
 
-	authUrl := r.Group(fmt.Sprintf("%v/auth", ApiVersion))
+	service.UseOAuthProviders(logger)
+
+	smsSender, err := send.NewSMSSender(logger)
+	if err != nil {
+		utility.LogAndPrint(logger, fmt.Sprintf("phone codes are disabled: %v", err))
+	}
+	send.UseSMSSender(smsSender)
+
+	authUrl := r.Group(fmt.Sprintf("%v/auth", ApiVersion), middleware.RateLimit(db.Redis, middleware.AuthRateLimit))
 	{
 		authUrl.POST("/register", auth.RegisterUser)
 		authUrl.POST("/login", auth.LoginUser)
@@ -28,17 +38,53 @@ func Auth(r *gin.Engine, ApiVersion string, validator *validator.Validate, db *s
 		authUrl.POST("/email-request", auth.VerifyEmailReq)
 		authUrl.POST("/email-request/verify", auth.VerifyEmailToken)
 		authUrl.POST("/google", auth.GoogleLogin)
+		authUrl.GET("/oauth/:provider", auth.BeginOAuth)
+		authUrl.GET("/oauth/:provider/callback", auth.OAuthCallback)
+		authUrl.POST("/2fa/verify", auth.VerifyTwoFactor)
+		authUrl.GET("/not-me", auth.ConfirmSignInReport)
+		authUrl.POST("/not-me", auth.ReportSignIn)
+		authUrl.POST("/phone/login", auth.RequestPhoneLogin)
+		authUrl.POST("/phone/login/verify", auth.VerifyPhoneLogin)
+	}
+
+	// refreshes are routine for signed in clients, so they are counted per
+	// token rather than against the tight per-IP auth allowance
+	refreshUrl := r.Group(
+		fmt.Sprintf("%v/auth", ApiVersion),
+		middleware.RateLimit(db.Redis, middleware.AnonymousRateLimit),
+		middleware.RateLimit(db.Redis, middleware.RefreshRateLimit),
+	)
+	{
+		refreshUrl.POST("/refresh", auth.RefreshToken)
 	}
 
 	authUrlSec := r.Group(
 		fmt.Sprintf("%v/auth", ApiVersion),
 		middleware.Authorize(db.Postgresql),
+		middleware.RateLimit(db.Redis, middleware.AccountRateLimit),
 	)
 
 	{
 		authUrlSec.POST("/logout", auth.LogoutUser)
 		authUrlSec.PUT("/change-password", auth.ChangePassword)
+		authUrlSec.GET("/sessions", auth.GetSessions)
+		authUrlSec.DELETE("/sessions/:sessionId", auth.RevokeSession)
+		authUrlSec.POST("/sessions/revoke-others", auth.RevokeOtherSessions)
+		authUrlSec.POST("/2fa/enroll", auth.EnrollTwoFactor)
+		authUrlSec.POST("/2fa/confirm", auth.ConfirmTwoFactor)
+		authUrlSec.POST("/2fa/recovery-codes", auth.RegenerateRecoveryCodes)
+		authUrlSec.POST("/2fa/disable", auth.DisableTwoFactor)
+		authUrlSec.GET("/identities", auth.GetIdentities)
+		authUrlSec.POST("/identities/:provider", auth.LinkIdentity)
+		authUrlSec.DELETE("/identities/:identityId", auth.UnlinkIdentity)
+		authUrlSec.GET("/api-tokens", auth.GetAPITokens)
+		authUrlSec.POST("/api-tokens", auth.CreateAPIToken)
+		authUrlSec.DELETE("/api-tokens/:tokenId", auth.RevokeAPIToken)
+		authUrlSec.POST("/phone/verify", auth.RequestPhoneVerification)
+		authUrlSec.POST("/phone/verify/confirm", auth.ConfirmPhoneVerification)
 	}
 
+	r.GET("/.well-known/jwks.json", middleware.RateLimit(db.Redis, middleware.AnonymousRateLimit), auth.GetJWKS)
+
 	return r
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/hngprojects/telex_be/external/request"
 	"github.com/hngprojects/telex_be/pkg/controller/health"
+	"github.com/hngprojects/telex_be/pkg/middleware"
 	"github.com/hngprojects/telex_be/pkg/repository/storage"
 	"github.com/hngprojects/telex_be/utility"
 )
@@ -15,7 +16,7 @@ func Health(r *gin.Engine, ApiVersion string, validator *validator.Validate, db
 	extReq := request.ExternalRequest{Logger: logger, Test: false}
 	health := health.Controller{Db: db, Validator: validator, Logger: logger, ExtReq: extReq}
 
-	healthUrl := r.Group(fmt.Sprintf("%v", ApiVersion))
+	healthUrl := r.Group(fmt.Sprintf("%v", ApiVersion), middleware.RateLimit(db.Redis, middleware.AnonymousRateLimit))
 	{
 		healthUrl.POST("/health", health.Post)
 		healthUrl.GET("/health", health.Get)
@@ -7,6 +7,7 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
 	"github.com/hngprojects/telex_be/pkg/controller/room"
 	"github.com/hngprojects/telex_be/pkg/middleware"
 	"github.com/hngprojects/telex_be/pkg/repository/storage"
@@ -17,23 +18,56 @@ func Room(r *gin.Engine, ApiVersion string, validator *validator.Validate, db *s
 	extReq := request.ExternalRequest{Logger: logger, Test: false}
 	room := room.Controller{Db: db, Validator: validator, Logger: logger, ExtReq: extReq}
 
-	roomUrl := r.Group(fmt.Sprintf("%v/rooms", ApiVersion), middleware.Authorize(db.Postgresql))
+	roomUrl := r.Group(fmt.Sprintf("%v/rooms", ApiVersion), middleware.Authorize(db.Postgresql), middleware.RoomScope(db.Postgresql))
+	roomRoutes(middleware.Scoped(roomUrl), room)
+
+	workspaceRoomUrl := r.Group(fmt.Sprintf("%v/workspaces/:workspaceId/rooms", ApiVersion), middleware.Authorize(db.Postgresql), middleware.RoomScope(db.Postgresql))
+	roomRoutes(middleware.Scoped(workspaceRoomUrl), room)
+
+	exportUrl := r.Group(fmt.Sprintf("%v/exports", ApiVersion), middleware.RateLimit(db.Redis, middleware.AnonymousRateLimit))
 	{
-		roomUrl.POST("/", room.CreateRoom)
-		roomUrl.POST("/:roomId/messages", room.AddRoomMsg)
-		roomUrl.POST("/:roomId/join", room.JoinRoom)
-		roomUrl.POST("/:roomId/leave", room.LeaveRoom)
-		roomUrl.DELETE("/:roomId", room.DeleteRoom)
-		roomUrl.PATCH("/:roomId/username", room.UpdateUsername)
-		roomUrl.GET("/", room.GetRooms)
-		roomUrl.GET("/:roomId", room.GetRoom)
-		roomUrl.GET("/:roomId/messages", room.GetRoomMsg)
-		roomUrl.GET("/:roomId/user-exist", room.CheckUser)
-		roomUrl.GET("/name/:roomName", room.GetRoomByName)
-		roomUrl.GET("/:roomId/num-users", room.CountRoomUsers)
-		roomUrl.PATCH("/:roomId", room.UpdateRoom)
-
-		roomUrl.GET("/search/:roomName", room.SearchRoomByNames)
+		exportUrl.GET("/:exportId/download", room.DownloadRoomExport)
 	}
 	return r
 }
+
+// roomRoutes registers the room endpoints on a group. They are mounted both
+// for rooms outside a workspace and under each workspace.
+func roomRoutes(roomUrl middleware.ScopedGroup, room room.Controller) {
+	roomUrl.POST("/", models.ScopeRoomsWrite, room.CreateRoom)
+	roomUrl.POST("/:roomId/messages", models.ScopeMessagesWrite, middleware.RateLimit(room.Db.Redis, middleware.MessageRateLimit), room.AddRoomMsg)
+	roomUrl.POST("/:roomId/join", models.ScopeRoomsWrite, room.JoinRoom)
+	roomUrl.POST("/:roomId/leave", models.ScopeRoomsWrite, room.LeaveRoom)
+	roomUrl.DELETE("/:roomId", models.ScopeRoomsWrite, room.DeleteRoom)
+	roomUrl.PATCH("/:roomId/username", models.ScopeRoomsWrite, room.UpdateUsername)
+	roomUrl.GET("/", models.ScopeRoomsRead, room.GetRooms)
+	roomUrl.GET("/:roomId", models.ScopeRoomsRead, room.GetRoom)
+	roomUrl.GET("/:roomId/messages", models.ScopeMessagesRead, room.GetRoomMsg)
+	roomUrl.GET("/:roomId/user-exist", models.ScopeRoomsRead, room.CheckUser)
+	roomUrl.GET("/name/:roomName", models.ScopeRoomsRead, room.GetRoomByName)
+	roomUrl.GET("/:roomId/num-users", models.ScopeRoomsRead, room.CountRoomUsers)
+	roomUrl.PATCH("/:roomId", models.ScopeRoomsWrite, room.UpdateRoom)
+	roomUrl.PATCH("/:roomId/retention", models.ScopeRoomsWrite, room.UpdateRoomRetention)
+	roomUrl.PATCH("/:roomId/slow-mode", models.ScopeRoomsWrite, room.UpdateSlowMode)
+	roomUrl.PATCH("/:roomId/members/:userId/role", models.ScopeRoomsWrite, room.UpdateMemberRole)
+	roomUrl.DELETE("/:roomId/members/:userId", models.ScopeRoomsWrite, room.KickMember)
+	roomUrl.POST("/:roomId/transfer-ownership", models.ScopeRoomsWrite, room.TransferOwnership)
+	roomUrl.GET("/:roomId/events", models.ScopeRoomsRead, room.GetRoomEvents)
+
+	roomUrl.GET("/search/:roomName", models.ScopeRoomsRead, room.SearchRoomByNames)
+
+	roomUrl.POST("/:roomId/scheduled-messages", models.ScopeMessagesWrite, room.CreateScheduledMessage)
+	roomUrl.GET("/:roomId/scheduled-messages", models.ScopeMessagesRead, room.GetScheduledMessages)
+	roomUrl.PATCH("/:roomId/scheduled-messages/:scheduledId", models.ScopeMessagesWrite, room.UpdateScheduledMessage)
+	roomUrl.DELETE("/:roomId/scheduled-messages/:scheduledId", models.ScopeMessagesWrite, room.CancelScheduledMessage)
+
+	roomUrl.POST("/:roomId/exports", models.ScopeMessagesRead, room.CreateRoomExport)
+	roomUrl.GET("/:roomId/exports/:exportId", models.ScopeMessagesRead, room.GetRoomExport)
+
+	roomUrl.POST("/:roomId/messages/:messageId/report", models.ScopeMessagesWrite, room.ReportMessage)
+	roomUrl.GET("/:roomId/reports", models.ScopeRoomsRead, room.GetRoomReports)
+	roomUrl.PATCH("/:roomId/reports/:reportId", models.ScopeRoomsWrite, room.ResolveReport)
+	roomUrl.GET("/:roomId/word-filters", models.ScopeRoomsRead, room.GetWordFilters)
+	roomUrl.POST("/:roomId/word-filters", models.ScopeRoomsWrite, room.CreateWordFilter)
+	roomUrl.DELETE("/:roomId/word-filters/:filterId", models.ScopeRoomsWrite, room.DeleteWordFilter)
+}
@@ -38,6 +38,9 @@ func Setup(logger *utility.Logger, validator *validator.Validate, db *storage.Da
 	Auth(r, ApiVersion, validator, db, logger)
 	Room(r, ApiVersion, validator, db, logger)
 	TokenGen(r, ApiVersion, validator, db, logger)
+	Admin(r, ApiVersion, validator, db, logger)
+	Workspace(r, ApiVersion, validator, db, logger)
+	User(r, ApiVersion, validator, db, logger)
 
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
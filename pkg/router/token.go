@@ -7,6 +7,7 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
 	"github.com/hngprojects/telex_be/pkg/controller/token"
 	"github.com/hngprojects/telex_be/pkg/middleware"
 	"github.com/hngprojects/telex_be/pkg/repository/storage"
@@ -17,10 +18,10 @@ func TokenGen(r *gin.Engine, ApiVersion string, validator *validator.Validate, d
 	extReq := request.ExternalRequest{Logger: logger, Test: false}
 	token := token.Controller{Db: db, Validator: validator, Logger: logger, ExtReq: extReq}
 
-	tokenUrl := r.Group(fmt.Sprintf("%v/token", ApiVersion), middleware.Authorize(db.Postgresql))
+	tokenUrl := middleware.Scoped(r.Group(fmt.Sprintf("%v/token", ApiVersion), middleware.Authorize(db.Postgresql)))
 	{
-		tokenUrl.GET("/connection", token.GetConnToken)
-		tokenUrl.POST("/subscription", token.GetSubToken)
+		tokenUrl.GET("/connection", models.ScopeMessagesRead, token.GetConnToken)
+		tokenUrl.POST("/subscription", models.ScopeMessagesRead, token.GetSubToken)
 	}
 	return r
 }
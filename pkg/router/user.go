@@ -0,0 +1,51 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/pkg/controller/user"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	service "github.com/hngprojects/telex_be/services/user"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func User(r *gin.Engine, ApiVersion string, validator *validator.Validate, db *storage.Database, logger *utility.Logger) *gin.Engine {
+	extReq := request.ExternalRequest{Logger: logger, Test: false}
+	user := user.Controller{Db: db, Validator: validator, Logger: logger, ExtReq: extReq}
+
+	meUrl := r.Group(fmt.Sprintf("%v/me", ApiVersion), middleware.Authorize(db.Postgresql))
+	{
+		meUrl.GET("", user.GetMe)
+		meUrl.PATCH("", user.UpdateMe)
+		meUrl.POST("/avatar", user.UploadAvatar)
+		meUrl.POST("/export", user.RequestExport)
+		meUrl.DELETE("", user.DeleteMe)
+		meUrl.POST("/restore", user.RestoreMe)
+		meUrl.POST("/email", user.ChangeEmail)
+		meUrl.POST("/email/confirm", user.ConfirmEmailChange)
+		meUrl.GET("/security-events", user.GetSecurityEvents)
+	}
+
+	userUrl := r.Group(fmt.Sprintf("%v/users", ApiVersion), middleware.RateLimit(db.Redis, middleware.AnonymousRateLimit))
+	{
+		userUrl.GET("/:userId", user.GetPublicProfile)
+	}
+
+	emailUrl := r.Group(fmt.Sprintf("%v/email-change", ApiVersion), middleware.RateLimit(db.Redis, middleware.AnonymousRateLimit))
+	{
+		emailUrl.POST("/revert", user.RevertEmailChange)
+	}
+
+	exportUrl := r.Group(fmt.Sprintf("%v/account-exports", ApiVersion), middleware.RateLimit(db.Redis, middleware.AnonymousRateLimit))
+	{
+		exportUrl.GET("/:exportId/download", user.DownloadExport)
+	}
+
+	r.Static(fmt.Sprintf("%v/avatars", ApiVersion), service.AvatarDir())
+	return r
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/workspace"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func Workspace(r *gin.Engine, ApiVersion string, validator *validator.Validate, db *storage.Database, logger *utility.Logger) *gin.Engine {
+	extReq := request.ExternalRequest{Logger: logger, Test: false}
+	workspace := workspace.Controller{Db: db, Validator: validator, Logger: logger, ExtReq: extReq}
+
+	workspaceUrl := middleware.Scoped(r.Group(fmt.Sprintf("%v/workspaces", ApiVersion), middleware.Authorize(db.Postgresql)))
+	{
+		workspaceUrl.POST("/", models.ScopeWorkspacesWrite, workspace.CreateWorkspace)
+		workspaceUrl.GET("/", models.ScopeWorkspacesRead, workspace.GetWorkspaces)
+		workspaceUrl.GET("/:workspaceId", models.ScopeWorkspacesRead, workspace.GetWorkspace)
+		workspaceUrl.PATCH("/:workspaceId", models.ScopeWorkspacesWrite, workspace.UpdateWorkspace)
+
+		workspaceUrl.GET("/:workspaceId/members", models.ScopeWorkspacesRead, workspace.GetMembers)
+		workspaceUrl.PATCH("/:workspaceId/members/:userId", models.ScopeWorkspacesWrite, workspace.UpdateMemberRole)
+		workspaceUrl.DELETE("/:workspaceId/members/:userId", models.ScopeWorkspacesWrite, workspace.RemoveMember)
+
+		workspaceUrl.POST("/:workspaceId/invites", models.ScopeWorkspacesWrite, workspace.CreateInvite)
+		workspaceUrl.GET("/:workspaceId/invites", models.ScopeWorkspacesRead, workspace.GetInvites)
+		workspaceUrl.DELETE("/:workspaceId/invites/:inviteId", models.ScopeWorkspacesWrite, workspace.DeleteInvite)
+		workspaceUrl.POST("/:workspaceId/invites/accept", models.ScopeWorkspacesWrite, workspace.AcceptInvite)
+
+		workspaceUrl.POST("/:workspaceId/domains", models.ScopeWorkspacesWrite, workspace.CreateDomain)
+		workspaceUrl.DELETE("/:workspaceId/domains/:domainId", models.ScopeWorkspacesWrite, workspace.DeleteDomain)
+	}
+	return r
+}
@@ -17,6 +17,14 @@ const (
 	SendMagicLink             NotificationName = "send_magic_link"
 	SendSqueeze               NotificationName = "send_squeeze"
 	SendContactUsMail         NotificationName = "send_contact_us"
+	SendRoomExportReady       NotificationName = "send_room_export_ready"
+	SendWorkspaceInvite       NotificationName = "send_workspace_invite"
+	SendAccountLocked         NotificationName = "send_account_locked"
+	SendAccountExportReady    NotificationName = "send_account_export_ready"
+	SendAccountDeletion       NotificationName = "send_account_deletion"
+	SendEmailChangeConfirm    NotificationName = "send_email_change_confirm"
+	SendEmailChangeNotice     NotificationName = "send_email_change_notice"
+	SendNewSignIn             NotificationName = "send_new_sign_in"
 )
 
 func Check() {
@@ -39,6 +39,30 @@ func Send(extReq request.ExternalRequest, db *gorm.DB, rdb *redis.Client, notifi
 		names.SendContactUsMail: func() error {
 			return req.SendContactUsMail()
 		},
+		names.SendRoomExportReady: func() error {
+			return req.SendRoomExportReady()
+		},
+		names.SendWorkspaceInvite: func() error {
+			return req.SendWorkspaceInvite()
+		},
+		names.SendAccountLocked: func() error {
+			return req.SendAccountLocked()
+		},
+		names.SendAccountExportReady: func() error {
+			return req.SendAccountExportReady()
+		},
+		names.SendAccountDeletion: func() error {
+			return req.SendAccountDeletion()
+		},
+		names.SendEmailChangeConfirm: func() error {
+			return req.SendEmailChangeConfirm()
+		},
+		names.SendEmailChangeNotice: func() error {
+			return req.SendEmailChangeNotice()
+		},
+		names.SendNewSignIn: func() error {
+			return req.SendNewSignIn()
+		},
 	}
 
 	err = callEmailFunc[name]()
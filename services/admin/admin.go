@@ -0,0 +1,154 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// queuePeekLimit is how many pending notifications an admin sees at once.
+const queuePeekLimit = 50
+
+func getUser(db *gorm.DB, userId string) (models.User, int, error) {
+	var user models.User
+
+	user, err := user.GetUserByID(db, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, http.StatusNotFound, errors.New("user not found")
+		}
+		return user, http.StatusInternalServerError, err
+	}
+	return user, http.StatusOK, nil
+}
+
+func ListUsers(db *gorm.DB, c *gin.Context) ([]models.User, postgresql.PaginationResponse, int, error) {
+	var user models.User
+
+	users, paginationResponse, err := user.SearchUsers(db, c, c.Query("search"))
+	if err != nil {
+		return nil, paginationResponse, http.StatusInternalServerError, err
+	}
+	return users, paginationResponse, http.StatusOK, nil
+}
+
+// SuspendUser blocks the user from signing in and ends their sessions.
+// Admins cannot suspend themselves.
+func SuspendUser(db *gorm.DB, req models.SuspendUserRequest, userId, adminId string) (int, error) {
+	var accessToken models.AccessToken
+
+	if userId == adminId {
+		return http.StatusBadRequest, errors.New("you cannot suspend your own account")
+	}
+
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return code, err
+	}
+
+	err = user.SetSuspended(db, true, req.Reason)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	_, err = accessToken.RevokeAllSessions(db, userId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	middleware.InvalidateSessionCache(userId)
+	return http.StatusOK, nil
+}
+
+func UnsuspendUser(db *gorm.DB, userId string) (int, error) {
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return code, err
+	}
+
+	if !user.IsSuspended() {
+		return http.StatusBadRequest, errors.New("user is not suspended")
+	}
+
+	err = user.SetSuspended(db, false, "")
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// ForceLogout ends every session of the user. API tokens are left alone;
+// the user can revoke those themselves.
+func ForceLogout(db *gorm.DB, userId string) (int64, int, error) {
+	var accessToken models.AccessToken
+
+	_, code, err := getUser(db, userId)
+	if err != nil {
+		return 0, code, err
+	}
+
+	revoked, err := accessToken.RevokeAllSessions(db, userId)
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	middleware.InvalidateSessionCache(userId)
+	return revoked, http.StatusOK, nil
+}
+
+func VerifyUserEmail(db *gorm.DB, userId string) (int, error) {
+	_, code, err := getUser(db, userId)
+	if err != nil {
+		return code, err
+	}
+
+	_, err = postgresql.UpdateFields(db, &models.User{}, map[string]interface{}{"is_verified": true}, "id = ?", userId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func ListRooms(db *gorm.DB, c *gin.Context) ([]models.Room, postgresql.PaginationResponse, int, error) {
+	var room models.Room
+
+	rooms, paginationResponse, err := room.ListAllRooms(db, c, c.Query("search"))
+	if err != nil {
+		return nil, paginationResponse, http.StatusInternalServerError, err
+	}
+	return rooms, paginationResponse, http.StatusOK, nil
+}
+
+// DeleteRoom removes any room regardless of who owns it.
+func DeleteRoom(db *gorm.DB, roomId string) (int, error) {
+	var room models.Room
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomId)
+	if !exists {
+		return http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	err := room.Delete(db)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+func GetNotificationQueue(rdb *redis.Client) (gin.H, int, error) {
+	queued, length, err := models.PeekNotificationQueue(rdb, queuePeekLimit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	responseData := gin.H{
+		"length": length,
+		"next":   queued,
+	}
+	return responseData, http.StatusOK, nil
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// apiTokenPrefixLength is how much of a token is kept in clear so users can
+// recognise it in listings.
+const apiTokenPrefixLength = 12
+
+// CreateAPIToken issues a personal access token. The token itself is only
+// returned here; afterwards just its hash is known.
+func CreateAPIToken(db *gorm.DB, req models.CreateAPITokenRequest, userId string) (gin.H, int, error) {
+	secret, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	token := models.APITokenPrefix + secret
+
+	apiToken := models.APIToken{
+		ID:         utility.GenerateUUID(),
+		UserID:     userId,
+		Name:       req.Name,
+		Prefix:     token[:apiTokenPrefixLength],
+		TokenHash:  utility.HashToken(token),
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiToken.ExpiresAt = &expiresAt
+	}
+
+	err = apiToken.CreateAPIToken(db)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	responseData := gin.H{
+		"api_token": apiToken,
+		"token":     token,
+	}
+	return responseData, http.StatusCreated, nil
+}
+
+func GetAPITokens(db *gorm.DB, userId string) ([]models.APIToken, int, error) {
+	tokens, err := models.GetUserAPITokens(db, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return tokens, http.StatusOK, nil
+}
+
+func RevokeAPIToken(db *gorm.DB, userId, tokenId string) (int, error) {
+	return models.DeleteAPIToken(db, userId, tokenId)
+}
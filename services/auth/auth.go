@@ -96,39 +96,91 @@ func CreateUser(req models.CreateUserRequestModel, db *gorm.DB) (gin.H, int, err
 	return nil, http.StatusCreated, nil
 }
 
-func LoginUser(req models.LoginRequestModel, db *gorm.DB) (gin.H, int, error) {
+func LoginUser(req models.LoginRequestModel, db *gorm.DB, client models.ClientInfo, logger *utility.Logger) (gin.H, int, error) {
 
 	var (
 		user         = models.User{}
 		responseData gin.H
 	)
 
+	guard := newAttemptGuard(db, guardLogin, client.IPAddress, req.Email)
+	if err := guard.Check(); err != nil {
+		return responseData, http.StatusTooManyRequests, err
+	}
+
 	// Check if the user email exists
 	exists := postgresql.CheckExists(db, &user, "email = ?", req.Email)
 	if !exists {
+		guard.Fail(client.IPAddress)
 		return responseData, 400, fmt.Errorf("invalid credentials")
 	}
 
 	if !utility.CompareHash(req.Password, user.Password) {
+		guard.Fail(client.IPAddress)
+		recordSecurityEvent(db, user.ID, models.SecurityEventLoginFailed, client)
 		return responseData, 400, fmt.Errorf("invalid credentials")
 	}
+	guard.Succeed()
+
+	if user.IsSuspended() {
+		return responseData, http.StatusForbidden, errAccountSuspended
+	}
+
+	if user.MustResetPassword {
+		return responseData, http.StatusForbidden, errPasswordResetRequired
+	}
+
+	// with 2FA on, the password only earns a challenge; the session is
+	// issued by VerifyTwoFactor
+	if models.IsTwoFactorEnabled(db, user.ID) {
+		return createTwoFactorChallenge(user.ID)
+	}
+
+	return completeLogin(db, user, client, logger)
+}
+
+var errAccountSuspended = errors.New("this account has been suspended")
+
+var errPasswordResetRequired = errors.New("a sign-in to this account was reported; reset your password to continue")
+
+// autoJoinWorkspaces is best effort: a failure must not block signup or
+// login, and the join is retried on the next login.
+func autoJoinWorkspaces(db *gorm.DB, user models.User, logger *utility.Logger) {
+	if err := models.AutoJoinWorkspaces(db, user); err != nil {
+		logger.Error("error auto-joining workspaces: ", user.ID, err.Error())
+	}
+}
+
+// completeLogin issues a session for user once every login factor has been
+// checked.
+func completeLogin(db *gorm.DB, user models.User, client models.ClientInfo, logger *utility.Logger) (gin.H, int, error) {
+	var responseData gin.H
 
 	userData, err := user.GetUserByID(db, user.ID)
 	if err != nil {
 		return responseData, http.StatusInternalServerError, fmt.Errorf("unable to fetch user " + err.Error())
 	}
 
+	if userData.IsSuspended() {
+		return responseData, http.StatusForbidden, errAccountSuspended
+	}
+
+	// picks up domains added to a workspace since the user last logged in
+	autoJoinWorkspaces(db, userData, logger)
+
 	tokenData, err := middleware.CreateToken(user)
 	if err != nil {
 		return responseData, http.StatusInternalServerError, fmt.Errorf("error saving token: " + err.Error())
 	}
 
 	tokens := map[string]string{
-		"access_token": tokenData.AccessToken,
-		"exp":          strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
+		"access_token":  tokenData.AccessToken,
+		"exp":           strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
+		"refresh_token": tokenData.RefreshToken,
+		"refresh_exp":   strconv.Itoa(int(tokenData.RefreshExpiresAt.Unix())),
 	}
 
-	access_token := models.AccessToken{ID: tokenData.AccessUuid, OwnerID: user.ID}
+	access_token := models.AccessToken{ID: tokenData.AccessUuid, OwnerID: user.ID, IPAddress: client.IPAddress, UserAgent: client.UserAgent}
 
 	err = access_token.CreateAccessToken(db, tokens)
 
@@ -136,6 +188,8 @@ func LoginUser(req models.LoginRequestModel, db *gorm.DB) (gin.H, int, error) {
 		return responseData, http.StatusInternalServerError, fmt.Errorf("error saving token: " + err.Error())
 	}
 
+	recordLoginEvent(db, user.ID, tokenData.AccessUuid, client)
+
 	responseData = gin.H{
 
 		"user": map[string]interface{}{
@@ -143,6 +197,7 @@ func LoginUser(req models.LoginRequestModel, db *gorm.DB) (gin.H, int, error) {
 			"email":       userData.Email,
 			"username":    userData.Name,
 			"is_verified": userData.IsVerified,
+			"role":        userData.Role,
 			"first_name":  userData.Profile.FirstName,
 			"last_name":   userData.Profile.LastName,
 			"fullname":    userData.Profile.FirstName + " " + userData.Profile.LastName,
@@ -152,7 +207,8 @@ func LoginUser(req models.LoginRequestModel, db *gorm.DB) (gin.H, int, error) {
 			"created_at":  strconv.Itoa(int(userData.CreatedAt.Unix())),
 			"updated_at":  strconv.Itoa(int(userData.UpdatedAt.Unix())),
 		},
-		"access_token": tokenData.AccessToken,
+		"access_token":  tokenData.AccessToken,
+		"refresh_token": tokenData.RefreshToken,
 	}
 
 	return responseData, http.StatusOK, nil
@@ -172,13 +228,14 @@ func LogoutUser(access_uuid, owner_id string, db *gorm.DB) (gin.H, int, error) {
 	if err != nil {
 		return responseData, http.StatusInternalServerError, fmt.Errorf("error revoking user session: " + err.Error())
 	}
+	middleware.InvalidateSessionCache(owner_id)
 
 	responseData = gin.H{}
 
 	return responseData, http.StatusOK, nil
 }
 
-func CreateAdmin(req models.CreateUserRequestModel, db *gorm.DB) (gin.H, int, error) {
+func CreateAdmin(req models.CreateUserRequestModel, db *gorm.DB, client models.ClientInfo) (gin.H, int, error) {
 
 	var (
 		email        = strings.ToLower(req.Email)
@@ -200,6 +257,7 @@ func CreateAdmin(req models.CreateUserRequestModel, db *gorm.DB) (gin.H, int, er
 		Name:     username,
 		Email:    email,
 		Password: password,
+		Role:     models.RoleAdmin,
 		Profile: models.Profile{
 			ID:        utility.GenerateUUID(),
 			FirstName: firstName,
@@ -219,11 +277,13 @@ func CreateAdmin(req models.CreateUserRequestModel, db *gorm.DB) (gin.H, int, er
 	}
 
 	tokens := map[string]string{
-		"access_token": tokenData.AccessToken,
-		"exp":          strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
+		"access_token":  tokenData.AccessToken,
+		"exp":           strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
+		"refresh_token": tokenData.RefreshToken,
+		"refresh_exp":   strconv.Itoa(int(tokenData.RefreshExpiresAt.Unix())),
 	}
 
-	access_token := models.AccessToken{ID: tokenData.AccessUuid, OwnerID: user.ID}
+	access_token := models.AccessToken{ID: tokenData.AccessUuid, OwnerID: user.ID, IPAddress: client.IPAddress, UserAgent: client.UserAgent}
 
 	err = access_token.CreateAccessToken(db, tokens)
 
@@ -240,11 +300,13 @@ func CreateAdmin(req models.CreateUserRequestModel, db *gorm.DB) (gin.H, int, er
 			"last_name":  user.Profile.LastName,
 			"fullname":   user.Profile.FirstName + " " + user.Profile.LastName,
 			"phone":      user.Profile.Phone,
+			"role":       user.Role,
 			"expires_in": strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
 			"created_at": strconv.Itoa(int(user.CreatedAt.Unix())),
 			"updated_at": strconv.Itoa(int(user.UpdatedAt.Unix())),
 		},
-		"access_token": tokenData.AccessToken,
+		"access_token":  tokenData.AccessToken,
+		"refresh_token": tokenData.RefreshToken,
 	}
 
 	return responseData, http.StatusCreated, nil
@@ -0,0 +1,332 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/markbates/goth"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+const (
+	// reauthWindow is how recent a login must be to stand in for a password
+	// on accounts that do not have one.
+	reauthWindow = 10 * time.Minute
+	// identityLinkLifetime matches the time gothic gives the provider round trip.
+	identityLinkLifetime = oauthStateLifetime * time.Second
+)
+
+// externalIdentity is a login vouched for by an outside provider.
+type externalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	FirstName     string
+	LastName      string
+	AvatarURL     string
+}
+
+func identityLinkKey(token string) string {
+	return "oauth:link:" + utility.HashToken(token)
+}
+
+func gothIdentity(gothUser goth.User) externalIdentity {
+	return externalIdentity{
+		Provider:      gothUser.Provider,
+		Subject:       gothUser.UserID,
+		Email:         strings.ToLower(gothUser.Email),
+		EmailVerified: oauthEmailVerified(gothUser),
+		Username:      oauthUsername(gothUser),
+		FirstName:     gothUser.FirstName,
+		LastName:      gothUser.LastName,
+		AvatarURL:     gothUser.AvatarURL,
+	}
+}
+
+// findOrCreateIdentityUser returns the account behind a provider login. A
+// known subject wins; otherwise an existing account is only matched by email
+// when the provider has verified the address, so an unverified address at
+// some provider cannot be used to take over an account.
+func findOrCreateIdentityUser(db *gorm.DB, ident externalIdentity) (models.User, int, error) {
+	var (
+		user     models.User
+		identity models.UserIdentity
+	)
+
+	if ident.Subject == "" {
+		return user, http.StatusBadRequest, errors.New("the provider did not identify the account")
+	}
+
+	code, err := identity.GetByProviderSubject(db, ident.Provider, ident.Subject)
+	if err == nil {
+		_ = identity.TouchLastUsed(db)
+		user, err = user.GetUserWithProfile(db, identity.UserID)
+		if err != nil {
+			return user, http.StatusInternalServerError, fmt.Errorf("unable to fetch user " + err.Error())
+		}
+		return user, http.StatusOK, nil
+	}
+	if code != http.StatusNotFound {
+		return user, code, err
+	}
+
+	if ident.Email == "" {
+		return user, http.StatusBadRequest, errors.New("the provider did not share an email address")
+	}
+
+	now := time.Now()
+	identity = models.UserIdentity{
+		ID:         utility.GenerateUUID(),
+		Provider:   ident.Provider,
+		Subject:    ident.Subject,
+		Email:      ident.Email,
+		LastUsedAt: &now,
+	}
+
+	existing, err := user.GetUserByEmail(db, ident.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, http.StatusInternalServerError, err
+	}
+	if err == nil {
+		if !ident.EmailVerified {
+			return user, http.StatusConflict, fmt.Errorf("an account already uses this email address, sign in and link %v from your settings", ident.Provider)
+		}
+
+		identity.UserID = existing.ID
+		err = identity.CreateIdentity(db)
+		if err != nil {
+			return user, http.StatusInternalServerError, err
+		}
+
+		user, err = user.GetUserWithProfile(db, existing.ID)
+		if err != nil {
+			return user, http.StatusInternalServerError, fmt.Errorf("unable to fetch user " + err.Error())
+		}
+		return user, http.StatusOK, nil
+	}
+
+	user = models.User{
+		ID:         utility.GenerateUUID(),
+		Name:       ident.Username,
+		Email:      ident.Email,
+		IsVerified: ident.EmailVerified,
+		Profile: models.Profile{
+			ID:        utility.GenerateUUID(),
+			FirstName: ident.FirstName,
+			LastName:  ident.LastName,
+			AvatarURL: ident.AvatarURL,
+		},
+	}
+	identity.UserID = user.ID
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := user.CreateUser(tx); err != nil {
+			return err
+		}
+		return identity.CreateIdentity(tx)
+	})
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	welcomeReq := models.SendWelcomeMail{
+		Email: user.Email,
+	}
+
+	err = actions.AddNotificationToQueue(storage.DB.Redis, names.SendWelcomeMail, welcomeReq)
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	return user, http.StatusCreated, nil
+}
+
+// Reauthenticate confirms the caller still controls the account before its
+// logins are changed or it is deleted. Accounts without a password must have signed in within
+// reauthWindow instead. Password checks count towards the same lockout as
+// sign in.
+func Reauthenticate(db *gorm.DB, user models.User, accessUuid, password, ip string) (int, error) {
+	if user.Password != "" {
+		guard := newAttemptGuard(db, guardLogin, ip, user.Email)
+		if err := guard.Check(); err != nil {
+			return http.StatusTooManyRequests, err
+		}
+		if !utility.CompareHash(password, user.Password) {
+			guard.Fail(ip)
+			return http.StatusForbidden, errors.New("password is incorrect")
+		}
+		guard.Succeed()
+		return http.StatusOK, nil
+	}
+
+	token := models.AccessToken{ID: accessUuid}
+	code, err := token.GetByID(db)
+	if err != nil {
+		return code, err
+	}
+
+	startedAt, err := token.SessionStartedAt(db)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if time.Since(startedAt) > reauthWindow {
+		return http.StatusForbidden, errors.New("sign in again to continue")
+	}
+	return http.StatusOK, nil
+}
+
+// linkIdentity attaches a provider login to userId. A login can belong to one
+// account only, and an account has at most one login per provider.
+func linkIdentity(db *gorm.DB, userId string, ident externalIdentity) (models.UserIdentity, int, error) {
+	var identity models.UserIdentity
+
+	if ident.Subject == "" {
+		return identity, http.StatusBadRequest, errors.New("the provider did not identify the account")
+	}
+
+	_, err := identity.GetByProviderSubject(db, ident.Provider, ident.Subject)
+	if err == nil {
+		if identity.UserID != userId {
+			return identity, http.StatusConflict, errors.New("this login is already linked to another account")
+		}
+		return identity, http.StatusOK, nil
+	}
+
+	if models.HasIdentityForProvider(db, userId, ident.Provider) {
+		return identity, http.StatusConflict, fmt.Errorf("a %v login is already linked to your account", ident.Provider)
+	}
+
+	identity = models.UserIdentity{
+		ID:       utility.GenerateUUID(),
+		UserID:   userId,
+		Provider: ident.Provider,
+		Subject:  ident.Subject,
+		Email:    ident.Email,
+	}
+
+	err = identity.CreateIdentity(db)
+	if err != nil {
+		return identity, http.StatusInternalServerError, err
+	}
+	return identity, http.StatusCreated, nil
+}
+
+func GetIdentities(db *gorm.DB, userId string) ([]models.UserIdentity, int, error) {
+	identities, err := models.GetUserIdentities(db, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return identities, http.StatusOK, nil
+}
+
+// LinkIdentity starts linking a provider login to the signed in user. Google
+// ID tokens are linked straight away; redirect based providers get a URL to
+// send the user to, whose callback finishes the link.
+func LinkIdentity(db *gorm.DB, req models.LinkIdentityRequest, provider, userId, accessUuid, ip string) (gin.H, int, error) {
+	var user models.User
+
+	user, err := user.GetUserByID(db, userId)
+	if err != nil {
+		return nil, http.StatusNotFound, fmt.Errorf("unable to fetch user " + err.Error())
+	}
+
+	code, err := Reauthenticate(db, user, accessUuid, req.Password, ip)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if provider == "google" {
+		if req.IDToken == "" {
+			return nil, http.StatusBadRequest, errors.New("id_token is required to link google")
+		}
+
+		ident, err := googleIdentity(req.IDToken)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+
+		identity, code, err := linkIdentity(db, userId, ident)
+		if err != nil {
+			return nil, code, err
+		}
+		return gin.H{"identity": identity}, code, nil
+	}
+
+	if _, err := goth.GetProvider(provider); err != nil {
+		return nil, http.StatusNotFound, errors.New("unsupported login provider")
+	}
+
+	token, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	err = rdb.RedisSetWithExpiry(storage.DB.Redis, identityLinkKey(token), userId, identityLinkLifetime)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	authorizeURL := fmt.Sprintf("%v/api/v1/auth/oauth/%v?link_token=%v", strings.TrimRight(config.GetConfig().App.Url, "/"), provider, token)
+	return gin.H{"authorize_url": authorizeURL}, http.StatusOK, nil
+}
+
+// GetOAuthLinkUser reports which user, if any, started the provider round
+// trip identified by state in order to link it.
+func GetOAuthLinkUser(state string) (string, bool) {
+	var userId string
+
+	if state == "" {
+		return "", false
+	}
+
+	stored, err := rdb.RedisGet(storage.DB.Redis, identityLinkKey(state))
+	if err != nil || json.Unmarshal(stored, &userId) != nil {
+		return "", false
+	}
+	return userId, true
+}
+
+// LinkOAuthIdentity finishes a link started by LinkIdentity. The link token
+// is single use.
+func LinkOAuthIdentity(gothUser goth.User, db *gorm.DB, userId, state string) (models.UserIdentity, int, error) {
+	deleted, err := rdb.RedisDelete(storage.DB.Redis, identityLinkKey(state))
+	if err != nil {
+		return models.UserIdentity{}, http.StatusInternalServerError, err
+	}
+	if deleted == 0 {
+		return models.UserIdentity{}, http.StatusUnauthorized, errors.New("invalid or expired link token")
+	}
+
+	return linkIdentity(db, userId, gothIdentity(gothUser))
+}
+
+func UnlinkIdentity(db *gorm.DB, req models.ReauthRequest, identityId, userId, accessUuid, ip string) (int, error) {
+	var user models.User
+
+	user, err := user.GetUserByID(db, userId)
+	if err != nil {
+		return http.StatusNotFound, fmt.Errorf("unable to fetch user " + err.Error())
+	}
+
+	code, err := Reauthenticate(db, user, accessUuid, req.Password, ip)
+	if err != nil {
+		return code, err
+	}
+
+	return models.DeleteUserIdentity(db, userId, identityId, user.Password != "")
+}
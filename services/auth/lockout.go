@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// Endpoints that accept guesses. IP counters are kept per endpoint so a
+// burst of bad reset codes does not slow down logins from the same office.
+const (
+	guardLogin         = "login"
+	guardMagicLink     = "magic_link"
+	guardPasswordReset = "password_reset"
+	guardEmailVerify   = "email_verify"
+	guardPhoneCode     = "phone_code"
+	guardTwoFactor     = "two_factor"
+)
+
+// accountGuards are the endpoints that also count failures per account.
+var accountGuards = []string{guardLogin, guardTwoFactor}
+
+// lockoutPolicy describes how one kind of counter reacts to failures. The
+// first FreeAttempts failures in Window cost nothing; after that each one
+// doubles the wait before the next try, up to MaxDelay. LockAfter failures
+// lock the key out for LockFor.
+type lockoutPolicy struct {
+	Window       time.Duration
+	FreeAttempts int64
+	MaxDelay     time.Duration
+	LockAfter    int64
+	LockFor      time.Duration
+}
+
+var (
+	accountPolicy = lockoutPolicy{
+		Window:       15 * time.Minute,
+		FreeAttempts: 3,
+		MaxDelay:     30 * time.Second,
+		LockAfter:    10,
+		LockFor:      15 * time.Minute,
+	}
+	// many users can share an address, so IPs get far more room
+	ipPolicy = lockoutPolicy{
+		Window:       15 * time.Minute,
+		FreeAttempts: 20,
+		MaxDelay:     time.Minute,
+		LockAfter:    100,
+		LockFor:      30 * time.Minute,
+	}
+)
+
+// LockoutError is returned while a client has to wait before trying again.
+type LockoutError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LockoutError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, try again in %d seconds", e.RetryAfterSeconds())
+	}
+	return fmt.Sprintf("please wait %d seconds before trying again", e.RetryAfterSeconds())
+}
+
+func (e *LockoutError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// attemptGuard tracks failures for one request against Redis, so limits hold
+// across instances. Like slow mode it fails open: if Redis is unreachable
+// attempts are let through rather than locking everyone out.
+type attemptGuard struct {
+	rdb     *redis.Client
+	db      *gorm.DB
+	keys    map[string]lockoutPolicy
+	email   string
+	account string
+}
+
+// newAttemptGuard guards endpoint for the client at ip. email is the account
+// being tried, when the endpoint knows it up front.
+func newAttemptGuard(db *gorm.DB, endpoint, ip, email string) *attemptGuard {
+	g := &attemptGuard{
+		rdb:  storage.DB.Redis,
+		db:   db,
+		keys: map[string]lockoutPolicy{},
+	}
+
+	if ip != "" {
+		g.keys[fmt.Sprintf("lockout:ip:%v:%v", endpoint, ip)] = ipPolicy
+	}
+	if email != "" {
+		g.email = strings.ToLower(email)
+		g.account = accountLockoutKey(endpoint, g.email)
+		g.keys[g.account] = accountPolicy
+	}
+	return g
+}
+
+// accountLockoutKey is keyed by a hash of the address, so unknown emails are
+// throttled the same as real ones and Redis holds no plain addresses. Each
+// endpoint counts on its own, so a correct password does not wipe out failed
+// two-factor codes.
+func accountLockoutKey(endpoint, email string) string {
+	return fmt.Sprintf("lockout:account:%v:%v", endpoint, utility.HashToken(strings.ToLower(email)))
+}
+
+// Check returns a *LockoutError if any key is locked or still waiting out a
+// delay.
+func (g *attemptGuard) Check() error {
+	if g.rdb == nil {
+		return nil
+	}
+
+	for key := range g.keys {
+		if ttl, err := g.rdb.PTTL(rdb.Ctx, key+":lock").Result(); err == nil && ttl > 0 {
+			return &LockoutError{RetryAfter: ttl, Locked: true}
+		}
+		if ttl, err := g.rdb.PTTL(rdb.Ctx, key+":delay").Result(); err == nil && ttl > 0 {
+			return &LockoutError{RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// Fail records a failed attempt against every key.
+func (g *attemptGuard) Fail(ip string) {
+	if g.rdb == nil {
+		return
+	}
+
+	for key, policy := range g.keys {
+		count, _, err := rdb.IncrWindow(g.rdb, key+":failures", policy.Window)
+		if err != nil {
+			continue
+		}
+
+		if count >= policy.LockAfter {
+			locked, err := g.rdb.SetNX(rdb.Ctx, key+":lock", 1, policy.LockFor).Result()
+			if err == nil && locked {
+				g.rdb.Del(rdb.Ctx, key+":failures", key+":delay")
+				if key == g.account {
+					g.notifyLocked(policy.LockFor, ip)
+				}
+			}
+			continue
+		}
+
+		if count > policy.FreeAttempts {
+			delay := time.Second << uint(count-policy.FreeAttempts-1)
+			if delay > policy.MaxDelay || delay <= 0 {
+				delay = policy.MaxDelay
+			}
+			g.rdb.Set(rdb.Ctx, key+":delay", 1, delay)
+		}
+	}
+}
+
+// Succeed clears the account's failures. IP counters are left alone, so an
+// attacker cannot reset them by logging into an account of their own.
+func (g *attemptGuard) Succeed() {
+	if g.rdb == nil || g.account == "" {
+		return
+	}
+	g.rdb.Del(rdb.Ctx, g.account+":failures", g.account+":delay")
+}
+
+func (g *attemptGuard) notifyLocked(lockFor time.Duration, ip string) {
+	var user models.User
+
+	user, err := user.GetUserByEmail(g.db, g.email)
+	if err != nil {
+		return
+	}
+
+	lockedReq := models.SendAccountLocked{
+		Email:       user.Email,
+		IPAddress:   ip,
+		LockedUntil: time.Now().Add(lockFor).Format(time.RFC1123),
+	}
+
+	_ = actions.AddNotificationToQueue(g.rdb, names.SendAccountLocked, lockedReq)
+}
+
+// UnlockAccount lifts a lockout and clears the failures for userId.
+func UnlockAccount(db *gorm.DB, redisClient *redis.Client, userId string) (int, error) {
+	var user models.User
+
+	user, err := user.GetUserByID(db, userId)
+	if err != nil {
+		return http.StatusNotFound, errors.New("user not found")
+	}
+
+	var keys []string
+	for _, endpoint := range accountGuards {
+		key := accountLockoutKey(endpoint, user.Email)
+		keys = append(keys, key+":failures", key+":delay", key+":lock")
+	}
+
+	err = redisClient.Del(rdb.Ctx, keys...).Err()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
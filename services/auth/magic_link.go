@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -13,7 +12,6 @@ import (
 
 	"github.com/hngprojects/telex_be/internal/config"
 	"github.com/hngprojects/telex_be/internal/models"
-	"github.com/hngprojects/telex_be/pkg/middleware"
 	"github.com/hngprojects/telex_be/pkg/repository/storage"
 	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
 	"github.com/hngprojects/telex_be/services/actions"
@@ -45,7 +43,9 @@ func MagicLinkRequest(userEmail, url string, db *gorm.DB) (string, int, error) {
 		return "error", http.StatusNotFound, fmt.Errorf("user not found")
 	}
 
-	resetToken, err := utility.GenerateOTP(6)
+	// the token only travels in a link, so it can be long enough that
+	// guessing is hopeless
+	resetToken, err := utility.GenerateSecureToken(32)
 
 	if err != nil {
 		return "error", http.StatusInternalServerError, err
@@ -54,7 +54,7 @@ func MagicLinkRequest(userEmail, url string, db *gorm.DB) (string, int, error) {
 	magic := models.MagicLink{
 		ID:        utility.GenerateUUID(),
 		Email:     strings.ToLower(userEmail),
-		Token:     strconv.Itoa(resetToken),
+		Token:     resetToken,
 		ExpiresAt: time.Now().Add(time.Duration(config.App.ResetPasswordDuration) * time.Minute),
 	}
 
@@ -78,7 +78,7 @@ func MagicLinkRequest(userEmail, url string, db *gorm.DB) (string, int, error) {
 	return "success", http.StatusOK, nil
 }
 
-func VerifyMagicLinkToken(req models.VerifyMagicLinkRequest, db *gorm.DB) (gin.H, int, error) {
+func VerifyMagicLinkToken(req models.VerifyMagicLinkRequest, db *gorm.DB, client models.ClientInfo, logger *utility.Logger) (gin.H, int, error) {
 
 	var (
 		user         = models.User{}
@@ -86,8 +86,14 @@ func VerifyMagicLinkToken(req models.VerifyMagicLinkRequest, db *gorm.DB) (gin.H
 		magicLink    = models.MagicLink{}
 	)
 
+	guard := newAttemptGuard(db, guardMagicLink, client.IPAddress, "")
+	if err := guard.Check(); err != nil {
+		return responseData, http.StatusTooManyRequests, err
+	}
+
 	magicExist, err := magicLink.GetMagicLinkByToken(db, req.Token)
 	if err != nil {
+		guard.Fail(client.IPAddress)
 		return responseData, http.StatusUnauthorized, errors.New("invalid or expired token")
 	}
 
@@ -96,50 +102,21 @@ func VerifyMagicLinkToken(req models.VerifyMagicLinkRequest, db *gorm.DB) (gin.H
 		return responseData, http.StatusBadRequest, errors.New("invalid credentials")
 	}
 
-	userData, err := user.GetUserByEmail(db, magicExist.Email)
-	if err != nil {
-		return responseData, http.StatusInternalServerError, errors.New("unable to fetch user")
-	}
-
-	tokenData, err := middleware.CreateToken(user)
-	if err != nil {
-		return responseData, http.StatusInternalServerError, errors.New("error saving token")
-	}
-
-	tokens := map[string]string{
-		"access_token": tokenData.AccessToken,
-		"exp":          strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
+	if user.IsSuspended() {
+		return responseData, http.StatusForbidden, errAccountSuspended
 	}
 
-	access_token := models.AccessToken{ID: tokenData.AccessUuid, OwnerID: user.ID}
-
-	err = access_token.CreateAccessToken(db, tokens)
-
-	if err != nil {
-		return responseData, http.StatusInternalServerError, errors.New("error saving token")
+	// the link stands in for the password only; 2FA still applies
+	if models.IsTwoFactorEnabled(db, user.ID) {
+		if err := magicExist.DeleteMagicLink(db); err != nil {
+			return responseData, http.StatusInternalServerError, err
+		}
+		return createTwoFactorChallenge(user.ID)
 	}
 
 	if err := magicExist.DeleteMagicLink(db); err != nil {
 		return responseData, http.StatusInternalServerError, err
 	}
 
-	responseData = gin.H{
-
-		"user": map[string]string{
-			"id":         userData.ID,
-			"email":      userData.Email,
-			"username":   userData.Name,
-			"first_name": userData.Profile.FirstName,
-			"last_name":  userData.Profile.LastName,
-			"fullname":   userData.Profile.FirstName + " " + userData.Profile.LastName,
-			"phone":      userData.Profile.Phone,
-			"avatar_url": userData.Profile.AvatarURL,
-			"expires_in": strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
-			"created_at": strconv.Itoa(int(userData.CreatedAt.Unix())),
-			"updated_at": strconv.Itoa(int(userData.UpdatedAt.Unix())),
-		},
-		"access_token": tokenData.AccessToken,
-	}
-
-	return responseData, http.StatusOK, nil
+	return completeLogin(db, user, client, logger)
 }
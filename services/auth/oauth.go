@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/github"
+	"github.com/markbates/goth/providers/gitlab"
+	"github.com/markbates/goth/providers/microsoftonline"
+	"github.com/markbates/goth/providers/openidConnect"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// oauthStateLifetime is how long a user has to finish signing in with the
+// provider, in seconds.
+const oauthStateLifetime = 10 * 60
+
+// UseOAuthProviders registers every provider with credentials in the config
+// and keeps gothic's state in a cookie signed with the server secret.
+func UseOAuthProviders(logger *utility.Logger) {
+	var (
+		cfg       = config.GetConfig()
+		oauth     = cfg.OAuth
+		providers []goth.Provider
+	)
+
+	callback := func(name string) string {
+		return fmt.Sprintf("%v/api/v1/auth/oauth/%v/callback", strings.TrimRight(cfg.App.Url, "/"), name)
+	}
+
+	if oauth.GitHubKey != "" {
+		providers = append(providers, github.New(oauth.GitHubKey, oauth.GitHubSecret, callback("github"), "read:user", "user:email"))
+	}
+	if oauth.MicrosoftKey != "" {
+		providers = append(providers, microsoftonline.New(oauth.MicrosoftKey, oauth.MicrosoftSecret, callback("microsoftonline")))
+	}
+	if oauth.GitLabKey != "" {
+		providers = append(providers, gitlab.New(oauth.GitLabKey, oauth.GitLabSecret, callback("gitlab"), "read_user"))
+	}
+	if oauth.OIDCKey != "" {
+		// goth names custom issuers "<name>-oidc"
+		name := "openid-connect"
+		if oauth.OIDCName != "" {
+			name = strings.ToLower(oauth.OIDCName) + "-oidc"
+		}
+
+		provider, err := openidConnect.NewNamed(oauth.OIDCName, oauth.OIDCKey, oauth.OIDCSecret, callback(name), oauth.OIDCDiscovery, "email", "profile")
+		if err != nil {
+			utility.LogAndPrint(logger, fmt.Sprintf("openid connect provider disabled: %v", err))
+		} else {
+			providers = append(providers, provider)
+		}
+	}
+
+	goth.UseProviders(providers...)
+
+	store := sessions.NewCookieStore([]byte(cfg.Server.Secret))
+	store.Options = &sessions.Options{
+		Path:     "/",
+		MaxAge:   oauthStateLifetime,
+		HttpOnly: true,
+		Secure:   cfg.App.Mode == "release",
+		SameSite: http.SameSiteLaxMode,
+	}
+	gothic.Store = store
+}
+
+// oauthEmailVerified reports whether the provider vouched for the address.
+// OIDC issuers say so in the email_verified claim; GitHub only hands out
+// verified addresses.
+func oauthEmailVerified(gothUser goth.User) bool {
+	switch verified := gothUser.RawData["email_verified"].(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	}
+	return gothUser.Provider == "github"
+}
+
+func oauthUsername(gothUser goth.User) string {
+	for _, name := range []string{gothUser.NickName, gothUser.Name} {
+		if name != "" {
+			return strings.ToLower(name)
+		}
+	}
+	return strings.ToLower(strings.Split(gothUser.Email, "@")[0])
+}
+
+// OAuthLogin signs in the user behind a completed provider login, creating
+// an account the first time the login is seen.
+func OAuthLogin(gothUser goth.User, db *gorm.DB, client models.ClientInfo, logger *utility.Logger) (gin.H, int, error) {
+	user, code, err := findOrCreateIdentityUser(db, gothIdentity(gothUser))
+	if err != nil {
+		return nil, code, err
+	}
+
+	if models.IsTwoFactorEnabled(db, user.ID) {
+		return createTwoFactorChallenge(user.ID)
+	}
+
+	return completeLogin(db, user, client, logger)
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -41,6 +40,10 @@ func UpdateUserPassword(c *gin.Context, req models.ChangePasswordRequestModel, d
 		return nil, http.StatusNotFound, fmt.Errorf("unable to fetch user " + err.Error())
 	}
 
+	if userDataExist.MustResetPassword {
+		return nil, http.StatusForbidden, errPasswordResetRequired
+	}
+
 	if !utility.CompareHash(req.OldPassword, userDataExist.Password) && userDataExist.Password != "" {
 		return nil, http.StatusBadRequest, fmt.Errorf("old password is incorrect")
 	}
@@ -60,6 +63,14 @@ func UpdateUserPassword(c *gin.Context, req models.ChangePasswordRequestModel, d
 		return nil, http.StatusBadRequest, err
 	}
 
+	var accessToken models.AccessToken
+	if _, err := accessToken.RevokeAllSessions(db, userDataExist.ID); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	middleware.InvalidateSessionCache(userDataExist.ID)
+
+	recordSecurityEvent(db, userDataExist.ID, models.SecurityEventPasswordChanged, models.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+
 	return &userDataExist, http.StatusOK, nil
 }
 
@@ -87,7 +98,9 @@ func PasswordReset(userEmail string, db *gorm.DB, extReq request.ExternalRequest
 		return "error", http.StatusNotFound, fmt.Errorf("user not found")
 	}
 
-	resetToken, err := utility.GenerateOTP(6)
+	// the code is the only thing the request carries, so it has to be too
+	// long to guess rather than a short OTP
+	resetToken, err := utility.GenerateSecureToken(32)
 
 	if err != nil {
 		return "error", http.StatusInternalServerError, err
@@ -96,7 +109,7 @@ func PasswordReset(userEmail string, db *gorm.DB, extReq request.ExternalRequest
 	reset := models.PasswordReset{
 		ID:        utility.GenerateUUID(),
 		Email:     strings.ToLower(userEmail),
-		Token:     strconv.Itoa(resetToken),
+		Token:     resetToken,
 		ExpiresAt: time.Now().Add(time.Duration(config.App.ResetPasswordDuration) * time.Minute),
 	}
 
@@ -118,15 +131,24 @@ func PasswordReset(userEmail string, db *gorm.DB, extReq request.ExternalRequest
 	return "success", http.StatusOK, nil
 }
 
-func VerifyPasswordResetToken(req models.ResetPasswordRequestModel, db *gorm.DB) (*models.User, int, error) {
+// VerifyPasswordResetToken sets a new password for the owner of a reset
+// code. The code alone identifies the account; it is a long random token and
+// failed guesses are still limited per IP.
+func VerifyPasswordResetToken(req models.ResetPasswordRequestModel, db *gorm.DB, client models.ClientInfo) (*models.User, int, error) {
 
 	var (
 		user      = models.User{}
 		passReset = models.PasswordReset{}
 	)
 
+	guard := newAttemptGuard(db, guardPasswordReset, client.IPAddress, "")
+	if err := guard.Check(); err != nil {
+		return nil, http.StatusTooManyRequests, err
+	}
+
 	resetExist, err := passReset.GetPasswordResetByToken(db, req.Token)
 	if err != nil {
+		guard.Fail(client.IPAddress)
 		return nil, http.StatusUnauthorized, errors.New("invalid or expired token")
 	}
 
@@ -141,6 +163,7 @@ func VerifyPasswordResetToken(req models.ResetPasswordRequestModel, db *gorm.DB)
 	}
 
 	userDataExist.Password = hashedPassword
+	userDataExist.MustResetPassword = false
 	err = userDataExist.Update(db)
 	if err != nil {
 		return nil, http.StatusInternalServerError, err
@@ -150,6 +173,14 @@ func VerifyPasswordResetToken(req models.ResetPasswordRequestModel, db *gorm.DB)
 		return nil, http.StatusInternalServerError, err
 	}
 
+	var accessToken models.AccessToken
+	if _, err := accessToken.RevokeAllSessions(db, userDataExist.ID); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	middleware.InvalidateSessionCache(userDataExist.ID)
+
+	recordSecurityEvent(db, userDataExist.ID, models.SecurityEventPasswordReset, client)
+
 	return &userDataExist, http.StatusOK, nil
 
 }
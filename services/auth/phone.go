@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/services/send"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// What a phone code is for. Codes for one purpose are never accepted for the
+// other.
+const (
+	phoneCodeLogin  = "login"
+	phoneCodeVerify = "verify"
+)
+
+const (
+	phoneCodeLifetime = 5 * time.Minute
+	// phoneCodeMaxGuesses wrong codes burn the code; a new one has to be
+	// requested.
+	phoneCodeMaxGuesses = 5
+	// phoneCodeCooldown is the least time between two texts to a number.
+	phoneCodeCooldown = time.Minute
+	phoneSendWindow   = time.Hour
+	// texts cost money, so a number and an address each get a small
+	// allowance per window
+	phoneSendLimitPerNumber = 5
+	phoneSendLimitPerIP     = 20
+)
+
+var errInvalidPhoneCode = errors.New("invalid or expired code")
+
+// phoneCode is a code waiting to be entered. Only its hash is kept.
+type phoneCode struct {
+	CodeHash string `json:"code_hash"`
+	UserID   string `json:"user_id"`
+}
+
+// phoneCodeKey is keyed by a hash of the number so Redis holds no plain
+// numbers.
+func phoneCodeKey(purpose, phone string) string {
+	return fmt.Sprintf("phone_code:%v:%v", purpose, utility.HashToken(phone))
+}
+
+func normalisePhone(phone string) (string, string, error) {
+	phoneE164, ok := utility.PhoneE164(phone)
+	if !ok {
+		return "", "", errors.New("phone number is invalid, include the country code")
+	}
+	national, _ := utility.PhoneValid(phone)
+	return phoneE164, national, nil
+}
+
+// checkPhoneSendLimits applies the cooldown and the per number and per IP
+// allowances before a text is sent.
+func checkPhoneSendLimits(phone, ip string) error {
+	redisClient := storage.DB.Redis
+	numberKey := "phone_send:number:" + utility.HashToken(phone)
+
+	started, err := redisClient.SetNX(rdb.Ctx, numberKey+":cooldown", 1, phoneCodeCooldown).Result()
+	if err != nil {
+		return err
+	}
+	if !started {
+		ttl, _ := redisClient.PTTL(rdb.Ctx, numberKey+":cooldown").Result()
+		return &LockoutError{RetryAfter: ttl}
+	}
+
+	limits := map[string]int64{
+		numberKey:             phoneSendLimitPerNumber,
+		"phone_send:ip:" + ip: phoneSendLimitPerIP,
+	}
+	for key, limit := range limits {
+		count, ttl, err := rdb.IncrWindow(redisClient, key, phoneSendWindow)
+		if err != nil {
+			return err
+		}
+		if count > limit {
+			return &LockoutError{RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// sendPhoneCode texts a new code for purpose to phone, replacing any code
+// sent before.
+func sendPhoneCode(purpose, phone, userId string) error {
+	otp, err := utility.GenerateOTP(6)
+	if err != nil {
+		return err
+	}
+	code := fmt.Sprintf("%06d", otp)
+
+	key := phoneCodeKey(purpose, phone)
+	err = rdb.RedisSetWithExpiry(storage.DB.Redis, key, phoneCode{CodeHash: utility.HashToken(code), UserID: userId}, phoneCodeLifetime)
+	if err != nil {
+		return err
+	}
+	_, _ = rdb.RedisDelete(storage.DB.Redis, key+":guesses")
+
+	body := fmt.Sprintf("Your Telex code is %v. It expires in %d minutes. Don't share it with anyone.", code, int(phoneCodeLifetime.Minutes()))
+	return send.SendSMS(phone, body)
+}
+
+// checkPhoneCode returns the user a code was sent for. A code works once.
+func checkPhoneCode(purpose, phone, code string) (string, error) {
+	var pending phoneCode
+
+	key := phoneCodeKey(purpose, phone)
+	data, err := rdb.RedisGet(storage.DB.Redis, key)
+	if err != nil || json.Unmarshal(data, &pending) != nil {
+		return "", errInvalidPhoneCode
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utility.HashToken(code)), []byte(pending.CodeHash)) != 1 {
+		guesses, _, err := rdb.IncrWindow(storage.DB.Redis, key+":guesses", phoneCodeLifetime)
+		if err != nil || guesses >= phoneCodeMaxGuesses {
+			_, _ = rdb.RedisDelete(storage.DB.Redis, key)
+		}
+		return "", errInvalidPhoneCode
+	}
+
+	// deleting decides between two requests racing with the same code
+	if deleted, err := rdb.RedisDelete(storage.DB.Redis, key); err != nil || deleted == 0 {
+		return "", errInvalidPhoneCode
+	}
+	_, _ = rdb.RedisDelete(storage.DB.Redis, key+":guesses")
+	return pending.UserID, nil
+}
+
+func phoneSendError(err error) (int, error) {
+	var lockoutErr *LockoutError
+	if errors.As(err, &lockoutErr) {
+		return http.StatusTooManyRequests, err
+	}
+	return http.StatusInternalServerError, err
+}
+
+// RequestPhoneVerification texts a code to the number the user wants to
+// verify.
+func RequestPhoneVerification(db *gorm.DB, userId string, req models.PhoneCodeRequest, client models.ClientInfo) (int, error) {
+	phone, _, err := normalisePhone(req.PhoneNumber)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if models.PhoneVerifiedByOther(db, phone, userId) {
+		return http.StatusConflict, errors.New("phone number is already in use")
+	}
+
+	if err := checkPhoneSendLimits(phone, client.IPAddress); err != nil {
+		return phoneSendError(err)
+	}
+
+	if err := sendPhoneCode(phoneCodeVerify, phone, userId); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// ConfirmPhoneVerification makes the number the user's phone once they enter
+// the code sent to it.
+func ConfirmPhoneVerification(db *gorm.DB, userId string, req models.PhoneCodeVerifyRequest, client models.ClientInfo) (models.Profile, int, error) {
+	var user models.User
+
+	phone, national, err := normalisePhone(req.PhoneNumber)
+	if err != nil {
+		return models.Profile{}, http.StatusBadRequest, err
+	}
+
+	guard := newAttemptGuard(db, guardPhoneCode, client.IPAddress, "")
+	if err := guard.Check(); err != nil {
+		return models.Profile{}, http.StatusTooManyRequests, err
+	}
+
+	codeUserId, err := checkPhoneCode(phoneCodeVerify, phone, req.Code)
+	if err != nil || codeUserId != userId {
+		guard.Fail(client.IPAddress)
+		return models.Profile{}, http.StatusBadRequest, errInvalidPhoneCode
+	}
+
+	if models.PhoneVerifiedByOther(db, phone, userId) {
+		return models.Profile{}, http.StatusConflict, errors.New("phone number is already in use")
+	}
+
+	user, err = user.GetUserByID(db, userId)
+	if err != nil {
+		return models.Profile{}, http.StatusNotFound, errors.New("user not found")
+	}
+
+	profile := user.Profile
+	if profile.ID == "" {
+		profile = models.Profile{ID: utility.GenerateUUID(), Userid: userId}
+		if err := postgresql.CreateOneRecord(db, &profile); err != nil {
+			return profile, http.StatusInternalServerError, err
+		}
+	}
+
+	if err := profile.SetVerifiedPhone(db, userId, national, phone); err != nil {
+		// another account may have verified the number since the check above
+		if models.PhoneVerifiedByOther(db, phone, userId) {
+			return profile, http.StatusConflict, errors.New("phone number is already in use")
+		}
+		return profile, http.StatusInternalServerError, err
+	}
+	return profile, http.StatusOK, nil
+}
+
+// RequestPhoneLogin texts a sign-in code if phone is verified on an account.
+// The response is the same either way, so numbers cannot be probed; that
+// includes the text failing to send, which is only logged.
+func RequestPhoneLogin(db *gorm.DB, req models.PhoneCodeRequest, client models.ClientInfo, logger *utility.Logger) (int, error) {
+	phone, _, err := normalisePhone(req.PhoneNumber)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if err := checkPhoneSendLimits(phone, client.IPAddress); err != nil {
+		return phoneSendError(err)
+	}
+
+	profile, err := models.GetProfileByVerifiedPhone(db, phone)
+	if err != nil {
+		return http.StatusOK, nil
+	}
+
+	if err := sendPhoneCode(phoneCodeLogin, phone, profile.Userid); err != nil {
+		logger.Error("error sending sign-in code: ", profile.Userid, err.Error())
+	}
+	return http.StatusOK, nil
+}
+
+// VerifyPhoneLogin signs in with a code from RequestPhoneLogin. Like a magic
+// link, the code only stands in for the password; 2FA still applies.
+func VerifyPhoneLogin(db *gorm.DB, req models.PhoneCodeVerifyRequest, client models.ClientInfo, logger *utility.Logger) (gin.H, int, error) {
+	var user models.User
+
+	phone, _, err := normalisePhone(req.PhoneNumber)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	guard := newAttemptGuard(db, guardPhoneCode, client.IPAddress, "")
+	if err := guard.Check(); err != nil {
+		return nil, http.StatusTooManyRequests, err
+	}
+
+	userId, err := checkPhoneCode(phoneCodeLogin, phone, req.Code)
+	if err != nil {
+		guard.Fail(client.IPAddress)
+		return nil, http.StatusUnauthorized, errInvalidPhoneCode
+	}
+
+	// the number may have been changed since the code was sent
+	profile, err := models.GetProfileByVerifiedPhone(db, phone)
+	if err != nil || profile.Userid != userId {
+		return nil, http.StatusUnauthorized, errInvalidPhoneCode
+	}
+
+	user, err = user.GetUserByID(db, userId)
+	if err != nil {
+		return nil, http.StatusUnauthorized, errInvalidPhoneCode
+	}
+
+	if user.IsSuspended() {
+		return nil, http.StatusForbidden, errAccountSuspended
+	}
+
+	if models.IsTwoFactorEnabled(db, user.ID) {
+		return createTwoFactorChallenge(user.ID)
+	}
+
+	return completeLogin(db, user, client, logger)
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+)
+
+var errInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// RefreshToken exchanges a refresh token for a new access and refresh token
+// pair. Each refresh token works once: presenting one that was already
+// rotated means it leaked, so the whole family is revoked.
+func RefreshToken(req models.RefreshTokenRequest, db *gorm.DB, client models.ClientInfo) (gin.H, int, error) {
+	var (
+		current      models.AccessToken
+		user         models.User
+		responseData gin.H
+	)
+
+	code, err := current.GetByRefreshToken(db, req.RefreshToken)
+	if err != nil {
+		if code == http.StatusUnauthorized {
+			return responseData, code, errInvalidRefreshToken
+		}
+		return responseData, code, err
+	}
+
+	if current.RotatedAt != nil {
+		if err := current.RevokeFamily(db); err != nil {
+			return responseData, http.StatusInternalServerError, err
+		}
+		middleware.InvalidateSessionCache(current.OwnerID)
+		return responseData, http.StatusUnauthorized, errors.New("refresh token reuse detected, please log in again")
+	}
+
+	if !current.IsLive || current.RefreshTokenExpiresAt == nil || time.Now().After(*current.RefreshTokenExpiresAt) {
+		return responseData, http.StatusUnauthorized, errInvalidRefreshToken
+	}
+
+	user, err = user.GetUserByID(db, current.OwnerID)
+	if err != nil {
+		return responseData, http.StatusUnauthorized, errInvalidRefreshToken
+	}
+
+	tokenData, err := middleware.CreateToken(user)
+	if err != nil {
+		return responseData, http.StatusInternalServerError, errors.New("error saving token: " + err.Error())
+	}
+
+	tokens := map[string]string{
+		"access_token":  tokenData.AccessToken,
+		"exp":           strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
+		"refresh_token": tokenData.RefreshToken,
+		"refresh_exp":   strconv.Itoa(int(tokenData.RefreshExpiresAt.Unix())),
+	}
+
+	access_token := models.AccessToken{
+		ID:        tokenData.AccessUuid,
+		OwnerID:   user.ID,
+		FamilyID:  current.Family(),
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+
+	reused := false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		rotated, err := current.Rotate(tx)
+		if err != nil {
+			return err
+		}
+		if !rotated {
+			// a concurrent refresh won the race with the same token
+			reused = true
+			return nil
+		}
+		return access_token.CreateAccessToken(tx, tokens)
+	})
+	if err != nil {
+		return responseData, http.StatusInternalServerError, errors.New("error saving token: " + err.Error())
+	}
+
+	if reused {
+		if err := current.RevokeFamily(db); err != nil {
+			return responseData, http.StatusInternalServerError, err
+		}
+		middleware.InvalidateSessionCache(current.OwnerID)
+		return responseData, http.StatusUnauthorized, errors.New("refresh token reuse detected, please log in again")
+	}
+
+	// the rotated token is no longer live
+	middleware.InvalidateSessionCache(current.OwnerID)
+
+	responseData = gin.H{
+		"access_token":  tokenData.AccessToken,
+		"refresh_token": tokenData.RefreshToken,
+		"expires_in":    strconv.Itoa(int(tokenData.ExpiresAt.Unix())),
+	}
+
+	return responseData, http.StatusOK, nil
+}
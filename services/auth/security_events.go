@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/external/external_models"
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// locationCacheLifetime keeps ipstack lookups to one per address a day.
+const locationCacheLifetime = 24 * time.Hour
+
+func locationCacheKey(ip string) string {
+	return "ipstack:" + ip
+}
+
+// recordSecurityEvent adds to the user's security history. It is best effort:
+// a failed write must not fail the action being recorded.
+func recordSecurityEvent(db *gorm.DB, userId, eventType string, client models.ClientInfo) {
+	event := models.SecurityEvent{
+		ID:        utility.GenerateUUID(),
+		UserID:    userId,
+		Type:      eventType,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	}
+	_ = event.CreateSecurityEvent(db)
+}
+
+// recordLoginEvent is recordSecurityEvent for a new session. It keeps the
+// session and a fingerprint of the browser so unfamiliar logins can be
+// flagged once the location is resolved.
+func recordLoginEvent(db *gorm.DB, userId, sessionId string, client models.ClientInfo) {
+	event := models.SecurityEvent{
+		ID:         utility.GenerateUUID(),
+		UserID:     userId,
+		Type:       models.SecurityEventLogin,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		DeviceHash: deviceHash(client.UserAgent),
+		SessionID:  sessionId,
+	}
+	_ = event.CreateSecurityEvent(db)
+}
+
+var userAgentVersion = regexp.MustCompile(`[0-9][0-9._]*`)
+
+// deviceHash fingerprints a browser by its user agent without version
+// numbers, so routine browser updates do not look like a new device.
+func deviceHash(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+	return utility.HashToken(userAgentVersion.ReplaceAllString(strings.ToLower(userAgent), ""))
+}
+
+func GetSecurityEvents(db *gorm.DB, c *gin.Context, userId string) ([]models.SecurityEvent, postgresql.PaginationResponse, int, error) {
+	events, pagination, err := models.GetUserSecurityEvents(db, c, userId)
+	if err != nil {
+		return nil, pagination, http.StatusInternalServerError, err
+	}
+	return events, pagination, http.StatusOK, nil
+}
+
+// ResolveSecurityEventLocations fills in the location of up to limit events.
+// An address that cannot be resolved is given an empty location rather than
+// being retried forever.
+func ResolveSecurityEventLocations(extReq request.ExternalRequest, db *gorm.DB, limit int) (int, error) {
+	events, err := models.GetUnresolvedSecurityEvents(db, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		geo := lookupLocation(extReq, event.IPAddress)
+		if err := event.SetLocation(db, geo.Location, geo.Country); err != nil {
+			return 0, err
+		}
+
+		if event.Type == models.SecurityEventLogin && event.IsUnfamiliarLogin(db) {
+			// the alert is best effort, the location is resolved either way
+			_ = sendNewSignInAlert(db, event)
+		}
+	}
+	return len(events), nil
+}
+
+// geoLocation is what is cached for an address.
+type geoLocation struct {
+	Location string `json:"location"`
+	Country  string `json:"country"`
+}
+
+// ResolveLocation returns a rough "city, region, country" for ip. Lookups are
+// cached in Redis and best effort: private addresses and failures give an
+// empty location.
+func ResolveLocation(extReq request.ExternalRequest, ip string) string {
+	return lookupLocation(extReq, ip).Location
+}
+
+func lookupLocation(extReq request.ExternalRequest, ip string) geoLocation {
+	var geo geoLocation
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() {
+		return geo
+	}
+
+	if config.GetConfig().IPStack.Key == "" && !extReq.Test {
+		return geo
+	}
+
+	cached, err := rdb.RedisGet(storage.DB.Redis, locationCacheKey(ip))
+	if err == nil && json.Unmarshal(cached, &geo) == nil {
+		return geo
+	}
+
+	resp, err := extReq.SendExternalRequest(request.IpstackResolveIp, ip)
+	if err != nil {
+		return geo
+	}
+
+	resolved, ok := resp.(external_models.IPStackResolveIPResponse)
+	if !ok {
+		return geo
+	}
+
+	var parts []string
+	for _, part := range []string{resolved.City, resolved.RegionName, resolved.CountryName} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	geo.Location = strings.Join(parts, ", ")
+	geo.Country = strings.ToUpper(resolved.CountryCode)
+
+	_ = rdb.RedisSetWithExpiry(storage.DB.Redis, locationCacheKey(ip), geo, locationCacheLifetime)
+	return geo
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+)
+
+// GetSessions lists the caller's signed in sessions, newest first.
+func GetSessions(extReq request.ExternalRequest, db *gorm.DB, userId, accessUuid string) ([]models.Session, int, error) {
+	var accessToken models.AccessToken
+
+	tokens, startedAt, err := accessToken.GetLiveSessions(db, userId)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	sessions := make([]models.Session, 0, len(tokens))
+	for _, token := range tokens {
+		session := models.Session{
+			ID:         token.Family(),
+			CreatedAt:  token.CreatedAt,
+			LastUsedAt: token.CreatedAt,
+			IPAddress:  token.IPAddress,
+			UserAgent:  token.UserAgent,
+			Current:    token.ID == accessUuid,
+		}
+
+		if started, ok := startedAt[session.ID]; ok {
+			session.CreatedAt = started
+		}
+		if token.LastUsedAt != nil {
+			session.LastUsedAt = *token.LastUsedAt
+		}
+		if token.LastUsedIP != "" {
+			session.IPAddress = token.LastUsedIP
+		}
+		session.Location = ResolveLocation(extReq, session.IPAddress)
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, http.StatusOK, nil
+}
+
+func RevokeSession(db *gorm.DB, userId, sessionId string) (int, error) {
+	var accessToken models.AccessToken
+
+	code, err := accessToken.RevokeSession(db, userId, sessionId)
+	if err == nil {
+		middleware.InvalidateSessionCache(userId)
+	}
+	return code, err
+}
+
+// RevokeOtherSessions signs the caller out everywhere except the session the
+// request was made with.
+func RevokeOtherSessions(db *gorm.DB, userId, accessUuid string) (int64, int, error) {
+	accessToken := models.AccessToken{ID: accessUuid}
+
+	code, err := accessToken.GetByID(db)
+	if err != nil {
+		return 0, code, err
+	}
+
+	revoked, err := accessToken.RevokeOtherSessions(db, userId, accessToken.Family())
+	if err != nil {
+		return 0, http.StatusInternalServerError, err
+	}
+	middleware.InvalidateSessionCache(userId)
+	return revoked, http.StatusOK, nil
+}
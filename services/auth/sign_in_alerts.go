@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// notMeLinkLifetime is how long the link in a new sign-in email works.
+const notMeLinkLifetime = 7 * 24 * time.Hour
+
+func notMeKey(tokenHash string) string {
+	return "signin:notme:" + tokenHash
+}
+
+// reportedSignIn is what a "this wasn't me" link points at.
+type reportedSignIn struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"session_id"`
+}
+
+// sendNewSignInAlert emails the user about an unfamiliar login with a link
+// that signs the session out and locks the password.
+func sendNewSignInAlert(db *gorm.DB, event models.SecurityEvent) error {
+	var user models.User
+
+	user, err := user.GetUserByID(db, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	token, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	report := reportedSignIn{UserID: event.UserID, SessionID: event.SessionID}
+	if err := rdb.RedisSetWithExpiry(storage.DB.Redis, notMeKey(utility.HashToken(token)), report, notMeLinkLifetime); err != nil {
+		return err
+	}
+
+	alert := models.SendNewSignIn{
+		Email:     user.Email,
+		IPAddress: event.IPAddress,
+		Location:  event.Location,
+		UserAgent: event.UserAgent,
+		Time:      event.CreatedAt.UTC().Format("02 Jan 2006 15:04 MST"),
+		NotMeLink: fmt.Sprintf("%v/api/v1/auth/not-me?token=%v", strings.TrimRight(config.GetConfig().App.Url, "/"), token),
+	}
+	return actions.AddNotificationToQueue(storage.DB.Redis, names.SendNewSignIn, alert)
+}
+
+// CheckSignInReport tells whether a "this wasn't me" link can still be
+// used, without using it up.
+func CheckSignInReport(token string) (int, error) {
+	var report reportedSignIn
+
+	data, err := rdb.RedisGet(storage.DB.Redis, notMeKey(utility.HashToken(token)))
+	if err != nil || json.Unmarshal(data, &report) != nil {
+		return http.StatusBadRequest, errors.New("invalid or expired link")
+	}
+	return http.StatusOK, nil
+}
+
+// ReportSignIn acts on a confirmed "this wasn't me" link: it revokes the
+// reported session, requires a password reset and emails a reset code. A
+// link works once.
+func ReportSignIn(db *gorm.DB, extReq request.ExternalRequest, token string, client models.ClientInfo) (int, error) {
+	var (
+		report reportedSignIn
+		user   models.User
+	)
+
+	key := notMeKey(utility.HashToken(token))
+	data, err := rdb.RedisGet(storage.DB.Redis, key)
+	if err != nil || json.Unmarshal(data, &report) != nil {
+		return http.StatusBadRequest, errors.New("invalid or expired link")
+	}
+
+	if deleted, err := rdb.RedisDelete(storage.DB.Redis, key); err != nil || deleted == 0 {
+		return http.StatusBadRequest, errors.New("invalid or expired link")
+	}
+
+	user, err = user.GetUserByID(db, report.UserID)
+	if err != nil {
+		return http.StatusNotFound, errors.New("user not found")
+	}
+
+	// the session may already have ended, which is fine
+	if code, err := RevokeSession(db, user.ID, report.SessionID); err != nil && code != http.StatusNotFound {
+		return code, err
+	}
+
+	if err := user.RequirePasswordReset(db); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if _, code, err := PasswordReset(user.Email, db, extReq); err != nil {
+		return code, err
+	}
+
+	recordSecurityEvent(db, user.ID, models.SecurityEventSignInReported, client)
+
+	return http.StatusOK, nil
+}
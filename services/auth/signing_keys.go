@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+const (
+	defaultKeyRotationDays = 30
+	// JWKSMaxAge is how long verifiers may cache the published keys.
+	JWKSMaxAge = 15 * time.Minute
+	// keyPublishLead is how long a new key is published before it signs, so
+	// verifiers holding a cached key set see it before tokens carry its kid.
+	keyPublishLead = time.Hour
+	// keyVerifyGrace keeps a replaced key verifying a while past the longest
+	// lived token it signed, to cover clock skew.
+	keyVerifyGrace = time.Hour
+)
+
+type JWKS struct {
+	Keys []utility.JWK `json:"keys"`
+}
+
+func keyRotationPeriod() time.Duration {
+	days := config.GetConfig().Server.JWTKeyRotationDays
+	if days <= 0 {
+		days = defaultKeyRotationDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// GetJWKS returns the public halves of every key tokens may be verified
+// with, including the next key before it starts signing.
+func GetJWKS(db *gorm.DB) (JWKS, int, error) {
+	jwks := JWKS{Keys: []utility.JWK{}}
+
+	keys, err := models.GetPublishedSigningKeys(db, time.Now())
+	if err != nil {
+		return jwks, http.StatusInternalServerError, err
+	}
+
+	for _, key := range keys {
+		public, err := utility.ParsePublicSigningKey(key.PublicKey)
+		if err != nil {
+			return jwks, http.StatusInternalServerError, err
+		}
+
+		jwk, err := utility.PublicJWK(key.ID, key.Algorithm, public)
+		if err != nil {
+			return jwks, http.StatusInternalServerError, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks, http.StatusOK, nil
+}
+
+// RotateSigningKeys publishes the next signing key once the current one is
+// due for rotation, sets when the keys it replaces stop verifying and drops
+// keys that have expired, encrypting any private key stored in the clear.
+// It holds the signing key lock throughout so instances running it at the
+// same time rotate once. It reports whether a key was created.
+func RotateSigningKeys(db *gorm.DB, now time.Time) (bool, error) {
+	var rotated bool
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := models.LockSigningKeys(tx); err != nil {
+			return err
+		}
+
+		if err := models.DeleteExpiredSigningKeys(tx, now); err != nil {
+			return err
+		}
+		if err := models.SealPlaintextSigningKeys(tx, middleware.SigningKeySecret()); err != nil {
+			return err
+		}
+
+		current, err := models.GetCurrentSigningKey(tx, now)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		activeFrom := now.Add(keyPublishLead)
+		if err != nil {
+			// nothing can be verified yet, so there is no one to warn
+			activeFrom = now
+		} else if models.HasPendingSigningKey(tx, now) || now.Before(current.ActiveFrom.Add(keyRotationPeriod()-keyPublishLead)) {
+			return nil
+		}
+
+		next, err := models.NewSigningKey(middleware.SigningAlgorithm(), activeFrom, middleware.SigningKeySecret())
+		if err != nil {
+			return err
+		}
+		if err := next.CreateSigningKey(tx); err != nil {
+			return err
+		}
+
+		expiresAt := activeFrom.Add(middleware.AccessTokenLifetime() + keyVerifyGrace)
+		if err := models.ExpireSigningKeys(tx, next, expiresAt); err != nil {
+			return err
+		}
+
+		rotated = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return rotated, nil
+}
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+const (
+	twoFactorChallengeLifetime = 5 * time.Minute
+	twoFactorMaxAttempts       = 5
+	recoveryCodeCount          = 10
+)
+
+var (
+	errInvalidTwoFactorCode      = errors.New("invalid two-factor code")
+	errInvalidTwoFactorChallenge = errors.New("invalid or expired challenge token")
+)
+
+func twoFactorChallengeKey(token string) string {
+	return "2fa:challenge:" + utility.HashToken(token)
+}
+
+// createTwoFactorChallenge answers a correct password for a 2FA user with a
+// short lived token that can only be exchanged at /auth/2fa/verify.
+func createTwoFactorChallenge(userId string) (gin.H, int, error) {
+	token, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	err = rdb.RedisSetWithExpiry(storage.DB.Redis, twoFactorChallengeKey(token), userId, twoFactorChallengeLifetime)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	responseData := gin.H{
+		"two_factor_required": true,
+		"challenge_token":     token,
+		"expires_in":          strconv.Itoa(int(time.Now().Add(twoFactorChallengeLifetime).Unix())),
+	}
+	return responseData, http.StatusOK, nil
+}
+
+func generateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		token, err := utility.GenerateSecureToken(8)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, fmt.Sprintf("%v-%v-%v-%v", token[0:4], token[4:8], token[8:12], token[12:16]))
+	}
+	return codes, nil
+}
+
+// checkTwoFactorCode accepts either a current TOTP code or an unused recovery
+// code for an enabled user.
+func checkTwoFactorCode(db *gorm.DB, twoFactor models.TwoFactor, code string) (bool, error) {
+	if step, ok := utility.ValidateTOTP(twoFactor.Secret, code, time.Now()); ok {
+		return twoFactor.MarkStepUsed(db, step)
+	}
+	return models.UseRecoveryCode(db, twoFactor.UserID, code)
+}
+
+// EnrollTwoFactor starts enrollment with a fresh secret. The returned
+// otpauth URI is also the payload for the QR code apps scan.
+func EnrollTwoFactor(db *gorm.DB, userId string) (gin.H, int, error) {
+	var user models.User
+
+	if models.IsTwoFactorEnabled(db, userId) {
+		return nil, http.StatusConflict, errors.New("two-factor authentication is already enabled")
+	}
+
+	user, err := user.GetUserByID(db, userId)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+
+	secret, err := utility.GenerateTOTPSecret()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	twoFactor := models.TwoFactor{
+		ID:     utility.GenerateUUID(),
+		UserID: userId,
+		Secret: secret,
+	}
+
+	err = twoFactor.SaveSecret(db)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	uri := utility.TOTPURI(config.GetConfig().App.Name, user.Email, secret)
+
+	responseData := gin.H{
+		"secret":      secret,
+		"otpauth_uri": uri,
+	}
+	return responseData, http.StatusOK, nil
+}
+
+// ConfirmTwoFactor turns 2FA on once the user proves their app produces the
+// right codes, and hands back the recovery codes. They are only shown here.
+func ConfirmTwoFactor(db *gorm.DB, req models.TwoFactorCodeRequest, userId string, client models.ClientInfo) (gin.H, int, error) {
+	var twoFactor models.TwoFactor
+
+	code, err := twoFactor.GetByUserID(db, userId)
+	if err != nil {
+		return nil, code, err
+	}
+
+	if twoFactor.Enabled {
+		return nil, http.StatusConflict, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := utility.ValidateTOTP(twoFactor.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, http.StatusBadRequest, errInvalidTwoFactorCode
+	}
+
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	err = twoFactor.Enable(db, step, codes)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	recordSecurityEvent(db, userId, models.SecurityEventTwoFactorEnabled, client)
+
+	return gin.H{"recovery_codes": codes}, http.StatusOK, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not.
+func RegenerateRecoveryCodes(db *gorm.DB, req models.TwoFactorCodeRequest, userId string, client models.ClientInfo) (gin.H, int, error) {
+	var twoFactor models.TwoFactor
+
+	_, err := twoFactor.GetByUserID(db, userId)
+	if err != nil || !twoFactor.Enabled {
+		return nil, http.StatusBadRequest, errors.New("two-factor authentication is not enabled")
+	}
+
+	step, ok := utility.ValidateTOTP(twoFactor.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, http.StatusBadRequest, errInvalidTwoFactorCode
+	}
+	if fresh, err := twoFactor.MarkStepUsed(db, step); err != nil || !fresh {
+		return nil, http.StatusBadRequest, errInvalidTwoFactorCode
+	}
+
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	err = twoFactor.ReplaceRecoveryCodes(db, codes)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	recordSecurityEvent(db, userId, models.SecurityEventRecoveryCodesRegenerated, client)
+
+	return gin.H{"recovery_codes": codes}, http.StatusOK, nil
+}
+
+func DisableTwoFactor(db *gorm.DB, req models.TwoFactorCodeRequest, userId string, client models.ClientInfo) (int, error) {
+	var twoFactor models.TwoFactor
+
+	_, err := twoFactor.GetByUserID(db, userId)
+	if err != nil || !twoFactor.Enabled {
+		return http.StatusBadRequest, errors.New("two-factor authentication is not enabled")
+	}
+
+	ok, err := checkTwoFactorCode(db, twoFactor, req.Code)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !ok {
+		return http.StatusBadRequest, errInvalidTwoFactorCode
+	}
+
+	err = twoFactor.Disable(db)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	recordSecurityEvent(db, userId, models.SecurityEventTwoFactorDisabled, client)
+	return http.StatusOK, nil
+}
+
+// VerifyTwoFactor completes a login started with a password. A challenge
+// allows a handful of wrong codes before it is thrown away, and failures are
+// also counted per account and IP so fresh challenges do not buy more
+// guesses.
+func VerifyTwoFactor(req models.VerifyTwoFactorRequest, db *gorm.DB, client models.ClientInfo, logger *utility.Logger) (gin.H, int, error) {
+	var (
+		twoFactor models.TwoFactor
+		user      models.User
+		userId    string
+		key       = twoFactorChallengeKey(req.ChallengeToken)
+	)
+
+	stored, err := rdb.RedisGet(storage.DB.Redis, key)
+	if err != nil || json.Unmarshal(stored, &userId) != nil {
+		return nil, http.StatusUnauthorized, errInvalidTwoFactorChallenge
+	}
+
+	attempts, _, err := rdb.IncrWindow(storage.DB.Redis, key+":attempts", twoFactorChallengeLifetime)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if attempts > twoFactorMaxAttempts {
+		_, _ = rdb.RedisDelete(storage.DB.Redis, key)
+		return nil, http.StatusUnauthorized, errInvalidTwoFactorChallenge
+	}
+
+	user, err = user.GetUserByID(db, userId)
+	if err != nil {
+		return nil, http.StatusUnauthorized, errInvalidTwoFactorChallenge
+	}
+
+	guard := newAttemptGuard(db, guardTwoFactor, client.IPAddress, user.Email)
+	if err := guard.Check(); err != nil {
+		return nil, http.StatusTooManyRequests, err
+	}
+
+	_, err = twoFactor.GetByUserID(db, userId)
+	if err != nil || !twoFactor.Enabled {
+		return nil, http.StatusUnauthorized, errInvalidTwoFactorChallenge
+	}
+
+	ok, err := checkTwoFactorCode(db, twoFactor, req.Code)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if !ok {
+		guard.Fail(client.IPAddress)
+		recordSecurityEvent(db, userId, models.SecurityEventTwoFactorFailed, client)
+		return nil, http.StatusUnauthorized, errInvalidTwoFactorCode
+	}
+	guard.Succeed()
+
+	// a challenge is good for one session only
+	deleted, err := rdb.RedisDelete(storage.DB.Redis, key)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if deleted == 0 {
+		return nil, http.StatusUnauthorized, errInvalidTwoFactorChallenge
+	}
+
+	return completeLogin(db, user, client, logger)
+}
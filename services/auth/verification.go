@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -44,7 +43,9 @@ func VerifyEmailReq(userEmail string, db *gorm.DB, extReq request.ExternalReques
 		return "error", http.StatusNotFound, fmt.Errorf("user not found")
 	}
 
-	resetToken, err := utility.GenerateOTP(6)
+	// the code is the only thing the request carries, so it has to be too
+	// long to guess rather than a short OTP
+	resetToken, err := utility.GenerateSecureToken(32)
 
 	if err != nil {
 		return "error", http.StatusInternalServerError, err
@@ -53,7 +54,7 @@ func VerifyEmailReq(userEmail string, db *gorm.DB, extReq request.ExternalReques
 	reset := models.PasswordReset{
 		ID:        utility.GenerateUUID(),
 		Email:     strings.ToLower(userEmail),
-		Token:     strconv.Itoa(resetToken),
+		Token:     resetToken,
 		ExpiresAt: time.Now().Add(time.Duration(config.App.ResetPasswordDuration) * time.Minute),
 	}
 
@@ -75,15 +76,21 @@ func VerifyEmailReq(userEmail string, db *gorm.DB, extReq request.ExternalReques
 	return "success", http.StatusOK, nil
 }
 
-func VerifyEmailToken(req models.VerifyEmailTokenReqModel, db *gorm.DB) (*models.User, int, error) {
+func VerifyEmailToken(req models.VerifyEmailTokenReqModel, db *gorm.DB, client models.ClientInfo, logger *utility.Logger) (*models.User, int, error) {
 
 	var (
 		user      = models.User{}
 		passReset = models.PasswordReset{}
 	)
 
+	guard := newAttemptGuard(db, guardEmailVerify, client.IPAddress, "")
+	if err := guard.Check(); err != nil {
+		return nil, http.StatusTooManyRequests, err
+	}
+
 	resetExist, err := passReset.GetPasswordResetByToken(db, req.Token)
 	if err != nil {
+		guard.Fail(client.IPAddress)
 		return nil, http.StatusUnauthorized, errors.New("invalid or expired token")
 	}
 
@@ -103,6 +110,8 @@ func VerifyEmailToken(req models.VerifyEmailTokenReqModel, db *gorm.DB) (*models
 		return nil, http.StatusInternalServerError, err
 	}
 
+	autoJoinWorkspaces(db, userDataExist, logger)
+
 	return nil, http.StatusOK, nil
 
 }
@@ -0,0 +1,227 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// RequestAccountExport queues a copy of the user's data. Only one export may
+// be waiting at a time.
+func RequestAccountExport(db *gorm.DB, userId string) (models.AccountExport, int, error) {
+	if models.HasExportInProgress(db, userId) {
+		return models.AccountExport{}, http.StatusConflict, errors.New("an export of your data is already in progress")
+	}
+
+	export := models.AccountExport{
+		ID:     utility.GenerateUUID(),
+		UserID: userId,
+	}
+
+	err := export.CreateAccountExport(db)
+	if err != nil {
+		return export, http.StatusInternalServerError, err
+	}
+
+	return export, http.StatusAccepted, nil
+}
+
+// GetAccountExportFile resolves a download link to the stored archive.
+func GetAccountExportFile(db *gorm.DB, id, token string) (string, string, int, error) {
+	var export models.AccountExport
+
+	export, err := export.GetAccountExportByToken(db, id, utility.HashToken(token))
+	if err != nil {
+		return "", "", http.StatusNotFound, errors.New("export not found or link expired")
+	}
+
+	fileName := fmt.Sprintf("telex-data-%v.zip", export.CreatedAt.Format("2006-01-02"))
+	return export.FilePath, fileName, http.StatusOK, nil
+}
+
+// ProcessAccountExport builds the archive for a claimed export and queues the
+// download link email.
+func ProcessAccountExport(db *gorm.DB, rdb *redis.Client, export models.AccountExport) error {
+	var user models.User
+
+	user, err := user.GetUserWithProfile(db, export.UserID)
+	if err == nil {
+		export.FilePath, err = writeAccountExport(db, user, export)
+	}
+	if err != nil {
+		return failAccountExport(db, export, err)
+	}
+
+	token, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		removeExportFile(export.FilePath)
+		return failAccountExport(db, export, err)
+	}
+
+	var (
+		now       = time.Now()
+		expiresAt = now.Add(downloadLifetime)
+	)
+
+	export.Status = models.ExportCompleted
+	export.DownloadTokenHash = utility.HashToken(token)
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+
+	err = export.Update(db)
+	if err != nil {
+		removeExportFile(export.FilePath)
+		return failAccountExport(db, export, err)
+	}
+
+	notification := models.SendAccountExportReady{
+		Email:        user.Email,
+		DownloadLink: fmt.Sprintf("%v/api/v1/account-exports/%v/download?token=%v", config.GetConfig().App.Url, export.ID, token),
+		ExpiresAt:    expiresAt.UTC().Format(time.RFC1123),
+	}
+
+	return actions.AddNotificationToQueue(rdb, names.SendAccountExportReady, notification)
+}
+
+// failAccountExport records why a claimed export could not be built. If even
+// that fails, the claim times out and the export is built again.
+func failAccountExport(db *gorm.DB, export models.AccountExport, cause error) error {
+	export.Status = models.ExportFailed
+	export.Error = cause.Error()
+	export.FilePath = ""
+	export.DownloadTokenHash = ""
+	export.CompletedAt = nil
+	export.ExpiresAt = nil
+
+	if err := export.Update(db); err != nil {
+		return fmt.Errorf("%v, and marking the export failed: %v", cause, err)
+	}
+	return cause
+}
+
+// writeAccountExport zips the user's profile, sessions, room memberships and
+// authored messages, one JSON file each.
+func writeAccountExport(db *gorm.DB, user models.User, export models.AccountExport) (string, error) {
+	var (
+		token    models.AccessToken
+		basePath = config.GetConfig().Storage.LocalPath
+	)
+
+	if basePath == "" {
+		basePath = "./storage"
+	}
+	dir := filepath.Join(basePath, "exports")
+
+	sessions, err := token.GetOwnerTokens(db, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	memberships, err := models.GetUserRoomMemberships(db, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(dir, fmt.Sprintf("account-%v.zip", export.ID))
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeAccountArchive(db, file, user, sessions, memberships)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		removeExportFile(filePath)
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+func writeAccountArchive(db *gorm.DB, file io.Writer, user models.User, sessions []models.AccessToken, memberships []models.RoomMembership) error {
+	archive := zip.NewWriter(file)
+
+	for name, data := range map[string]interface{}{
+		"profile.json":  user,
+		"sessions.json": sessions,
+		"rooms.json":    memberships,
+	} {
+		if err := writeZipJSON(archive, name, data); err != nil {
+			return err
+		}
+	}
+
+	w, err := archive.Create("messages.json")
+	if err != nil {
+		return err
+	}
+	if err := writeUserMessages(db, w, user.ID); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func writeZipJSON(archive *zip.Writer, name string, data interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// writeUserMessages streams the user's messages as a JSON array.
+func writeUserMessages(db *gorm.DB, w io.Writer, userID string) error {
+	first := true
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	err := models.EachUserMessage(db, userID, messageBatchSize, func(message models.Message) error {
+		b, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+
+		sep := ",\n  "
+		if first {
+			sep, first = "\n  ", false
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n]\n")
+	return err
+}
@@ -0,0 +1,267 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+var (
+	messageBatchSize = 500
+	downloadLifetime = 7 * 24 * time.Hour
+)
+
+func RequestRoomExport(req models.CreateRoomExportRequest, db *gorm.DB, roomId, userId string) (models.RoomExport, int, error) {
+	var room models.Room
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomId)
+	if !exists {
+		return models.RoomExport{}, http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	if room.OwnerId != userId {
+		return models.RoomExport{}, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	export := models.RoomExport{
+		ID:          utility.GenerateUUID(),
+		RoomID:      roomId,
+		RequestedBy: userId,
+		Format:      req.Format,
+	}
+
+	err := export.CreateRoomExport(db)
+	if err != nil {
+		return export, http.StatusInternalServerError, err
+	}
+
+	return export, http.StatusAccepted, nil
+}
+
+func GetRoomExport(db *gorm.DB, id, roomId, userId string) (models.RoomExport, int, error) {
+	var export models.RoomExport
+
+	export, code, err := export.GetRoomExportByID(db, id, roomId, userId)
+	if err != nil {
+		return export, code, err
+	}
+	return export, http.StatusOK, nil
+}
+
+// GetExportFile resolves a download link to the stored transcript on disk.
+func GetExportFile(db *gorm.DB, id, token string) (string, string, int, error) {
+	var export models.RoomExport
+
+	export, err := export.GetRoomExportByToken(db, id, utility.HashToken(token))
+	if err != nil {
+		return "", "", http.StatusNotFound, errors.New("export not found or link expired")
+	}
+
+	fileName := fmt.Sprintf("room-%v.%v", export.RoomID, fileExtension(export.Format))
+	return export.FilePath, fileName, http.StatusOK, nil
+}
+
+// ProcessRoomExport builds the transcript for a claimed export, stores it under
+// the configured local storage path and queues the download link email.
+func ProcessRoomExport(db *gorm.DB, rdb *redis.Client, export models.RoomExport) error {
+	filePath, err := writeRoomExport(db, export)
+	if err != nil {
+		return failRoomExport(db, export, err)
+	}
+
+	token, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		removeExportFile(filePath)
+		return failRoomExport(db, export, err)
+	}
+
+	var (
+		now       = time.Now()
+		expiresAt = now.Add(downloadLifetime)
+	)
+
+	export.Status = models.ExportCompleted
+	export.FilePath = filePath
+	export.DownloadTokenHash = utility.HashToken(token)
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+
+	err = export.Update(db)
+	if err != nil {
+		removeExportFile(filePath)
+		return failRoomExport(db, export, err)
+	}
+
+	var (
+		user models.User
+		room models.Room
+	)
+
+	user, err = user.GetUserByID(db, export.RequestedBy)
+	if err != nil {
+		return err
+	}
+
+	err = db.First(&room, "id = ?", export.RoomID).Error
+	if err != nil {
+		return err
+	}
+
+	notification := models.SendRoomExportReady{
+		Email:        user.Email,
+		RoomName:     room.Name,
+		DownloadLink: fmt.Sprintf("%v/api/v1/exports/%v/download?token=%v", config.GetConfig().App.Url, export.ID, token),
+		ExpiresAt:    expiresAt.UTC().Format(time.RFC1123),
+	}
+
+	return actions.AddNotificationToQueue(rdb, names.SendRoomExportReady, notification)
+}
+
+// failRoomExport records why a claimed export could not be built. If even
+// that fails, the claim times out and the export is built again.
+func failRoomExport(db *gorm.DB, export models.RoomExport, cause error) error {
+	export.Status = models.ExportFailed
+	export.Error = cause.Error()
+	export.FilePath = ""
+	export.DownloadTokenHash = ""
+	export.CompletedAt = nil
+	export.ExpiresAt = nil
+
+	if err := export.Update(db); err != nil {
+		return fmt.Errorf("%v, and marking the export failed: %v", cause, err)
+	}
+	return cause
+}
+
+func removeExportFile(filePath string) {
+	if filePath != "" {
+		_ = os.Remove(filePath)
+	}
+}
+
+// PurgeExpiredExports deletes the files of room and account exports whose
+// download links have expired. It returns how many it removed.
+func PurgeExpiredExports(db *gorm.DB, now time.Time, limit int) (int, error) {
+	var (
+		roomExport    models.RoomExport
+		accountExport models.AccountExport
+		purged        int
+	)
+
+	roomExports, err := roomExport.GetExpiredRoomExports(db, now, limit)
+	if err != nil {
+		return purged, err
+	}
+	for _, export := range roomExports {
+		if err := deleteExportFile(export.FilePath); err != nil {
+			return purged, err
+		}
+		if err := export.MarkExpired(db); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	accountExports, err := accountExport.GetExpiredAccountExports(db, now, limit)
+	if err != nil {
+		return purged, err
+	}
+	for _, export := range accountExports {
+		if err := deleteExportFile(export.FilePath); err != nil {
+			return purged, err
+		}
+		if err := export.MarkExpired(db); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// deleteExportFile removes an export's file; one already gone is not an error.
+func deleteExportFile(filePath string) error {
+	if filePath == "" {
+		return nil
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func writeRoomExport(db *gorm.DB, export models.RoomExport) (string, error) {
+	var (
+		room     models.Room
+		basePath = config.GetConfig().Storage.LocalPath
+	)
+
+	if basePath == "" {
+		basePath = "./storage"
+	}
+	dir := filepath.Join(basePath, "exports")
+
+	err := db.First(&room, "id = ?", export.RoomID).Error
+	if err != nil {
+		return "", err
+	}
+
+	members, err := room.GetRoomUsersByID(db, room.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(dir, fmt.Sprintf("%v.%v", export.ID, fileExtension(export.Format)))
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeTranscript(db, file, export.Format, room, members)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// a partial transcript must never be handed out
+		removeExportFile(filePath)
+		return "", err
+	}
+
+	return filePath, nil
+}
+
+func writeTranscript(db *gorm.DB, w io.Writer, format string, room models.Room, members []models.UserRoom) error {
+	writer, err := newTranscriptWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteHeader(room, members); err != nil {
+		return err
+	}
+
+	err = models.EachRoomMessage(db, room.ID, messageBatchSize, writer.WriteMessage)
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
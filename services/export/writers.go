@@ -0,0 +1,286 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hngprojects/telex_be/internal/models"
+)
+
+// transcriptWriter writes a room transcript one message at a time so exports
+// never need the full history in memory.
+type transcriptWriter interface {
+	WriteHeader(room models.Room, members []models.UserRoom) error
+	WriteMessage(message models.Message) error
+	Close() error
+}
+
+func newTranscriptWriter(format string, w io.Writer) (transcriptWriter, error) {
+	buf := bufio.NewWriter(w)
+
+	switch format {
+	case models.ExportFormatJSON:
+		return &jsonWriter{w: buf}, nil
+	case models.ExportFormatCSV:
+		return &csvWriter{buf: buf, w: csv.NewWriter(buf)}, nil
+	case models.ExportFormatHTML:
+		return &htmlWriter{w: buf}, nil
+	case models.ExportFormatMbox:
+		return &mboxWriter{w: buf}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %v", format)
+	}
+}
+
+func fileExtension(format string) string {
+	if format == models.ExportFormatMbox {
+		return "mbox"
+	}
+	return format
+}
+
+type exportMember struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type exportRoom struct {
+	ID          string         `json:"room_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	OwnerID     string         `json:"owner_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Members     []exportMember `json:"members"`
+}
+
+type exportMessage struct {
+	ID          int       `json:"id"`
+	UserID      string    `json:"user_id"`
+	Username    string    `json:"username"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+	Attachments []string  `json:"attachments"`
+}
+
+func toExportRoom(room models.Room, members []models.UserRoom) exportRoom {
+	out := exportRoom{
+		ID:          room.ID,
+		Name:        room.Name,
+		Description: room.Description,
+		OwnerID:     room.OwnerId,
+		CreatedAt:   room.CreatedAt,
+		Members:     make([]exportMember, 0, len(members)),
+	}
+	for _, m := range members {
+		out.Members = append(out.Members, exportMember{UserID: m.UserID, Username: m.Username, JoinedAt: m.CreatedAt})
+	}
+	return out
+}
+
+// messages carry no attachments yet; the field keeps the export shape stable
+// for when they do.
+func toExportMessage(message models.Message) exportMessage {
+	return exportMessage{
+		ID:          message.ID,
+		UserID:      message.UserID,
+		Username:    message.Username,
+		Content:     message.Content,
+		CreatedAt:   message.CreatedAt,
+		Attachments: []string{},
+	}
+}
+
+type jsonWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (j *jsonWriter) WriteHeader(room models.Room, members []models.UserRoom) error {
+	roomData, err := json.Marshal(toExportRoom(room, members))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, "{\"room\":%s,\"messages\":[", roomData)
+	return err
+}
+
+func (j *jsonWriter) WriteMessage(message models.Message) error {
+	if j.count > 0 {
+		if err := j.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	j.count++
+
+	data, err := json.Marshal(toExportMessage(message))
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if _, err := j.w.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+type csvWriter struct {
+	buf *bufio.Writer
+	w   *csv.Writer
+}
+
+// the CSV export is a flat message log; members are only part of the JSON
+// and HTML transcripts.
+func (c *csvWriter) WriteHeader(room models.Room, members []models.UserRoom) error {
+	if err := c.w.Write([]string{"message_id", "created_at", "user_id", "username", "content", "attachments"}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// attachments are written as a JSON list, the same as in the JSON export.
+func (c *csvWriter) WriteMessage(message models.Message) error {
+	attachments, err := json.Marshal(toExportMessage(message).Attachments)
+	if err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		strconv.Itoa(message.ID),
+		message.CreatedAt.UTC().Format(time.RFC3339),
+		message.UserID,
+		message.Username,
+		message.Content,
+		string(attachments),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+var (
+	htmlHeaderTemplate = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} transcript</title>
+<style>
+body { font-family: "Helvetica Neue", Helvetica, Arial, sans-serif; font-size: 13px; margin: 40px; }
+.meta { color: #555; } .message { margin: 6px 0; } .time { color: #888; } .author { font-weight: bold; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<p class="meta">{{.Description}}</p>
+<p class="meta">Exported {{.ExportedAt}} &middot; {{len .Members}} members</p>
+<h2>Members</h2>
+<ul>{{range .Members}}<li>{{.Username}}</li>{{end}}</ul>
+<h2>Messages</h2>
+`))
+	htmlMessageTemplate = template.Must(template.New("message").Parse(`<div class="message"><span class="time">{{.CreatedAt}}</span> <span class="author">{{.Username}}</span>: {{.Content}}</div>
+`))
+)
+
+type htmlWriter struct {
+	w *bufio.Writer
+}
+
+func (h *htmlWriter) WriteHeader(room models.Room, members []models.UserRoom) error {
+	data := toExportRoom(room, members)
+	return htmlHeaderTemplate.Execute(h.w, map[string]interface{}{
+		"Name":        data.Name,
+		"Description": data.Description,
+		"Members":     data.Members,
+		"ExportedAt":  time.Now().UTC().Format(time.RFC1123),
+	})
+}
+
+func (h *htmlWriter) WriteMessage(message models.Message) error {
+	return htmlMessageTemplate.Execute(h.w, map[string]interface{}{
+		"CreatedAt": message.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+		"Username":  message.Username,
+		"Content":   message.Content,
+	})
+}
+
+func (h *htmlWriter) Close() error {
+	if _, err := h.w.WriteString("</body>\n</html>\n"); err != nil {
+		return err
+	}
+	return h.w.Flush()
+}
+
+type mboxWriter struct {
+	w    *bufio.Writer
+	room models.Room
+}
+
+func (m *mboxWriter) WriteHeader(room models.Room, members []models.UserRoom) error {
+	m.room = room
+	return nil
+}
+
+// singleLine drops line breaks from user supplied text bound for a header,
+// where they would start new headers.
+func singleLine(text string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+}
+
+// headerText is singleLine with non-ASCII text encoded for a header value.
+func headerText(text string) string {
+	return mime.QEncoding.Encode("utf-8", singleLine(text))
+}
+
+func (m *mboxWriter) WriteMessage(message models.Message) error {
+	var (
+		from   = fmt.Sprintf("%v@telex.local", message.UserID)
+		author = mail.Address{Name: singleLine(message.Username), Address: from}
+		body   = strings.ReplaceAll(message.Content, "\r\n", "\n")
+	)
+
+	_, err := fmt.Fprintf(m.w, "From %s %s\nFrom: %s\nDate: %s\nSubject: %s\nMessage-ID: <%d.%s@telex.local>\nContent-Type: text/plain; charset=utf-8\n\n",
+		from, message.CreatedAt.UTC().Format(time.ANSIC),
+		author.String(),
+		message.CreatedAt.UTC().Format(time.RFC1123Z),
+		headerText(m.room.Name),
+		message.ID, m.room.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		// mboxrd quoting: any line that looks like a separator gets a leading '>'
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			line = ">" + line
+		}
+		if _, err := m.w.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+
+	_, err = m.w.WriteString("\n")
+	return err
+}
+
+func (m *mboxWriter) Close() error {
+	return m.w.Flush()
+}
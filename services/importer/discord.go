@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hngprojects/telex_be/internal/models"
+)
+
+type discordAuthor struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Nickname string `json:"nickname"`
+}
+
+type discordMessage struct {
+	ID        string        `json:"id"`
+	Type      string        `json:"type"`
+	Timestamp time.Time     `json:"timestamp"`
+	Content   string        `json:"content"`
+	Author    discordAuthor `json:"author"`
+}
+
+type discordChannelExport struct {
+	Channel struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Topic string `json:"topic"`
+	} `json:"channel"`
+	Messages []discordMessage `json:"messages"`
+}
+
+var discordBatchSize = 500
+
+// DiscordExport reads DiscordChatExporter JSON output: one document per
+// channel, either as a single .json file or several bundled in a zip.
+type DiscordExport struct {
+	channels []discordChannelExport
+}
+
+func OpenDiscordExport(path string) (*DiscordExport, error) {
+	export := &DiscordExport{}
+
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return export, export.add(file)
+	}
+
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+
+		file, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		err = export.add(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return export, nil
+}
+
+func (d *DiscordExport) add(r io.Reader) error {
+	var channel discordChannelExport
+
+	if err := json.NewDecoder(r).Decode(&channel); err != nil {
+		return err
+	}
+	d.channels = append(d.channels, channel)
+	return nil
+}
+
+func (d *DiscordExport) Provider() string {
+	return models.ImportProviderDiscord
+}
+
+func (d *DiscordExport) Close() error {
+	return nil
+}
+
+// Users is derived from message authors, since Discord exports carry no
+// separate member list.
+func (d *DiscordExport) Users() ([]User, error) {
+	var (
+		users []User
+		seen  = map[string]bool{}
+	)
+
+	for _, channel := range d.channels {
+		for _, m := range channel.Messages {
+			if seen[m.Author.ID] {
+				continue
+			}
+			seen[m.Author.ID] = true
+			users = append(users, User{ExternalID: m.Author.ID, Username: m.Author.Name, FirstName: m.Author.Nickname})
+		}
+	}
+	return users, nil
+}
+
+func (d *DiscordExport) Channels() ([]Channel, error) {
+	channels := make([]Channel, 0, len(d.channels))
+
+	for _, c := range d.channels {
+		var (
+			members []string
+			seen    = map[string]bool{}
+		)
+		for _, m := range c.Messages {
+			if !seen[m.Author.ID] {
+				seen[m.Author.ID] = true
+				members = append(members, m.Author.ID)
+			}
+		}
+
+		channels = append(channels, Channel{ExternalID: c.Channel.ID, Name: c.Channel.Name, Description: c.Channel.Topic, Members: members})
+	}
+	return channels, nil
+}
+
+func (d *DiscordExport) EachMessageBatch(channel Channel, fn func([]Message) error) error {
+	for _, c := range d.channels {
+		if c.Channel.ID != channel.ExternalID {
+			continue
+		}
+
+		batch := make([]Message, 0, discordBatchSize)
+		for _, m := range c.Messages {
+			// only plain messages and replies carry user content
+			if (m.Type != "Default" && m.Type != "Reply") || m.Content == "" {
+				continue
+			}
+
+			batch = append(batch, Message{
+				ExternalID:     m.ID,
+				UserExternalID: m.Author.ID,
+				Username:       m.Author.Name,
+				Content:        m.Content,
+				Timestamp:      m.Timestamp.UTC(),
+			})
+
+			if len(batch) == discordBatchSize {
+				if err := fn(batch); err != nil {
+					return err
+				}
+				batch = make([]Message, 0, discordBatchSize)
+			}
+		}
+
+		if len(batch) > 0 {
+			return fn(batch)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// User, Channel and Message are the provider-neutral shapes every export
+// adapter produces.
+type User struct {
+	ExternalID string
+	Username   string
+	FirstName  string
+	LastName   string
+}
+
+type Channel struct {
+	ExternalID  string
+	Name        string
+	Description string
+	Members     []string
+}
+
+type Message struct {
+	ExternalID     string
+	UserExternalID string
+	Username       string
+	Content        string
+	Timestamp      time.Time
+}
+
+// Source is implemented by each export adapter. EachMessageBatch must yield a
+// channel's messages in chronological order.
+type Source interface {
+	Provider() string
+	Users() ([]User, error)
+	Channels() ([]Channel, error)
+	EachMessageBatch(channel Channel, fn func([]Message) error) error
+	Close() error
+}
+
+type Result struct {
+	RoomsCreated    int `json:"rooms_created"`
+	UsersCreated    int `json:"users_created"`
+	MessagesCreated int `json:"messages_created"`
+	MessagesSkipped int `json:"messages_skipped"`
+}
+
+// Open picks the adapter for provider and opens the export at path.
+func Open(provider, path string) (Source, error) {
+	switch provider {
+	case models.ImportProviderSlack:
+		return OpenSlackExport(path)
+	case models.ImportProviderDiscord:
+		return OpenDiscordExport(path)
+	default:
+		return nil, fmt.Errorf("unsupported import provider: %v", provider)
+	}
+}
+
+type run struct {
+	db      *gorm.DB
+	src     Source
+	ownerID string
+	users   map[string]User
+	userIDs map[string]string
+	result  Result
+}
+
+// Run imports everything in src, attributing new rooms to ownerID. Objects
+// already recorded in import_refs are reused, so re-running an import only
+// adds what is missing.
+func Run(db *gorm.DB, src Source, ownerID string) (Result, error) {
+	r := &run{
+		db:      db,
+		src:     src,
+		ownerID: ownerID,
+		users:   map[string]User{},
+		userIDs: map[string]string{},
+	}
+
+	users, err := src.Users()
+	if err != nil {
+		return r.result, err
+	}
+
+	for _, user := range users {
+		r.users[user.ExternalID] = user
+		if _, err := r.ensureUser(user); err != nil {
+			return r.result, err
+		}
+	}
+
+	channels, err := src.Channels()
+	if err != nil {
+		return r.result, err
+	}
+
+	for _, channel := range channels {
+		roomID, err := r.ensureRoom(channel)
+		if err != nil {
+			return r.result, err
+		}
+
+		err = src.EachMessageBatch(channel, func(batch []Message) error {
+			return r.importMessages(roomID, batch)
+		})
+		if err != nil {
+			return r.result, err
+		}
+	}
+
+	return r.result, nil
+}
+
+func (r *run) ensureUser(user User) (string, error) {
+	if id, ok := r.userIDs[user.ExternalID]; ok {
+		return id, nil
+	}
+
+	var ref models.ImportRef
+
+	ref, err := ref.GetImportRef(r.db, r.src.Provider(), models.ImportRefUser, user.ExternalID)
+	if err == nil {
+		r.userIDs[user.ExternalID] = ref.InternalID
+		return ref.InternalID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	// placeholders use a reserved domain and no password so they can never log
+	// in or collide with a real account
+	placeholder := models.User{
+		ID:    utility.GenerateUUID(),
+		Name:  strings.ToLower(user.Username),
+		Email: fmt.Sprintf("%v-%v@import.invalid", r.src.Provider(), strings.ToLower(user.ExternalID)),
+		Profile: models.Profile{
+			ID:        utility.GenerateUUID(),
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		},
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := placeholder.CreateUser(tx); err != nil {
+			return err
+		}
+		ref = models.ImportRef{Provider: r.src.Provider(), Kind: models.ImportRefUser, ExternalID: user.ExternalID, InternalID: placeholder.ID}
+		return ref.CreateImportRef(tx)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	r.result.UsersCreated++
+	r.userIDs[user.ExternalID] = placeholder.ID
+	return placeholder.ID, nil
+}
+
+func (r *run) ensureRoom(channel Channel) (string, error) {
+	var ref models.ImportRef
+
+	ref, err := ref.GetImportRef(r.db, r.src.Provider(), models.ImportRefRoom, channel.ExternalID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	roomID := ref.InternalID
+	if roomID == "" {
+		room := models.Room{
+			ID:          utility.GenerateUUID(),
+			Name:        r.roomName(channel.Name),
+			Description: channel.Description,
+			OwnerId:     r.ownerID,
+		}
+
+		err = r.db.Transaction(func(tx *gorm.DB) error {
+			if err := room.CreateRoom(tx); err != nil {
+				return err
+			}
+			ref = models.ImportRef{Provider: r.src.Provider(), Kind: models.ImportRefRoom, ExternalID: channel.ExternalID, InternalID: room.ID}
+			return ref.CreateImportRef(tx)
+		})
+		if err != nil {
+			return "", err
+		}
+
+		r.result.RoomsCreated++
+		roomID = room.ID
+	}
+
+	var owner models.User
+	owner, err = owner.GetUserByID(r.db, r.ownerID)
+	if err != nil {
+		return "", err
+	}
+	if err := r.addMember(roomID, r.ownerID, owner.Name); err != nil {
+		return "", err
+	}
+
+	for _, member := range channel.Members {
+		userID, err := r.ensureUser(r.lookupUser(member, ""))
+		if err != nil {
+			return "", err
+		}
+		if err := r.addMember(roomID, userID, r.users[member].Username); err != nil {
+			return "", err
+		}
+	}
+
+	return roomID, nil
+}
+
+// roomName keeps the channel name when it is free and otherwise suffixes the
+// provider, since room names are unique among rooms outside a workspace.
+func (r *run) roomName(name string) string {
+	var room models.Room
+
+	candidate := name
+	for i := 1; ; i++ {
+		if err := models.InWorkspace(r.db, "").Where("name = ?", candidate).First(&room).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%v-%v", name, r.src.Provider())
+		if i > 1 {
+			candidate = fmt.Sprintf("%v-%v-%d", name, r.src.Provider(), i)
+		}
+	}
+}
+
+func (r *run) addMember(roomID, userID, username string) error {
+	userRoom := models.UserRoom{RoomID: roomID, UserID: userID, Username: username}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&userRoom).Error
+}
+
+func (r *run) lookupUser(externalID, username string) User {
+	if user, ok := r.users[externalID]; ok {
+		return user
+	}
+	if username == "" {
+		username = externalID
+	}
+	user := User{ExternalID: externalID, Username: username}
+	r.users[externalID] = user
+	return user
+}
+
+func (r *run) importMessages(roomID string, batch []Message) error {
+	var (
+		ref         models.ImportRef
+		externalIDs = make([]string, 0, len(batch))
+	)
+
+	for _, message := range batch {
+		externalIDs = append(externalIDs, message.ExternalID)
+	}
+
+	existing, err := ref.GetImportRefs(r.db, r.src.Provider(), models.ImportRefMessage, externalIDs)
+	if err != nil {
+		return err
+	}
+
+	var (
+		messages = make([]models.Message, 0, len(batch))
+		pending  = make([]string, 0, len(batch))
+	)
+
+	for _, message := range batch {
+		if _, ok := existing[message.ExternalID]; ok {
+			r.result.MessagesSkipped++
+			continue
+		}
+
+		author := r.lookupUser(message.UserExternalID, message.Username)
+		userID, err := r.ensureUser(author)
+		if err != nil {
+			return err
+		}
+
+		username := message.Username
+		if username == "" {
+			username = author.Username
+		}
+
+		messages = append(messages, models.Message{
+			Content:   message.Content,
+			RoomID:    roomID,
+			UserID:    userID,
+			Username:  username,
+			CreatedAt: message.Timestamp,
+		})
+		pending = append(pending, message.ExternalID)
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&messages).Error; err != nil {
+			return err
+		}
+
+		refs := make([]models.ImportRef, 0, len(messages))
+		for i, message := range messages {
+			refs = append(refs, models.ImportRef{
+				Provider:   r.src.Provider(),
+				Kind:       models.ImportRefMessage,
+				ExternalID: pending[i],
+				InternalID: strconv.Itoa(message.ID),
+			})
+		}
+		return tx.Create(&refs).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	r.result.MessagesCreated += len(messages)
+	return nil
+}
@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// MaxUploadBytes caps the size of an uploaded export.
+const MaxUploadBytes = 512 << 20
+
+var errUploadTooLarge = fmt.Errorf("export file must be %dMB or smaller", MaxUploadBytes>>20)
+
+// CreateImportJob stores the uploaded export and queues it for the
+// process-imports cron job.
+func CreateImportJob(db *gorm.DB, provider string, upload *multipart.FileHeader, userId string) (models.ImportJob, int, error) {
+	if provider != models.ImportProviderSlack && provider != models.ImportProviderDiscord {
+		return models.ImportJob{}, http.StatusBadRequest, fmt.Errorf("unsupported import provider: %v", provider)
+	}
+
+	if upload.Size > MaxUploadBytes {
+		return models.ImportJob{}, http.StatusRequestEntityTooLarge, errUploadTooLarge
+	}
+
+	ext := strings.ToLower(filepath.Ext(upload.Filename))
+	if ext != ".zip" && !(provider == models.ImportProviderDiscord && ext == ".json") {
+		return models.ImportJob{}, http.StatusBadRequest, errors.New("unsupported export file type")
+	}
+
+	basePath := config.GetConfig().Storage.LocalPath
+	if basePath == "" {
+		basePath = "./storage"
+	}
+	dir := filepath.Join(basePath, "imports")
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return models.ImportJob{}, http.StatusInternalServerError, err
+	}
+
+	job := models.ImportJob{
+		ID:          utility.GenerateUUID(),
+		Provider:    provider,
+		RequestedBy: userId,
+	}
+	job.FilePath = filepath.Join(dir, job.ID+ext)
+
+	if err := saveUpload(upload, job.FilePath); err != nil {
+		os.Remove(job.FilePath)
+		if errors.Is(err, errUploadTooLarge) {
+			return job, http.StatusRequestEntityTooLarge, err
+		}
+		return job, http.StatusInternalServerError, err
+	}
+
+	err := job.CreateImportJob(db)
+	if err != nil {
+		os.Remove(job.FilePath)
+		return job, http.StatusInternalServerError, err
+	}
+
+	return job, http.StatusAccepted, nil
+}
+
+func GetImportJob(db *gorm.DB, id string) (models.ImportJob, int, error) {
+	var job models.ImportJob
+
+	job, code, err := job.GetImportJobByID(db, id)
+	if err != nil {
+		return job, code, err
+	}
+	return job, http.StatusOK, nil
+}
+
+// ProcessImportJob runs a claimed job and records its outcome. The uploaded
+// file is kept on failure so the job can be retried.
+func ProcessImportJob(db *gorm.DB, job models.ImportJob) error {
+	result, err := runImport(db, job)
+
+	job.RoomsCreated = result.RoomsCreated
+	job.UsersCreated = result.UsersCreated
+	job.MessagesCreated = result.MessagesCreated
+	job.MessagesSkipped = result.MessagesSkipped
+
+	if err != nil {
+		job.Status = models.ImportFailed
+		job.Error = err.Error()
+		if updateErr := job.Update(db); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+
+	now := time.Now()
+	job.Status = models.ImportCompleted
+	job.CompletedAt = &now
+
+	if err := job.Update(db); err != nil {
+		return err
+	}
+
+	os.Remove(job.FilePath)
+	return nil
+}
+
+func runImport(db *gorm.DB, job models.ImportJob) (Result, error) {
+	src, err := Open(job.Provider, job.FilePath)
+	if err != nil {
+		return Result{}, err
+	}
+	defer src.Close()
+
+	return Run(db, src, job.RequestedBy)
+}
+
+// saveUpload copies the upload to dst, refusing to write more than
+// MaxUploadBytes whatever size the upload claims.
+func saveUpload(upload *multipart.FileHeader, dst string) error {
+	src, err := upload.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	written, err := io.Copy(out, io.LimitReader(src, MaxUploadBytes+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written > MaxUploadBytes {
+		return errUploadTooLarge
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hngprojects/telex_be/internal/models"
+)
+
+type slackUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Profile  struct {
+		DisplayName string `json:"display_name"`
+		FirstName   string `json:"first_name"`
+		LastName    string `json:"last_name"`
+	} `json:"profile"`
+}
+
+type slackChannel struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+	Purpose struct {
+		Value string `json:"value"`
+	} `json:"purpose"`
+	Topic struct {
+		Value string `json:"value"`
+	} `json:"topic"`
+}
+
+type slackMessage struct {
+	Type     string `json:"type"`
+	Subtype  string `json:"subtype"`
+	User     string `json:"user"`
+	BotID    string `json:"bot_id"`
+	Username string `json:"username"`
+	Text     string `json:"text"`
+	Ts       string `json:"ts"`
+}
+
+// membership churn is carried by the room itself, not its history
+var skippedSlackSubtypes = map[string]bool{
+	"channel_join":  true,
+	"channel_leave": true,
+	"group_join":    true,
+	"group_leave":   true,
+}
+
+// SlackExport reads a standard Slack workspace export: users.json,
+// channels.json and one directory per channel holding a JSON file per day.
+type SlackExport struct {
+	zip   *zip.ReadCloser
+	files map[string]*zip.File
+}
+
+func OpenSlackExport(path string) (*SlackExport, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &SlackExport{zip: reader, files: map[string]*zip.File{}}
+	for _, file := range reader.File {
+		export.files[strings.TrimPrefix(file.Name, "./")] = file
+	}
+	return export, nil
+}
+
+func (s *SlackExport) Provider() string {
+	return models.ImportProviderSlack
+}
+
+func (s *SlackExport) Close() error {
+	return s.zip.Close()
+}
+
+func (s *SlackExport) Users() ([]User, error) {
+	var slackUsers []slackUser
+
+	if err := s.decode("users.json", &slackUsers); err != nil {
+		return nil, err
+	}
+
+	users := make([]User, 0, len(slackUsers))
+	for _, u := range slackUsers {
+		username := u.Name
+		if username == "" {
+			username = u.Profile.DisplayName
+		}
+
+		firstName, lastName := u.Profile.FirstName, u.Profile.LastName
+		if firstName == "" && lastName == "" {
+			firstName, lastName, _ = strings.Cut(u.RealName, " ")
+		}
+
+		users = append(users, User{ExternalID: u.ID, Username: username, FirstName: firstName, LastName: lastName})
+	}
+	return users, nil
+}
+
+func (s *SlackExport) Channels() ([]Channel, error) {
+	var slackChannels []slackChannel
+
+	if err := s.decode("channels.json", &slackChannels); err != nil {
+		return nil, err
+	}
+
+	channels := make([]Channel, 0, len(slackChannels))
+	for _, c := range slackChannels {
+		description := c.Purpose.Value
+		if description == "" {
+			description = c.Topic.Value
+		}
+		channels = append(channels, Channel{ExternalID: c.ID, Name: c.Name, Description: description, Members: c.Members})
+	}
+	return channels, nil
+}
+
+// EachMessageBatch yields one batch per day file, oldest first.
+func (s *SlackExport) EachMessageBatch(channel Channel, fn func([]Message) error) error {
+	var days []string
+
+	for name := range s.files {
+		if path.Dir(name) == channel.Name && strings.HasSuffix(name, ".json") {
+			days = append(days, name)
+		}
+	}
+	// day files are named YYYY-MM-DD.json, so lexical order is chronological
+	sort.Strings(days)
+
+	for _, day := range days {
+		var slackMessages []slackMessage
+
+		if err := s.decode(day, &slackMessages); err != nil {
+			return err
+		}
+
+		batch := make([]Message, 0, len(slackMessages))
+		for _, m := range slackMessages {
+			if m.Type != "message" || skippedSlackSubtypes[m.Subtype] || m.Text == "" {
+				continue
+			}
+
+			timestamp, err := parseSlackTs(m.Ts)
+			if err != nil {
+				return fmt.Errorf("%v: %v", day, err)
+			}
+
+			author := m.User
+			if author == "" {
+				author = m.BotID
+			}
+
+			batch = append(batch, Message{
+				ExternalID:     fmt.Sprintf("%v:%v", channel.ExternalID, m.Ts),
+				UserExternalID: author,
+				Username:       m.Username,
+				Content:        m.Text,
+				Timestamp:      timestamp,
+			})
+		}
+
+		if len(batch) == 0 {
+			continue
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SlackExport) decode(name string, v interface{}) error {
+	file, ok := s.files[name]
+	if !ok {
+		return fmt.Errorf("slack export is missing %v", name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return json.NewDecoder(reader).Decode(v)
+}
+
+// parseSlackTs converts a Slack "seconds.micros" timestamp.
+func parseSlackTs(ts string) (time.Time, error) {
+	seconds, micros, _ := strings.Cut(ts, ".")
+
+	sec, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid message timestamp %q", ts)
+	}
+
+	var usec int64
+	if micros != "" {
+		usec, err = strconv.ParseInt(micros, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid message timestamp %q", ts)
+		}
+	}
+
+	return time.Unix(sec, usec*int64(time.Microsecond)).UTC(), nil
+}
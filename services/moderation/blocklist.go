@@ -0,0 +1,169 @@
+package moderation
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hngprojects/telex_be/internal/models"
+)
+
+// blocklistRefresh is how long a room's compiled patterns are reused before
+// its overrides are read again, so changes made on another instance still
+// take effect.
+const blocklistRefresh = time.Minute
+
+// roomPatterns are the block and mask patterns for one room, with its
+// overrides applied. A nil pattern matches nothing.
+type roomPatterns struct {
+	block    *regexp.Regexp
+	mask     *regexp.Regexp
+	loadedAt time.Time
+}
+
+// patternCache holds compiled patterns by room, then by the global list they
+// were built from.
+type patternCache struct {
+	mu    sync.RWMutex
+	rooms map[string]map[string]*roomPatterns
+}
+
+var patterns = &patternCache{rooms: map[string]map[string]*roomPatterns{}}
+
+func (p *patternCache) get(roomID, key string, now time.Time) *roomPatterns {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	entry := p.rooms[roomID][key]
+	if entry == nil || now.Sub(entry.loadedAt) >= blocklistRefresh {
+		return nil
+	}
+	return entry
+}
+
+func (p *patternCache) set(roomID, key string, entry *roomPatterns) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rooms[roomID] == nil {
+		p.rooms[roomID] = map[string]*roomPatterns{}
+	}
+	p.rooms[roomID][key] = entry
+}
+
+// invalidateBlocklist drops a room's compiled patterns. Call it after
+// changing the room's word filters.
+func invalidateBlocklist(roomID string) {
+	patterns.mu.Lock()
+	delete(patterns.rooms, roomID)
+	patterns.mu.Unlock()
+}
+
+// BlocklistFilter matches whole words case-insensitively. Rooms can add their
+// own words or exempt global ones through RoomWordFilter overrides.
+type BlocklistFilter struct {
+	words  []string
+	action string
+	key    string
+}
+
+func NewBlocklistFilter(words []string, action string) *BlocklistFilter {
+	if action != models.FilterActionBlock {
+		action = models.FilterActionMask
+	}
+
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			normalized = append(normalized, word)
+		}
+	}
+	sort.Strings(normalized)
+	key := action + ":" + strings.Join(normalized, ",")
+	return &BlocklistFilter{words: normalized, action: action, key: key}
+}
+
+func (f *BlocklistFilter) Name() string {
+	return "blocklist"
+}
+
+func (f *BlocklistFilter) Check(in Input) (Verdict, error) {
+	room, err := f.patterns(in)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	if room.block != nil && room.block.MatchString(in.Content) {
+		return Verdict{Action: Block, Reason: "message contains a blocked word"}, nil
+	}
+
+	if room.mask != nil && room.mask.MatchString(in.Content) {
+		content := room.mask.ReplaceAllStringFunc(in.Content, func(match string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(match))
+		})
+		return Verdict{Action: Mask, Content: content, Reason: "message contains a masked word"}, nil
+	}
+
+	return Verdict{Action: Allow}, nil
+}
+
+// patterns returns the room's compiled patterns, building them from the
+// global list and the room's overrides when they are not cached.
+func (f *BlocklistFilter) patterns(in Input) (*roomPatterns, error) {
+	now := time.Now()
+	if room := patterns.get(in.RoomID, f.key, now); room != nil {
+		return room, nil
+	}
+
+	var (
+		wordFilter models.RoomWordFilter
+		actions    = make(map[string]string, len(f.words))
+	)
+
+	for _, word := range f.words {
+		actions[word] = f.action
+	}
+
+	overrides, err := wordFilter.GetWordFiltersByRoomID(in.Db, in.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		if override.Action == models.FilterActionAllow {
+			delete(actions, override.Word)
+			continue
+		}
+		actions[override.Word] = override.Action
+	}
+
+	var blocked, masked []string
+	for word, action := range actions {
+		if action == models.FilterActionBlock {
+			blocked = append(blocked, word)
+		} else {
+			masked = append(masked, word)
+		}
+	}
+
+	room := &roomPatterns{loadedAt: now}
+	if len(blocked) > 0 {
+		room.block = wordPattern(blocked)
+	}
+	if len(masked) > 0 {
+		room.mask = wordPattern(masked)
+	}
+
+	patterns.set(in.RoomID, f.key, room)
+	return room, nil
+}
+
+func wordPattern(words []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, regexp.QuoteMeta(word))
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
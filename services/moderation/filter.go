@@ -0,0 +1,86 @@
+package moderation
+
+import (
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+)
+
+type Action int
+
+const (
+	Allow Action = iota
+	Flag
+	Mask
+	Block
+)
+
+// Input is the message a filter inspects. Content reflects any masking done
+// by earlier filters in the chain.
+type Input struct {
+	Db      *gorm.DB
+	RoomID  string
+	UserID  string
+	Content string
+}
+
+type Verdict struct {
+	Action  Action
+	Content string
+	Reason  string
+}
+
+type Filter interface {
+	Name() string
+	Check(in Input) (Verdict, error)
+}
+
+// Result is the combined outcome of a chain: either the message is blocked,
+// or it goes through with Content, raising a report for every flag.
+type Result struct {
+	Blocked bool
+	Reason  string
+	Content string
+	Flags   []string
+}
+
+type Chain []Filter
+
+// NewChain builds the chain new messages pass through. The spam filter needs
+// Redis and is left out when redisClient is nil.
+func NewChain(cfg config.Moderation, redisClient *redis.Client) Chain {
+	chain := Chain{
+		NewBlocklistFilter(cfg.Blocklist, cfg.BlocklistAction),
+		NewLinkFilter(cfg.LinkAllowlist, cfg.LinkDenylist),
+	}
+
+	if redisClient != nil {
+		chain = append(chain, NewSpamFilter(redisClient, cfg.SpamThreshold, cfg.SpamWindow))
+	}
+
+	return chain
+}
+
+// Run applies each filter in order, stopping at the first block.
+func (c Chain) Run(in Input) (Result, error) {
+	var flags []string
+
+	for _, filter := range c {
+		verdict, err := filter.Check(in)
+		if err != nil {
+			return Result{}, err
+		}
+
+		switch verdict.Action {
+		case Block:
+			return Result{Blocked: true, Reason: verdict.Reason}, nil
+		case Mask:
+			in.Content = verdict.Content
+		case Flag:
+			flags = append(flags, verdict.Reason)
+		}
+	}
+
+	return Result{Content: in.Content, Flags: flags}, nil
+}
@@ -0,0 +1,69 @@
+package moderation
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+// LinkFilter blocks links to denied domains and, when an allowlist is set,
+// flags links to any domain not on it. Subdomains match their parent.
+type LinkFilter struct {
+	allow []string
+	deny  []string
+}
+
+func NewLinkFilter(allow, deny []string) *LinkFilter {
+	return &LinkFilter{allow: normalizeDomains(allow), deny: normalizeDomains(deny)}
+}
+
+func (f *LinkFilter) Name() string {
+	return "links"
+}
+
+func (f *LinkFilter) Check(in Input) (Verdict, error) {
+	verdict := Verdict{Action: Allow}
+
+	for _, link := range linkPattern.FindAllString(in.Content, -1) {
+		if !strings.Contains(strings.ToLower(link), "://") {
+			link = "http://" + link
+		}
+
+		parsed, err := url.Parse(link)
+		if err != nil || parsed.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(parsed.Hostname())
+
+		if matchesDomain(host, f.deny) {
+			return Verdict{Action: Block, Reason: "message links to a blocked domain"}, nil
+		}
+		if len(f.allow) > 0 && !matchesDomain(host, f.allow) {
+			verdict = Verdict{Action: Flag, Reason: "message links to an unapproved domain: " + host}
+		}
+	}
+
+	return verdict, nil
+}
+
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "*.")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
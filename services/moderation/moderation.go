@@ -0,0 +1,198 @@
+package moderation
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func requireModerator(db *gorm.DB, roomId, userId string) (int, error) {
+	var room models.Room
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomId)
+	if !exists {
+		return http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	isModerator, err := room.IsRoomModerator(db, roomId, userId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !isModerator {
+		return http.StatusUnauthorized, errors.New("user not authorized")
+	}
+	return http.StatusOK, nil
+}
+
+// FlagMessage queues a message that a filter let through but wants reviewed.
+func FlagMessage(db *gorm.DB, message models.Message, reasons []string) error {
+	report := models.MessageReport{
+		ID:        utility.GenerateUUID(),
+		MessageID: message.ID,
+		RoomID:    message.RoomID,
+		AuthorID:  message.UserID,
+		Content:   message.Content,
+		Source:    models.ReportSourceFilter,
+		Reason:    strings.Join(reasons, "; "),
+	}
+	return report.CreateReport(db)
+}
+
+func ReportMessage(db *gorm.DB, req models.CreateReportRequest, roomId string, messageId int, userId string) (models.MessageReport, int, error) {
+	var (
+		userRoom models.UserRoom
+		message  models.Message
+	)
+
+	exists := postgresql.CheckExists(db, &userRoom, "room_id = ? AND user_id = ?", roomId, userId)
+	if !exists {
+		return models.MessageReport{}, http.StatusUnauthorized, errors.New("user not in room")
+	}
+
+	exists = postgresql.CheckExists(db, &message, "id = ? AND room_id = ?", messageId, roomId)
+	if !exists {
+		return models.MessageReport{}, http.StatusNotFound, errors.New("message not found")
+	}
+
+	exists = postgresql.CheckExists(db, &models.MessageReport{}, "message_id = ? AND reporter_id = ? AND status = ?", messageId, userId, models.ReportOpen)
+	if exists {
+		return models.MessageReport{}, http.StatusConflict, errors.New("message already reported")
+	}
+
+	report := models.MessageReport{
+		ID:         utility.GenerateUUID(),
+		MessageID:  message.ID,
+		RoomID:     roomId,
+		ReporterID: &userId,
+		AuthorID:   message.UserID,
+		Content:    message.Content,
+		Source:     models.ReportSourceUser,
+		Reason:     req.Reason,
+	}
+
+	err := report.CreateReport(db)
+	if err != nil {
+		return report, http.StatusInternalServerError, err
+	}
+	return report, http.StatusCreated, nil
+}
+
+func GetRoomReports(db *gorm.DB, roomId, userId, status string) ([]models.MessageReport, int, error) {
+	if code, err := requireModerator(db, roomId, userId); err != nil {
+		return nil, code, err
+	}
+
+	return GetModerationQueue(db, roomId, status)
+}
+
+// GetModerationQueue lists reports for one room, or for every room when roomId
+// is empty.
+func GetModerationQueue(db *gorm.DB, roomId, status string) ([]models.MessageReport, int, error) {
+	var report models.MessageReport
+
+	if status == "" {
+		status = models.ReportOpen
+	}
+
+	reports, err := report.GetModerationQueue(db, roomId, status)
+	if err != nil {
+		return reports, http.StatusInternalServerError, err
+	}
+	return reports, http.StatusOK, nil
+}
+
+func ResolveRoomReport(db *gorm.DB, req models.ResolveReportRequest, roomId, reportId, userId string) (models.MessageReport, int, error) {
+	if code, err := requireModerator(db, roomId, userId); err != nil {
+		return models.MessageReport{}, code, err
+	}
+
+	return ResolveReport(db, req, roomId, reportId, userId)
+}
+
+// ResolveReport closes an open report. A non-empty roomId restricts it to
+// reports from that room.
+func ResolveReport(db *gorm.DB, req models.ResolveReportRequest, roomId, reportId, userId string) (models.MessageReport, int, error) {
+	var report models.MessageReport
+
+	report, code, err := report.GetReportByID(db, reportId)
+	if err != nil {
+		return report, code, err
+	}
+
+	if roomId != "" && report.RoomID != roomId {
+		return report, http.StatusNotFound, errors.New("report not found")
+	}
+
+	if report.Status != models.ReportOpen {
+		return report, http.StatusConflict, errors.New("report already resolved")
+	}
+
+	err = report.Resolve(db, req, userId)
+	if err != nil {
+		return report, http.StatusInternalServerError, err
+	}
+
+	report, code, err = report.GetReportByID(db, reportId)
+	if err != nil {
+		return report, code, err
+	}
+	return report, http.StatusOK, nil
+}
+
+func GetWordFilters(db *gorm.DB, roomId, userId string) ([]models.RoomWordFilter, int, error) {
+	var filter models.RoomWordFilter
+
+	if code, err := requireModerator(db, roomId, userId); err != nil {
+		return nil, code, err
+	}
+
+	filters, err := filter.GetWordFiltersByRoomID(db, roomId)
+	if err != nil {
+		return filters, http.StatusInternalServerError, err
+	}
+	return filters, http.StatusOK, nil
+}
+
+func CreateWordFilter(db *gorm.DB, req models.CreateWordFilterRequest, roomId, userId string) (models.RoomWordFilter, int, error) {
+	if code, err := requireModerator(db, roomId, userId); err != nil {
+		return models.RoomWordFilter{}, code, err
+	}
+
+	filter := models.RoomWordFilter{
+		ID:        utility.GenerateUUID(),
+		RoomID:    roomId,
+		Word:      req.Word,
+		Action:    req.Action,
+		CreatedBy: userId,
+	}
+
+	err := filter.CreateWordFilter(db)
+	if err != nil {
+		return filter, http.StatusBadRequest, err
+	}
+
+	invalidateBlocklist(roomId)
+	return filter, http.StatusCreated, nil
+}
+
+func DeleteWordFilter(db *gorm.DB, roomId, filterId, userId string) (int, error) {
+	var filter models.RoomWordFilter
+
+	if code, err := requireModerator(db, roomId, userId); err != nil {
+		return code, err
+	}
+
+	code, err := filter.DeleteWordFilter(db, filterId, roomId)
+	if err != nil {
+		return code, err
+	}
+
+	invalidateBlocklist(roomId)
+	return code, nil
+}
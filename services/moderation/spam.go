@@ -0,0 +1,57 @@
+package moderation
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+)
+
+var (
+	defaultSpamThreshold = 3
+	defaultSpamWindow    = 60
+)
+
+// SpamFilter blocks a user who repeats the same message in a room more than
+// threshold times within the window.
+type SpamFilter struct {
+	redis     *redis.Client
+	threshold int
+	window    time.Duration
+}
+
+func NewSpamFilter(redisClient *redis.Client, threshold, windowSeconds int) *SpamFilter {
+	if threshold <= 0 {
+		threshold = defaultSpamThreshold
+	}
+	if windowSeconds <= 0 {
+		windowSeconds = defaultSpamWindow
+	}
+	return &SpamFilter{redis: redisClient, threshold: threshold, window: time.Duration(windowSeconds) * time.Second}
+}
+
+func (f *SpamFilter) Name() string {
+	return "spam"
+}
+
+func (f *SpamFilter) Check(in Input) (Verdict, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(in.Content)), " ")
+	sum := sha1.Sum([]byte(normalized))
+	key := fmt.Sprintf("spam:%v:%v:%v", in.RoomID, in.UserID, hex.EncodeToString(sum[:]))
+
+	count, _, err := rdb.IncrWindow(f.redis, key, f.window)
+	if err != nil {
+		// losing the counter should not stop people from talking
+		return Verdict{Action: Allow}, nil
+	}
+
+	if count > int64(f.threshold) {
+		return Verdict{Action: Block, Reason: "message repeated too many times"}, nil
+	}
+	return Verdict{Action: Allow}, nil
+}
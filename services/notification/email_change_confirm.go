@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/send"
+)
+
+func (n NotificationObject) SendEmailChangeConfirm() error {
+	var (
+		notificationData     = models.SendEmailChangeConfirm{}
+		templateFileName     = "email_change_confirm.html"
+		baseTemplateFileName = ""
+	)
+
+	err := json.Unmarshal([]byte(n.Notification.Data), &notificationData)
+	if err != nil {
+		return fmt.Errorf("error decoding saved notification data, %v", err)
+	}
+
+	subject := "Subject: Confirm your new Telex email address"
+
+	data, err := ConvertToMapAndAddExtraData(notificationData, map[string]interface{}{"firstname": thisOrThatStr(notificationData.FirstName, notificationData.Email)})
+	if err != nil {
+		return fmt.Errorf("error converting data to map, %v", err)
+	}
+
+	return send.SendEmail(n.ExtReq, notificationData.Email, subject, templateFileName, baseTemplateFileName, data)
+}
@@ -0,0 +1,33 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/services/send"
+)
+
+// SendWorkspaceInvite mails the invite link. The invitee may not have an
+// account yet, so the email is addressed to the invited address directly.
+func (n NotificationObject) SendWorkspaceInvite() error {
+	var (
+		notificationData     = models.SendWorkspaceInvite{}
+		templateFileName     = "workspace_invite.html"
+		baseTemplateFileName = ""
+	)
+
+	err := json.Unmarshal([]byte(n.Notification.Data), &notificationData)
+	if err != nil {
+		return fmt.Errorf("error decoding saved notification data, %v", err)
+	}
+
+	subject := fmt.Sprintf("Subject: You have been invited to %v on Telex", notificationData.WorkspaceName)
+
+	data, err := ConvertToMapAndAddExtraData(notificationData, map[string]interface{}{"firstname": notificationData.Email})
+	if err != nil {
+		return fmt.Errorf("error converting data to map, %v", err)
+	}
+
+	return send.SendEmail(n.ExtReq, notificationData.Email, subject, templateFileName, baseTemplateFileName, data)
+}
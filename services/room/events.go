@@ -0,0 +1,113 @@
+package room
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/external/external_models"
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+)
+
+// publishRoomActivity pushes system messages to the room's Centrifugo channel.
+// The change is already committed, so a failed publish is only logged.
+func publishRoomActivity(extReq request.ExternalRequest, activities ...models.RoomActivity) {
+	if config.GetConfig().Centrifuge.ApiUrl == "" && !extReq.Test {
+		return
+	}
+
+	for _, activity := range activities {
+		data := external_models.CentrifugoPublishRequest{
+			Channel: activity.Message.RoomID,
+			Data:    activity.Message,
+		}
+
+		_, err := extReq.SendExternalRequest(request.CentrifugoPublish, data)
+		if err != nil && extReq.Logger != nil {
+			extReq.Logger.Error("error publishing room activity: ", activity.Event.ID, err.Error())
+		}
+	}
+}
+
+func KickMember(extReq request.ExternalRequest, db *gorm.DB, roomId, memberId, userId string) (int, error) {
+	var room models.Room
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomId)
+	if !exists {
+		return http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	if memberId == userId {
+		return http.StatusBadRequest, errors.New("use leave to remove yourself from a room")
+	}
+
+	if memberId == room.OwnerId {
+		return http.StatusUnauthorized, errors.New("the room owner cannot be removed")
+	}
+
+	isModerator, err := room.IsRoomModerator(db, roomId, userId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if !isModerator {
+		return http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	// moderators can only remove members; removing a moderator is up to the owner
+	if userId != room.OwnerId {
+		targetIsModerator, err := room.IsRoomModerator(db, roomId, memberId)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if targetIsModerator {
+			return http.StatusUnauthorized, errors.New("user not authorized")
+		}
+	}
+
+	activity, err := room.RemoveUserFromRoom(db, roomId, memberId, userId)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	publishRoomActivity(extReq, activity)
+	return http.StatusOK, nil
+}
+
+func TransferOwnership(extReq request.ExternalRequest, db *gorm.DB, req models.TransferOwnershipRequest, roomId, userId string) (int, error) {
+	var room models.Room
+
+	activity, code, err := room.TransferOwnership(db, roomId, userId, req.UserID)
+	if err != nil {
+		return code, err
+	}
+
+	publishRoomActivity(extReq, activity)
+	return http.StatusOK, nil
+}
+
+func GetRoomEvents(db *gorm.DB, c *gin.Context, roomId, userId string) ([]models.RoomEvent, postgresql.PaginationResponse, int, error) {
+	var (
+		room  models.Room
+		event models.RoomEvent
+	)
+
+	exists := postgresql.CheckExists(db, &room, "id = ?", roomId)
+	if !exists {
+		return nil, postgresql.PaginationResponse{}, http.StatusNotFound, errors.New("room does not exist")
+	}
+
+	if room.OwnerId != userId {
+		return nil, postgresql.PaginationResponse{}, http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	events, paginationResponse, err := event.GetRoomEvents(db, c, roomId, c.Query("type"))
+	if err != nil {
+		return events, paginationResponse, http.StatusInternalServerError, err
+	}
+	return events, paginationResponse, http.StatusOK, nil
+}
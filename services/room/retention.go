@@ -0,0 +1,17 @@
+package room
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+)
+
+func UpdateRoomRetention(db *gorm.DB, req models.UpdateRoomRetentionRequest, roomId, userId string) (models.Room, int, error) {
+	var room models.Room
+
+	updatedRoom, code, err := room.UpdateRetention(db, req, roomId, userId)
+	if err != nil {
+		return updatedRoom, code, err
+	}
+	return updatedRoom, code, nil
+}
@@ -2,20 +2,25 @@ package room
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"gorm.io/gorm"
 
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/config"
 	"github.com/hngprojects/telex_be/internal/models"
 	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/services/moderation"
 	"github.com/hngprojects/telex_be/utility"
 )
 
-func GetRooms(db *gorm.DB) ([]models.Room, int, error) {
+func GetRooms(db *gorm.DB, workspaceId string) ([]models.Room, int, error) {
 	var room models.Room
 
-	rooms, err := room.GetRooms(db)
+	rooms, err := room.GetRooms(db, workspaceId)
 	if err != nil {
 		return rooms, http.StatusInternalServerError, err
 	}
@@ -31,6 +36,9 @@ func CreateRoom(req models.CreateRoomRequest, db *gorm.DB, userId string) (model
 		OwnerId:     userId,
 		Description: req.Description,
 	}
+	if req.WorkspaceID != "" {
+		room.WorkspaceID = &req.WorkspaceID
+	}
 
 	joinRoomReq.RoomID = room.ID
 	joinRoomReq.UserID = userId
@@ -41,7 +49,8 @@ func CreateRoom(req models.CreateRoomRequest, db *gorm.DB, userId string) (model
 		return room, http.StatusBadRequest, err
 	}
 
-	err = room.AddUserToRoom(db, joinRoomReq)
+	// nobody is subscribed to a brand new room, so the join is not published
+	_, err = room.AddUserToRoom(db, joinRoomReq)
 	if err != nil {
 		return room, http.StatusBadRequest, err
 	}
@@ -58,10 +67,10 @@ func GetRoom(db *gorm.DB, roomID string) (models.Room, int, error) {
 	return room, http.StatusOK, nil
 }
 
-func GetRoomByName(db *gorm.DB, name string) (models.Room, int, error) {
+func GetRoomByName(db *gorm.DB, workspaceId, name string) (models.Room, int, error) {
 	var r models.Room
 
-	room, err := r.GetRoomByName(db, name)
+	room, err := r.GetRoomByName(db, workspaceId, name)
 	if err != nil {
 		return room, http.StatusBadRequest, err
 	}
@@ -81,19 +90,20 @@ func GetRoomMsg(roomId, userID string, db *gorm.DB) ([]models.Message, int, erro
 
 }
 
-func JoinRoom(db *gorm.DB, req models.JoinRoomRequest) (int, error) {
+func JoinRoom(extReq request.ExternalRequest, db *gorm.DB, req models.JoinRoomRequest) (int, error) {
 	var room models.Room
 
-	err := room.AddUserToRoom(db, req)
+	activity, err := room.AddUserToRoom(db, req)
 
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
 
+	publishRoomActivity(extReq, activity)
 	return http.StatusOK, nil
 }
 
-func LeaveRoom(db *gorm.DB, room_id, user_id string) (int, error) {
+func LeaveRoom(extReq request.ExternalRequest, db *gorm.DB, room_id, user_id string) (int, error) {
 	var room models.Room
 
 	_, _, err := GetRoom(db, room_id)
@@ -101,40 +111,81 @@ func LeaveRoom(db *gorm.DB, room_id, user_id string) (int, error) {
 		return http.StatusBadRequest, errors.New("room does not exist")
 	}
 
-	err = room.RemoveUserFromRoom(db, room_id, user_id)
+	activity, err := room.RemoveUserFromRoom(db, room_id, user_id, user_id)
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
+
+	publishRoomActivity(extReq, activity)
 	return http.StatusOK, nil
 
 }
 
-func AddRoomMsg(req models.CreateMessageRequest, db *gorm.DB) (int, error) {
+// AddRoomMsg stores a message after applying the room's posting limits and
+// the moderation filters. redisClient is required; the posting limits and
+// spam filter keep their counters there.
+func AddRoomMsg(req models.CreateMessageRequest, db *gorm.DB, redisClient *redis.Client, logger *utility.Logger) (int, error) {
+	var userRoom models.UserRoom
 
-	message := models.Message{
+	inRoom, msg := userRoom.CheckUser(db, req.UserId, req.RoomId)
+	if !inRoom {
+		return http.StatusBadRequest, errors.New(msg)
+	}
+
+	if err := checkPostingLimit(db, redisClient, req.RoomId, req.UserId); err != nil {
+		var limitErr *PostingLimitError
+		if errors.As(err, &limitErr) {
+			return http.StatusTooManyRequests, err
+		}
+		return http.StatusBadRequest, err
+	}
+
+	result, err := moderation.NewChain(config.GetConfig().Moderation, redisClient).Run(moderation.Input{
+		Db:      db,
+		RoomID:  req.RoomId,
+		UserID:  req.UserId,
 		Content: req.Content,
+	})
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if result.Blocked {
+		return http.StatusUnprocessableEntity, fmt.Errorf("message blocked: %v", result.Reason)
+	}
+
+	message := models.Message{
+		Content: result.Content,
 		RoomID:  req.RoomId,
 		UserID:  req.UserId,
 	}
 
-	err := message.CreateMessage(db)
+	err = message.CreateMessage(db)
 
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
 
+	if len(result.Flags) > 0 {
+		// The message is already posted; a missing report should not turn
+		// that into a failure for the author.
+		if err := moderation.FlagMessage(db, message, result.Flags); err != nil {
+			logger.Error("error flagging message: ", message.ID, err.Error())
+		}
+	}
+
 	return http.StatusCreated, nil
 }
 
-func UpdateUsername(req models.UpdateRoomUserNameReq, db *gorm.DB, roomId, userId string) (int, error) {
+func UpdateUsername(extReq request.ExternalRequest, req models.UpdateRoomUserNameReq, db *gorm.DB, roomId, userId string) (int, error) {
 
 	var userroom models.UserRoom
 
-	err := userroom.UpdateUsername(db, req, roomId, userId)
+	activity, err := userroom.UpdateUsername(db, req, roomId, userId)
 	if err != nil {
 		return http.StatusBadRequest, err
 	}
 
+	publishRoomActivity(extReq, activity)
 	return http.StatusOK, nil
 }
 
@@ -169,14 +220,16 @@ func CountRoomUsers(db *gorm.DB, roomId string) (int64, int, error) {
 	return count, http.StatusOK, nil
 }
 
-func UpdateRoom(db *gorm.DB, req models.UpdateRoomRequest, roomId string, userId string) (models.Room, error) {
+func UpdateRoom(extReq request.ExternalRequest, db *gorm.DB, req models.UpdateRoomRequest, roomId string, userId string) (models.Room, error) {
 	var (
 		room models.Room
 	)
-	updatedRoom, _, err := room.UpdateRoom(db, req, roomId, userId)
+	updatedRoom, activities, _, err := room.UpdateRoom(db, req, roomId, userId)
 	if err != nil {
 		return updatedRoom, err
 	}
+
+	publishRoomActivity(extReq, activities...)
 	return updatedRoom, nil
 }
 
@@ -196,11 +249,11 @@ func CheckUser(roomId, userID string, db *gorm.DB) (gin.H, int, error) {
 	return resp, http.StatusOK, nil
 }
 
-func SearchRoomByNames(db *gorm.DB, c *gin.Context, name string) ([]models.Room, postgresql.PaginationResponse, error) {
+func SearchRoomByNames(db *gorm.DB, c *gin.Context, workspaceId, name string) ([]models.Room, postgresql.PaginationResponse, error) {
 	var (
 		room models.Room
 	)
-	rooms, paginationResponse, err := room.SearchRoomsByName(db, c, name)
+	rooms, paginationResponse, err := room.SearchRoomsByName(db, c, workspaceId, name)
 
 	if err != nil {
 		return rooms, paginationResponse, err
@@ -0,0 +1,125 @@
+package room
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func CreateScheduledMessage(req models.CreateScheduledMessageRequest, db *gorm.DB, roomId, userId string) (models.ScheduledMessage, int, error) {
+	if !req.SendAt.After(time.Now()) {
+		return models.ScheduledMessage{}, http.StatusBadRequest, errors.New("send_at must be in the future")
+	}
+
+	scheduled := models.ScheduledMessage{
+		ID:      utility.GenerateUUID(),
+		Content: req.Content,
+		RoomID:  roomId,
+		UserID:  userId,
+		SendAt:  req.SendAt.UTC(),
+	}
+
+	err := scheduled.CreateScheduledMessage(db)
+	if err != nil {
+		return scheduled, http.StatusBadRequest, err
+	}
+
+	return scheduled, http.StatusCreated, nil
+}
+
+func GetScheduledMessages(db *gorm.DB, roomId, userId string) ([]models.ScheduledMessage, int, error) {
+	var scheduled models.ScheduledMessage
+
+	messages, err := scheduled.GetScheduledMessagesByRoomID(db, userId, roomId)
+	if err != nil {
+		return messages, http.StatusInternalServerError, err
+	}
+
+	return messages, http.StatusOK, nil
+}
+
+func UpdateScheduledMessage(req models.UpdateScheduledMessageRequest, db *gorm.DB, id, roomId, userId string) (models.ScheduledMessage, int, error) {
+	var scheduled models.ScheduledMessage
+
+	scheduled, code, err := scheduled.GetScheduledMessageByID(db, id, roomId, userId)
+	if err != nil {
+		return scheduled, code, err
+	}
+
+	if req.Content != "" {
+		scheduled.Content = req.Content
+	}
+
+	if req.SendAt != nil {
+		if !req.SendAt.After(time.Now()) {
+			return scheduled, http.StatusBadRequest, errors.New("send_at must be in the future")
+		}
+		scheduled.SendAt = req.SendAt.UTC()
+	}
+
+	updated, err := scheduled.UpdatePending(db)
+	if err != nil {
+		return scheduled, http.StatusInternalServerError, err
+	}
+	if !updated {
+		return scheduled, http.StatusConflict, errors.New("scheduled message is no longer pending")
+	}
+
+	return scheduled, http.StatusOK, nil
+}
+
+func CancelScheduledMessage(db *gorm.DB, id, roomId, userId string) (int, error) {
+	var scheduled models.ScheduledMessage
+
+	scheduled, code, err := scheduled.GetScheduledMessageByID(db, id, roomId, userId)
+	if err != nil {
+		return code, err
+	}
+
+	err = scheduled.SetStatus(db, models.ScheduledMessageCancelled, "cancelled by author")
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// SendScheduledMessage posts a claimed scheduled message through AddRoomMsg,
+// cancelling it instead if the author is no longer a member of the room.
+// Slow mode applies as it would to the author posting by hand, so
+// scheduling cannot be used to get around it; a message over the limit is
+// put back until the author may post again.
+func SendScheduledMessage(db *gorm.DB, redisClient *redis.Client, logger *utility.Logger, scheduled models.ScheduledMessage) error {
+	var userRoom models.UserRoom
+
+	inRoom, _ := userRoom.CheckUser(db, scheduled.UserID, scheduled.RoomID)
+	if !inRoom {
+		return scheduled.SetStatus(db, models.ScheduledMessageCancelled, "author is no longer in the room")
+	}
+
+	req := models.CreateMessageRequest{
+		Content: scheduled.Content,
+		RoomId:  scheduled.RoomID,
+		UserId:  scheduled.UserID,
+	}
+
+	_, err := AddRoomMsg(req, db, redisClient, logger)
+	var limitErr *PostingLimitError
+	if errors.As(err, &limitErr) {
+		return scheduled.Reschedule(db, time.Now().Add(limitErr.RetryAfter))
+	}
+	if err != nil {
+		if statusErr := scheduled.SetStatus(db, models.ScheduledMessageCancelled, err.Error()); statusErr != nil {
+			return statusErr
+		}
+		return err
+	}
+
+	return scheduled.SetStatus(db, models.ScheduledMessageSent, "")
+}
@@ -0,0 +1,90 @@
+package room
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+)
+
+// PostingLimitError is returned by AddRoomMsg when the sender has used up the
+// room's slow mode allowance.
+type PostingLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PostingLimitError) Error() string {
+	return fmt.Sprintf("slow mode is enabled, try again in %d seconds", e.RetryAfterSeconds())
+}
+
+func (e *PostingLimitError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// checkPostingLimit allows SlowModeBurst messages per user in every
+// SlowModeInterval window. Counters live in Redis so the limit holds across
+// instances; if Redis is unavailable the message is let through.
+func checkPostingLimit(db *gorm.DB, redisClient *redis.Client, roomID, userID string) error {
+	var room models.Room
+
+	err := db.Select("id", "slow_mode_interval", "slow_mode_burst").First(&room, "id = ?", roomID).Error
+	if err != nil {
+		return errors.New("room not found")
+	}
+
+	if room.SlowModeInterval <= 0 {
+		return nil
+	}
+
+	isModerator, err := room.IsRoomModerator(db, roomID, userID)
+	if err != nil || isModerator {
+		return err
+	}
+
+	var (
+		key    = fmt.Sprintf("slowmode:%v:%v", roomID, userID)
+		window = time.Duration(room.SlowModeInterval) * time.Second
+		burst  = room.SlowModeBurst
+	)
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	count, ttl, err := rdb.IncrWindow(redisClient, key, window)
+	if err != nil {
+		return nil
+	}
+
+	if count > int64(burst) {
+		return &PostingLimitError{RetryAfter: ttl}
+	}
+	return nil
+}
+
+func UpdateSlowMode(db *gorm.DB, req models.UpdateSlowModeRequest, roomId, userId string) (models.Room, int, error) {
+	var room models.Room
+
+	updatedRoom, code, err := room.UpdateSlowMode(db, req, roomId, userId)
+	if err != nil {
+		return updatedRoom, code, err
+	}
+	return updatedRoom, http.StatusOK, nil
+}
+
+func UpdateMemberRole(db *gorm.DB, req models.UpdateMemberRoleRequest, roomId, memberId, userId string) (models.UserRoom, int, error) {
+	var userRoom models.UserRoom
+
+	member, code, err := userRoom.UpdateRole(db, req, roomId, memberId, userId)
+	if err != nil {
+		return member, code, err
+	}
+	return member, http.StatusOK, nil
+}
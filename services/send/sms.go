@@ -0,0 +1,51 @@
+package send
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// SMSSender delivers a text message to a number in E.164 form.
+type SMSSender interface {
+	SendSMS(to, body string) error
+}
+
+// LogSMSSender writes messages to the log instead of sending them.
+type LogSMSSender struct {
+	Logger *utility.Logger
+}
+
+func (s LogSMSSender) SendSMS(to, body string) error {
+	s.Logger.Info(fmt.Sprintf("sms to %v: %v", to, body))
+	return nil
+}
+
+var smsSender SMSSender
+
+// NewSMSSender returns the driver named in the SMS config. The log driver
+// has to be asked for, so a missing setting never leaks codes to the log.
+func NewSMSSender(logger *utility.Logger) (SMSSender, error) {
+	switch driver := config.GetConfig().SMS.Driver; driver {
+	case "":
+		return nil, errors.New("no sms driver configured")
+	case "log":
+		return LogSMSSender{Logger: logger}, nil
+	default:
+		return nil, fmt.Errorf("unknown sms driver %v", driver)
+	}
+}
+
+// UseSMSSender sets the driver SendSMS delivers through.
+func UseSMSSender(sender SMSSender) {
+	smsSender = sender
+}
+
+func SendSMS(to, body string) error {
+	if smsSender == nil {
+		return errors.New("no sms driver configured")
+	}
+	return smsSender.SendSMS(to, body)
+}
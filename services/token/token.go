@@ -8,20 +8,19 @@ import (
 	"github.com/golang-jwt/jwt"
 	"gorm.io/gorm"
 
-	"github.com/hngprojects/telex_be/internal/config"
 	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
 )
 
 func GetConnToken(userId string, db *gorm.DB) (gin.H, int, error) {
 
 	userClaims := jwt.MapClaims{}
 
+	userClaims["aud"] = middleware.CentrifugoAudience
 	userClaims["sub"] = userId
 	userClaims["exp"] = time.Now().Unix() + int64(120)
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims)
-
-	connToken, err := token.SignedString([]byte(config.Config.Centrifuge.Secret))
+	connToken, err := middleware.SignToken(userClaims)
 	if err != nil {
 		return gin.H{}, http.StatusInternalServerError, err
 	}
@@ -42,13 +41,12 @@ func GetSubToken(userId string, req models.ChannelSubTokenReq, db *gorm.DB) (gin
 
 	userClaims := jwt.MapClaims{}
 
+	userClaims["aud"] = middleware.CentrifugoAudience
 	userClaims["sub"] = userId
 	userClaims["channel"] = channelName
 	userClaims["exp"] = time.Now().Unix() + int64(300)
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, userClaims)
-
-	subToken, err := token.SignedString([]byte(config.Config.Centrifuge.Secret))
+	subToken, err := middleware.SignToken(userClaims)
 	if err != nil {
 		return gin.H{}, http.StatusInternalServerError, err
 	}
@@ -0,0 +1,102 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/services/auth"
+)
+
+// deletionGracePeriod is how long a deleted account can still be restored.
+const deletionGracePeriod = 30 * 24 * time.Hour
+
+// DeleteMe schedules the account for erasure after deletionGracePeriod. The
+// user keeps access until then so they can change their mind.
+func DeleteMe(db *gorm.DB, rdb *redis.Client, req models.ReauthRequest, userId, accessUuid, ip string) (models.User, int, error) {
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return user, code, err
+	}
+
+	if user.DeleteAfter != nil {
+		return user, http.StatusConflict, errors.New("account is already scheduled for deletion")
+	}
+
+	code, err = auth.Reauthenticate(db, user, accessUuid, req.Password, ip)
+	if err != nil {
+		return user, code, err
+	}
+
+	err = user.ScheduleDeletion(db, time.Now().Add(deletionGracePeriod))
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	notification := models.SendAccountDeletion{
+		Email:       user.Email,
+		DeleteAfter: user.DeleteAfter.UTC().Format(time.RFC1123),
+	}
+
+	err = actions.AddNotificationToQueue(rdb, names.SendAccountDeletion, notification)
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	return user, http.StatusAccepted, nil
+}
+
+func RestoreMe(db *gorm.DB, userId string) (models.User, int, error) {
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return user, code, err
+	}
+
+	if user.DeleteAfter == nil {
+		return user, http.StatusBadRequest, errors.New("account is not scheduled for deletion")
+	}
+
+	err = user.CancelDeletion(db)
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+	return user, http.StatusOK, nil
+}
+
+// PurgeAccount erases an account whose grace period has ended, along with the
+// files stored for it.
+func PurgeAccount(db *gorm.DB, user models.User) error {
+	var profile models.Profile
+
+	exports, err := models.GetUserAccountExports(db, user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = db.Where("userid = ?", user.ID).Limit(1).Find(&profile).Error
+	if err != nil {
+		return err
+	}
+
+	err = user.EraseAccount(db)
+	if err != nil {
+		return err
+	}
+	middleware.InvalidateSessionCache(user.ID)
+
+	for _, export := range exports {
+		if export.FilePath != "" {
+			_ = os.Remove(export.FilePath)
+		}
+	}
+	removeAvatar(profile.AvatarURL)
+	return nil
+}
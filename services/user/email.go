@@ -0,0 +1,201 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+const (
+	emailChangeLifetime = 24 * time.Hour
+	// emailRevertLifetime gives the old address time to undo a change even
+	// after it has been confirmed.
+	emailRevertLifetime = 7 * 24 * time.Hour
+)
+
+// RequestEmailChange starts moving the account to req.NewEmail. The new
+// address gets a confirmation link and the old one a notice it can revert
+// the change from; the address only switches once confirmed.
+func RequestEmailChange(db *gorm.DB, rdb *redis.Client, req models.ChangeEmailRequest, userId, accessUuid, ip string) (models.EmailChange, int, error) {
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return models.EmailChange{}, code, err
+	}
+
+	if newEmail == strings.ToLower(user.Email) {
+		return models.EmailChange{}, http.StatusBadRequest, errors.New("new email is the same as the current one")
+	}
+
+	code, err = auth.Reauthenticate(db, user, accessUuid, req.Password, ip)
+	if err != nil {
+		return models.EmailChange{}, code, err
+	}
+
+	if models.EmailTaken(db, newEmail, userId) {
+		return models.EmailChange{}, http.StatusConflict, errors.New("email is already in use")
+	}
+
+	confirmToken, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		return models.EmailChange{}, http.StatusInternalServerError, err
+	}
+	revertToken, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		return models.EmailChange{}, http.StatusInternalServerError, err
+	}
+
+	now := time.Now()
+	change := models.EmailChange{
+		ID:               utility.GenerateUUID(),
+		UserID:           userId,
+		OldEmail:         user.Email,
+		NewEmail:         newEmail,
+		ConfirmTokenHash: utility.HashToken(confirmToken),
+		RevertTokenHash:  utility.HashToken(revertToken),
+		ExpiresAt:        now.Add(emailChangeLifetime),
+		RevertExpiresAt:  now.Add(emailRevertLifetime),
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := models.CancelPendingEmailChanges(tx, userId); err != nil {
+			return err
+		}
+		return change.CreateEmailChange(tx)
+	})
+	if err != nil {
+		return change, http.StatusInternalServerError, err
+	}
+
+	appUrl := strings.TrimRight(config.GetConfig().App.Url, "/")
+
+	confirmReq := models.SendEmailChangeConfirm{
+		Email:       newEmail,
+		FirstName:   user.Profile.FirstName,
+		ConfirmLink: fmt.Sprintf("%v/settings/email/confirm?token=%v", appUrl, confirmToken),
+		ExpiresAt:   change.ExpiresAt.UTC().Format(time.RFC1123),
+	}
+
+	err = actions.AddNotificationToQueue(rdb, names.SendEmailChangeConfirm, confirmReq)
+	if err != nil {
+		return change, http.StatusInternalServerError, err
+	}
+
+	noticeReq := models.SendEmailChangeNotice{
+		Email:      user.Email,
+		FirstName:  user.Profile.FirstName,
+		NewEmail:   newEmail,
+		RevertLink: fmt.Sprintf("%v/settings/email/revert?token=%v", appUrl, revertToken),
+		ExpiresAt:  change.RevertExpiresAt.UTC().Format(time.RFC1123),
+	}
+
+	err = actions.AddNotificationToQueue(rdb, names.SendEmailChangeNotice, noticeReq)
+	if err != nil {
+		return change, http.StatusInternalServerError, err
+	}
+
+	return change, http.StatusAccepted, nil
+}
+
+// ConfirmEmailChange switches the account to the new address and signs out
+// every other session.
+func ConfirmEmailChange(db *gorm.DB, req models.EmailChangeTokenRequest, userId, accessUuid string) (models.User, int, error) {
+	var change models.EmailChange
+
+	code, err := change.GetPendingByConfirmToken(db, userId, utility.HashToken(req.Token))
+	if err != nil {
+		return models.User{}, code, err
+	}
+
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return user, code, err
+	}
+
+	if models.EmailTaken(db, change.NewEmail, userId) {
+		return user, http.StatusConflict, errors.New("email is already in use")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := user.SetEmail(tx, change.NewEmail); err != nil {
+			return err
+		}
+		return change.MarkConfirmed(tx)
+	})
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	_, code, err = auth.RevokeOtherSessions(db, userId, accessUuid)
+	if err != nil {
+		return user, code, err
+	}
+
+	return user, http.StatusOK, nil
+}
+
+// RevertEmailChange is used from the old address. A pending change is simply
+// cancelled; a confirmed one is undone and every session is signed out, since
+// whoever made it may still be signed in.
+func RevertEmailChange(db *gorm.DB, req models.EmailChangeTokenRequest) (string, int, error) {
+	var (
+		change models.EmailChange
+		token  models.AccessToken
+	)
+
+	code, err := change.GetByRevertToken(db, utility.HashToken(req.Token))
+	if err != nil {
+		return "", code, err
+	}
+
+	if change.ConfirmedAt == nil {
+		err = change.MarkReverted(db)
+		if err != nil {
+			return "", http.StatusInternalServerError, err
+		}
+		return "email change cancelled", http.StatusOK, nil
+	}
+
+	user, code, err := getUser(db, change.UserID)
+	if err != nil {
+		return "", code, err
+	}
+
+	if models.EmailTaken(db, change.OldEmail, user.ID) {
+		return "", http.StatusConflict, errors.New("the previous email is now used by another account")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := user.SetEmail(tx, change.OldEmail); err != nil {
+			return err
+		}
+		if err := change.MarkReverted(tx); err != nil {
+			return err
+		}
+		if err := models.CancelPendingEmailChanges(tx, user.ID); err != nil {
+			return err
+		}
+		_, err := token.RevokeAllSessions(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	middleware.InvalidateSessionCache(user.ID)
+
+	return "email change reverted", http.StatusOK, nil
+}
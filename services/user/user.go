@@ -0,0 +1,233 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/config"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+const (
+	// avatarSize is the side of the square every avatar is stored at.
+	avatarSize = 256
+	// maxAvatarBytes caps uploads before they are decoded.
+	maxAvatarBytes = 5 << 20
+)
+
+// AvatarDir is where processed avatars are written and served from.
+func AvatarDir() string {
+	basePath := config.GetConfig().Storage.LocalPath
+	if basePath == "" {
+		basePath = "./storage"
+	}
+	return filepath.Join(basePath, "avatars")
+}
+
+func avatarURLPrefix() string {
+	return fmt.Sprintf("%v/api/v1/avatars/", strings.TrimRight(config.GetConfig().App.Url, "/"))
+}
+
+func getUser(db *gorm.DB, userId string) (models.User, int, error) {
+	var user models.User
+
+	user, err := user.GetUserWithProfile(db, userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, http.StatusNotFound, errors.New("user not found")
+		}
+		return user, http.StatusInternalServerError, err
+	}
+	return user, http.StatusOK, nil
+}
+
+// ensureProfile creates an empty profile for accounts made without one, so
+// profile updates have a row to land on.
+func ensureProfile(db *gorm.DB, user models.User) error {
+	if user.Profile.ID != "" {
+		return nil
+	}
+
+	profile := models.Profile{ID: utility.GenerateUUID(), Userid: user.ID}
+	return postgresql.CreateOneRecord(db, &profile)
+}
+
+func GetMe(db *gorm.DB, userId string) (models.User, int, error) {
+	return getUser(db, userId)
+}
+
+// UpdateMe applies a partial profile update. Usernames and phone numbers
+// must stay unique across users.
+func UpdateMe(db *gorm.DB, req models.UpdateUserRequestModel, userId string) (models.User, int, error) {
+	var (
+		userUpdates    = map[string]interface{}{}
+		profileUpdates = map[string]interface{}{}
+	)
+
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return user, code, err
+	}
+
+	if req.UserName != nil {
+		username := strings.ToLower(strings.TrimSpace(*req.UserName))
+		if username != user.Name {
+			if models.UsernameTaken(db, username, userId) {
+				return user, http.StatusConflict, errors.New("username is already taken")
+			}
+			userUpdates["name"] = username
+		}
+	}
+
+	if req.FirstName != nil {
+		profileUpdates["first_name"] = strings.Title(strings.ToLower(strings.TrimSpace(*req.FirstName)))
+	}
+	if req.LastName != nil {
+		profileUpdates["last_name"] = strings.Title(strings.ToLower(strings.TrimSpace(*req.LastName)))
+	}
+
+	if req.PhoneNumber != nil {
+		phone := strings.TrimSpace(*req.PhoneNumber)
+		phoneE164 := ""
+		if phone != "" {
+			formatted, ok := utility.PhoneValid(phone)
+			if !ok {
+				return user, http.StatusBadRequest, errors.New("phone number is invalid")
+			}
+			if models.PhoneTaken(db, formatted, userId) {
+				return user, http.StatusConflict, errors.New("phone number is already in use")
+			}
+			phoneE164, _ = utility.PhoneE164(phone)
+			phone = formatted
+		}
+		profileUpdates["phone"] = phone
+
+		// a new number has to be verified again before it can sign in
+		if phoneE164 != user.Profile.PhoneE164 {
+			profileUpdates["phone_e164"] = phoneE164
+			profileUpdates["phone_verified_at"] = nil
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureProfile(tx, user); err != nil {
+			return err
+		}
+		if len(userUpdates) > 0 {
+			if err := tx.Model(&models.User{}).Where("id = ?", userId).Updates(userUpdates).Error; err != nil {
+				return err
+			}
+		}
+		if len(profileUpdates) > 0 {
+			return user.Profile.UpdateByUserID(tx, userId, profileUpdates)
+		}
+		return nil
+	})
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	return getUser(db, userId)
+}
+
+// UploadAvatar crops the upload to a square, scales it to avatarSize and
+// stores it as PNG. Without a crop size the largest centred square is used.
+func UploadAvatar(db *gorm.DB, upload *multipart.FileHeader, crop models.AvatarCropRequest, userId string) (models.User, int, error) {
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return user, code, err
+	}
+
+	if upload.Size > maxAvatarBytes {
+		return user, http.StatusRequestEntityTooLarge, errors.New("avatar must be 5MB or smaller")
+	}
+
+	file, err := upload.Open()
+	if err != nil {
+		return user, http.StatusBadRequest, err
+	}
+	defer file.Close()
+
+	img, err := utility.DecodeImage(file)
+	if err != nil {
+		if errors.Is(err, utility.ErrImageTooLarge) {
+			return user, http.StatusBadRequest, err
+		}
+		return user, http.StatusBadRequest, errors.New("avatar must be a GIF, JPEG, PNG or WebP image")
+	}
+
+	area := utility.CenterSquare(img.Bounds())
+	if crop.Size > 0 {
+		origin := img.Bounds().Min.Add(image.Pt(crop.X, crop.Y))
+		area = image.Rectangle{Min: origin, Max: origin.Add(image.Pt(crop.Size, crop.Size))}
+	}
+
+	avatar, err := utility.CropAndResize(img, area, avatarSize)
+	if err != nil {
+		return user, http.StatusBadRequest, err
+	}
+
+	suffix, err := utility.GenerateSecureToken(8)
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+	name := fmt.Sprintf("%v-%v.png", userId, suffix)
+
+	if err := os.MkdirAll(AvatarDir(), 0o750); err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	out, err := os.Create(filepath.Join(AvatarDir(), name))
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+	err = utility.EncodePNG(out, avatar)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	err = ensureProfile(db, user)
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	err = user.Profile.UpdateByUserID(db, userId, map[string]interface{}{"avatar_url": avatarURLPrefix() + name})
+	if err != nil {
+		return user, http.StatusInternalServerError, err
+	}
+
+	removeAvatar(user.Profile.AvatarURL)
+
+	return getUser(db, userId)
+}
+
+// removeAvatar deletes a replaced avatar if it was one of ours. Avatars from
+// login providers are left alone.
+func removeAvatar(avatarURL string) {
+	if !strings.HasPrefix(avatarURL, avatarURLPrefix()) {
+		return
+	}
+	name := filepath.Base(strings.TrimPrefix(avatarURL, avatarURLPrefix()))
+	_ = os.Remove(filepath.Join(AvatarDir(), name))
+}
+
+func GetPublicProfile(db *gorm.DB, userId string) (models.PublicProfile, int, error) {
+	user, code, err := getUser(db, userId)
+	if err != nil {
+		return models.PublicProfile{}, code, err
+	}
+	return user.PublicProfile(), http.StatusOK, nil
+}
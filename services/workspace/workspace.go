@@ -0,0 +1,336 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
+	"github.com/hngprojects/telex_be/services/actions"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+const inviteLifetime = 7 * 24 * time.Hour
+
+// requireRole checks that userId belongs to the workspace with one of roles,
+// or with any role when none are given, and returns their role.
+func requireRole(db *gorm.DB, workspaceId, userId string, roles ...string) (string, int, error) {
+	var member models.WorkspaceMember
+
+	role, err := member.GetMemberRole(db, workspaceId, userId)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if role == "" {
+		return "", http.StatusNotFound, errors.New("workspace not found")
+	}
+
+	if len(roles) == 0 {
+		return role, http.StatusOK, nil
+	}
+	for _, r := range roles {
+		if r == role {
+			return role, http.StatusOK, nil
+		}
+	}
+	return role, http.StatusUnauthorized, errors.New("user not authorized")
+}
+
+func CreateWorkspace(db *gorm.DB, req models.CreateWorkspaceRequest, userId string) (models.Workspace, int, error) {
+	workspace := models.Workspace{
+		ID:          utility.GenerateUUID(),
+		Name:        req.Name,
+		Description: req.Description,
+		OwnerID:     userId,
+	}
+
+	err := workspace.CreateWorkspace(db)
+	if err != nil {
+		return workspace, http.StatusInternalServerError, err
+	}
+
+	workspace.Domains = []models.WorkspaceDomain{}
+	workspace.Role = models.WorkspaceRoleOwner
+	return workspace, http.StatusCreated, nil
+}
+
+func GetWorkspaces(db *gorm.DB, userId string) ([]models.Workspace, int, error) {
+	var workspace models.Workspace
+
+	workspaces, err := workspace.GetUserWorkspaces(db, userId)
+	if err != nil {
+		return workspaces, http.StatusInternalServerError, err
+	}
+	return workspaces, http.StatusOK, nil
+}
+
+func GetWorkspace(db *gorm.DB, workspaceId, userId string) (models.Workspace, int, error) {
+	var workspace models.Workspace
+
+	role, code, err := requireRole(db, workspaceId, userId)
+	if err != nil {
+		return workspace, code, err
+	}
+
+	workspace, code, err = workspace.GetWorkspaceByID(db, workspaceId)
+	if err != nil {
+		return workspace, code, err
+	}
+
+	workspace.Role = role
+	return workspace, http.StatusOK, nil
+}
+
+func UpdateWorkspace(db *gorm.DB, req models.UpdateWorkspaceRequest, workspaceId, userId string) (models.Workspace, int, error) {
+	workspace := models.Workspace{ID: workspaceId}
+
+	_, code, err := requireRole(db, workspaceId, userId, models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return workspace, code, err
+	}
+
+	err = workspace.UpdateWorkspace(db, req)
+	if err != nil {
+		return workspace, http.StatusInternalServerError, err
+	}
+
+	return GetWorkspace(db, workspaceId, userId)
+}
+
+func GetMembers(db *gorm.DB, workspaceId, userId string) ([]models.WorkspaceMember, int, error) {
+	var member models.WorkspaceMember
+
+	_, code, err := requireRole(db, workspaceId, userId)
+	if err != nil {
+		return nil, code, err
+	}
+
+	members, err := member.GetWorkspaceMembers(db, workspaceId)
+	if err != nil {
+		return members, http.StatusInternalServerError, err
+	}
+	return members, http.StatusOK, nil
+}
+
+// UpdateMemberRole promotes or demotes a member. The owner's role is fixed,
+// and only the owner can change another admin.
+func UpdateMemberRole(db *gorm.DB, req models.UpdateWorkspaceMemberRequest, workspaceId, memberId, userId string) (int, error) {
+	var member models.WorkspaceMember
+
+	role, code, err := requireRole(db, workspaceId, userId, models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return code, err
+	}
+
+	targetRole, err := member.GetMemberRole(db, workspaceId, memberId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if targetRole == "" {
+		return http.StatusNotFound, errors.New("user not in workspace")
+	}
+
+	if targetRole == models.WorkspaceRoleOwner {
+		return http.StatusBadRequest, errors.New("the workspace owner's role cannot be changed")
+	}
+	if targetRole == models.WorkspaceRoleAdmin && role != models.WorkspaceRoleOwner {
+		return http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	return member.UpdateRole(db, workspaceId, memberId, req.Role)
+}
+
+// RemoveMember removes memberId from the workspace. Members may remove
+// themselves; otherwise the same rules as UpdateMemberRole apply.
+func RemoveMember(db *gorm.DB, workspaceId, memberId, userId string) (int, error) {
+	var member models.WorkspaceMember
+
+	role, code, err := requireRole(db, workspaceId, userId)
+	if err != nil {
+		return code, err
+	}
+
+	if memberId == userId {
+		if role == models.WorkspaceRoleOwner {
+			return http.StatusBadRequest, errors.New("the workspace owner cannot leave the workspace")
+		}
+		return member.RemoveMember(db, workspaceId, memberId)
+	}
+
+	if role != models.WorkspaceRoleOwner && role != models.WorkspaceRoleAdmin {
+		return http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	targetRole, err := member.GetMemberRole(db, workspaceId, memberId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if targetRole == "" {
+		return http.StatusNotFound, errors.New("user not in workspace")
+	}
+	if targetRole == models.WorkspaceRoleOwner || (targetRole == models.WorkspaceRoleAdmin && role != models.WorkspaceRoleOwner) {
+		return http.StatusUnauthorized, errors.New("user not authorized")
+	}
+
+	return member.RemoveMember(db, workspaceId, memberId)
+}
+
+func CreateInvite(db *gorm.DB, rdb *redis.Client, req models.CreateWorkspaceInviteRequest, workspaceId, userId, url string) (models.WorkspaceInvite, int, error) {
+	var (
+		workspace models.Workspace
+		user      models.User
+	)
+
+	_, code, err := requireRole(db, workspaceId, userId, models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return models.WorkspaceInvite{}, code, err
+	}
+
+	email := strings.ToLower(req.Email)
+
+	exists := postgresql.CheckExists(db, &user, "email = ?", email)
+	if exists && postgresql.CheckExists(db, &models.WorkspaceMember{}, "workspace_id = ? AND user_id = ?", workspaceId, user.ID) {
+		return models.WorkspaceInvite{}, http.StatusConflict, errors.New("user already in workspace")
+	}
+
+	workspace, code, err = workspace.GetWorkspaceByID(db, workspaceId)
+	if err != nil {
+		return models.WorkspaceInvite{}, code, err
+	}
+
+	token, err := utility.GenerateSecureToken(32)
+	if err != nil {
+		return models.WorkspaceInvite{}, http.StatusInternalServerError, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.WorkspaceRoleMember
+	}
+
+	invite := models.WorkspaceInvite{
+		ID:          utility.GenerateUUID(),
+		WorkspaceID: workspaceId,
+		Email:       email,
+		Role:        role,
+		Token:       token,
+		InvitedBy:   userId,
+		ExpiresAt:   time.Now().Add(inviteLifetime),
+	}
+
+	err = invite.CreateInvite(db)
+	if err != nil {
+		return invite, http.StatusInternalServerError, err
+	}
+
+	inviteReq := models.SendWorkspaceInvite{
+		Email:         email,
+		WorkspaceName: workspace.Name,
+		InviteLink:    fmt.Sprintf("%vworkspaces/%v/invites/accept?token=%v", url, workspaceId, token),
+		ExpiresAt:     invite.ExpiresAt.Format(time.RFC1123),
+	}
+
+	err = actions.AddNotificationToQueue(rdb, names.SendWorkspaceInvite, inviteReq)
+	if err != nil {
+		return invite, http.StatusInternalServerError, err
+	}
+
+	return invite, http.StatusCreated, nil
+}
+
+func GetInvites(db *gorm.DB, workspaceId, userId string) ([]models.WorkspaceInvite, int, error) {
+	var invite models.WorkspaceInvite
+
+	_, code, err := requireRole(db, workspaceId, userId, models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return nil, code, err
+	}
+
+	invites, err := invite.GetPendingInvites(db, workspaceId)
+	if err != nil {
+		return invites, http.StatusInternalServerError, err
+	}
+	return invites, http.StatusOK, nil
+}
+
+func DeleteInvite(db *gorm.DB, workspaceId, inviteId, userId string) (int, error) {
+	var invite models.WorkspaceInvite
+
+	_, code, err := requireRole(db, workspaceId, userId, models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return code, err
+	}
+
+	return invite.DeleteInvite(db, inviteId, workspaceId)
+}
+
+func AcceptInvite(db *gorm.DB, req models.AcceptWorkspaceInviteRequest, workspaceId, userId string) (models.WorkspaceMember, int, error) {
+	var (
+		invite models.WorkspaceInvite
+		user   models.User
+	)
+
+	user, err := user.GetUserByID(db, userId)
+	if err != nil {
+		return models.WorkspaceMember{}, http.StatusNotFound, err
+	}
+
+	return invite.Accept(db, workspaceId, req.Token, user)
+}
+
+// CreateDomain enables auto-join for a domain. The admin's own address must
+// be a verified one on that domain, so nobody can claim a domain they do not
+// belong to, and shared mail providers are refused outright.
+func CreateDomain(db *gorm.DB, req models.CreateWorkspaceDomainRequest, workspaceId, userId string) (models.WorkspaceDomain, int, error) {
+	var user models.User
+
+	_, code, err := requireRole(db, workspaceId, userId, models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return models.WorkspaceDomain{}, code, err
+	}
+
+	user, err = user.GetUserByID(db, userId)
+	if err != nil {
+		return models.WorkspaceDomain{}, http.StatusNotFound, err
+	}
+
+	domain := strings.ToLower(req.Domain)
+	if models.IsPublicEmailDomain(domain) {
+		return models.WorkspaceDomain{}, http.StatusBadRequest, errors.New("public email domains cannot be used for auto-join")
+	}
+
+	if !user.IsVerified || models.EmailDomain(user.Email) != domain {
+		return models.WorkspaceDomain{}, http.StatusForbidden, errors.New("domain must match your verified email address")
+	}
+
+	workspaceDomain := models.WorkspaceDomain{
+		ID:          utility.GenerateUUID(),
+		WorkspaceID: workspaceId,
+		Domain:      domain,
+		CreatedBy:   userId,
+	}
+
+	err = workspaceDomain.CreateDomain(db)
+	if err != nil {
+		return workspaceDomain, http.StatusConflict, err
+	}
+	return workspaceDomain, http.StatusCreated, nil
+}
+
+func DeleteDomain(db *gorm.DB, workspaceId, domainId, userId string) (int, error) {
+	var domain models.WorkspaceDomain
+
+	_, code, err := requireRole(db, workspaceId, userId, models.WorkspaceRoleOwner, models.WorkspaceRoleAdmin)
+	if err != nil {
+		return code, err
+	}
+
+	return domain.DeleteDomain(db, domainId, workspaceId)
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/hngprojects/telex_be/internal/config"
 	"github.com/hngprojects/telex_be/internal/models"
@@ -20,6 +21,7 @@ import (
 	"github.com/hngprojects/telex_be/pkg/repository/storage"
 	"github.com/hngprojects/telex_be/pkg/repository/storage/postgresql"
 	"github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/services/actions/names"
 	"github.com/hngprojects/telex_be/utility"
 )
 
@@ -75,6 +77,55 @@ func AssertValidationError(t *testing.T, response map[string]interface{}, field
 	}
 }
 
+// NewSignupRequest returns signup details for a new user with a unique email
+// and username.
+func NewSignupRequest() models.CreateUserRequestModel {
+	currUUID := utility.GenerateUUID()
+	return models.CreateUserRequestModel{
+		Email:       fmt.Sprintf("testuser%v@qa.team", currUUID),
+		PhoneNumber: fmt.Sprintf("+234%v", utility.GetRandomNumbersInRange(7000000000, 9099999999)),
+		FirstName:   "test",
+		LastName:    "user",
+		Password:    "password",
+		UserName:    fmt.Sprintf("test_username%v", currUUID),
+	}
+}
+
+// CreateUser inserts a user straight into the database, skipping signup. The
+// name and email are made unique from name.
+func CreateUser(db *gorm.DB, name, password string) models.User {
+	currUUID := utility.GenerateUUID()
+	hashed, _ := utility.HashPassword(password)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     fmt.Sprintf("%v%v", name, currUUID[:8]),
+		Email:    fmt.Sprintf("test%v%v@qa.team", name, currUUID),
+		Password: hashed,
+	}
+	db.Create(&user)
+	return user
+}
+
+// QueuedNotifications returns the data of every queued notification called
+// name, newest first. Tests read secrets that are only stored hashed, such as
+// download tokens, from here.
+func QueuedNotifications(t *testing.T, db *storage.Database, name names.NotificationName) []string {
+	entries, err := db.Redis.LRange(redis.Ctx, redis.KeyName, 0, -1).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []string
+	for _, entry := range entries {
+		var record models.NotificationRecord
+		if json.Unmarshal([]byte(entry), &record) == nil && record.Name == string(name) {
+			found = append(found, record.Data)
+		}
+	}
+	return found
+}
+
 func SignupUser(t *testing.T, r *gin.Engine, auth auth.Controller, userSignUpData models.CreateUserRequestModel, admin bool) {
 	var (
 		signupPath = "/api/v1/auth/register"
@@ -0,0 +1,211 @@
+package test_admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/admin"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestAdmin(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+
+	operator := tst.CreateUser(db.Postgresql, "adminoperator", currUUID)
+	member := tst.CreateUser(db.Postgresql, "adminmember", currUUID)
+	target := tst.CreateUser(db.Postgresql, "admintarget", currUUID)
+	db.Postgresql.Model(&operator).Update("role", models.RoleAdmin)
+
+	authController := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	adminController := admin.Controller{Db: db, Validator: validatorRef, Logger: logger}
+
+	r := gin.Default()
+	r.POST("/api/v1/auth/login", authController.LoginUser)
+	r.GET("/api/v1/auth/sessions", middleware.Authorize(db.Postgresql), authController.GetSessions)
+
+	adminUrl := middleware.Scoped(r.Group("/api/v1/admin", middleware.Authorize(db.Postgresql), middleware.AdminOnly(db.Postgresql)))
+	{
+		adminUrl.GET("/users", models.ScopeAdmin, adminController.ListUsers)
+		adminUrl.POST("/users/:userId/suspend", models.ScopeAdmin, adminController.SuspendUser)
+		adminUrl.POST("/users/:userId/unsuspend", models.ScopeAdmin, adminController.UnsuspendUser)
+		adminUrl.POST("/users/:userId/logout", models.ScopeAdmin, adminController.ForceLogout)
+		adminUrl.POST("/users/:userId/verify-email", models.ScopeAdmin, adminController.VerifyUserEmail)
+		adminUrl.GET("/rooms", models.ScopeAdmin, adminController.ListRooms)
+		adminUrl.DELETE("/rooms/:roomId", models.ScopeAdmin, adminController.DeleteRoom)
+		adminUrl.GET("/reports", models.ScopeAdmin, adminController.GetModerationQueue)
+		adminUrl.PATCH("/reports/:reportId", models.ScopeAdmin, adminController.ResolveReport)
+		adminUrl.GET("/notifications/queue", models.ScopeAdmin, adminController.GetNotificationQueue)
+	}
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	login := func(user models.User) *httptest.ResponseRecorder {
+		return send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: user.Email, Password: currUUID})
+	}
+
+	token := func(user models.User) string {
+		resp := login(user)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+		return tst.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+	}
+
+	adminToken := token(operator)
+
+	t.Run("Requires Admin Role", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/admin/users", token(member), nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("Search Users", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/admin/users?search="+target.Email, adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		users := tst.ParseResponse(resp)["data"].([]interface{})
+		if len(users) != 1 || users[0].(map[string]interface{})["id"] != target.ID {
+			t.Errorf("expected search to find only the target user, got %v", users)
+		}
+	})
+
+	t.Run("Suspend User", func(t *testing.T) {
+		session := token(target)
+
+		resp := send(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%v/suspend", target.ID), adminToken, models.SuspendUserRequest{Reason: "spam"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = send(http.MethodGet, "/api/v1/auth/sessions", session, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = login(target)
+		tst.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("Cannot Suspend Self", func(t *testing.T) {
+		resp := send(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%v/suspend", operator.ID), adminToken, models.SuspendUserRequest{Reason: "oops"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Unsuspend User", func(t *testing.T) {
+		resp := send(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%v/unsuspend", target.ID), adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = login(target)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Force Logout", func(t *testing.T) {
+		session := token(target)
+
+		resp := send(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%v/logout", target.ID), adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = send(http.MethodGet, "/api/v1/auth/sessions", session, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Verify Email", func(t *testing.T) {
+		resp := send(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%v/verify-email", target.ID), adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		var user models.User
+		db.Postgresql.First(&user, "id = ?", target.ID)
+		if !user.IsVerified {
+			t.Errorf("expected the user to be verified")
+		}
+	})
+
+	t.Run("Unknown User", func(t *testing.T) {
+		resp := send(http.MethodPost, fmt.Sprintf("/api/v1/admin/users/%v/verify-email", utility.GenerateUUID()), adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusNotFound)
+	})
+
+	t.Run("List And Delete Any Room", func(t *testing.T) {
+		room := models.Room{
+			ID:          utility.GenerateUUID(),
+			Name:        "admin-room-" + currUUID,
+			Description: "owned by someone else",
+			OwnerId:     member.ID,
+		}
+		db.Postgresql.Create(&room)
+
+		resp := send(http.MethodGet, "/api/v1/admin/rooms?search="+room.Name, adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+		if rooms := tst.ParseResponse(resp)["data"].([]interface{}); len(rooms) != 1 {
+			t.Errorf("expected to find the room, got %v", rooms)
+		}
+
+		resp = send(http.MethodDelete, "/api/v1/admin/rooms/"+room.ID, adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Platform Moderation Queue", func(t *testing.T) {
+		report := models.MessageReport{
+			ID:       utility.GenerateUUID(),
+			RoomID:   utility.GenerateUUID(),
+			AuthorID: member.ID,
+			Content:  "flagged " + currUUID,
+			Source:   models.ReportSourceFilter,
+			Reason:   "message contains a link",
+		}
+		db.Postgresql.Create(&report)
+
+		resp := send(http.MethodGet, "/api/v1/admin/reports", token(member), nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+
+		resp = send(http.MethodGet, "/api/v1/admin/reports", adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		found := false
+		for _, item := range tst.ParseResponse(resp)["data"].([]interface{}) {
+			if item.(map[string]interface{})["id"] == report.ID {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected the queue to include the report")
+		}
+
+		resp = send(http.MethodPatch, "/api/v1/admin/reports/"+report.ID, adminToken, models.ResolveReportRequest{Action: "dismiss"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = send(http.MethodPatch, "/api/v1/admin/reports/"+report.ID, adminToken, models.ResolveReportRequest{Action: "dismiss"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusConflict)
+	})
+
+	t.Run("Notification Queue", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/admin/notifications/queue", adminToken, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tst.ParseResponse(resp)["data"].(map[string]interface{})
+		if _, ok := data["length"].(float64); !ok {
+			t.Errorf("expected the queue length, got %v", data)
+		}
+	})
+}
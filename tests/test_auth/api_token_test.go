@@ -0,0 +1,124 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestAPITokens(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "api token jane doe",
+		Email:    fmt.Sprintf("testapitoken%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "ok"}) }
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+	scoped := middleware.Scoped(&router.RouterGroup)
+	scoped.GET("/api/v1/test/rooms", models.ScopeRoomsRead, middleware.Authorize(db), ok)
+	scoped.POST("/api/v1/test/rooms", models.ScopeRoomsWrite, middleware.Authorize(db), ok)
+	middleware.Scoped(router.Group("/api/v1/test/groups", middleware.Authorize(db))).GET("/", models.ScopeRoomsRead, ok)
+
+	send := func(method, path, token, remoteAddr string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/api/v1/auth/login", "", "", models.LoginRequestModel{Email: user.Email, Password: currUUID})
+	tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	session := tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+	create := func(req models.CreateAPITokenRequest) (string, string) {
+		resp := send(http.MethodPost, "/api/v1/auth/api-tokens", session, "", req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusCreated)
+
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		return data["token"].(string), data["api_token"].(map[string]interface{})["id"].(string)
+	}
+
+	t.Run("Rejects Unknown Scope", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/auth/api-tokens", session, "", models.CreateAPITokenRequest{Name: "bot", Scopes: []string{"everything"}})
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnprocessableEntity)
+	})
+
+	token, tokenID := create(models.CreateAPITokenRequest{Name: "bot", Scopes: []string{models.ScopeRoomsRead}})
+
+	t.Run("Token Is Shown Once", func(t *testing.T) {
+		if !strings.HasPrefix(token, models.APITokenPrefix) {
+			t.Errorf("expected token to start with %v, got %v", models.APITokenPrefix, token)
+		}
+
+		resp := send(http.MethodGet, "/api/v1/auth/api-tokens", session, "", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		if strings.Contains(resp.Body.String(), token) {
+			t.Errorf("expected listing to leave out the token")
+		}
+	})
+
+	t.Run("Scope Allows Route", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/test/rooms", token, "", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Scope Declared On Group Route", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/test/groups/", token, "", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Missing Scope", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/test/rooms", token, "", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("Route Without Scope Is Session Only", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/auth/api-tokens", token, "", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("IP Restriction", func(t *testing.T) {
+		restricted, _ := create(models.CreateAPITokenRequest{Name: "ci", Scopes: []string{models.ScopeRoomsRead}, AllowedIPs: []string{"10.0.0.0/8"}})
+
+		resp := send(http.MethodGet, "/api/v1/test/rooms", restricted, "192.168.1.10:4000", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+
+		resp = send(http.MethodGet, "/api/v1/test/rooms", restricted, "10.1.2.3:4000", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Revoked Token", func(t *testing.T) {
+		resp := send(http.MethodDelete, "/api/v1/auth/api-tokens/"+tokenID, session, "", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = send(http.MethodGet, "/api/v1/test/rooms", token, "", nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 
+	"github.com/hngprojects/telex_be/external/request"
 	"github.com/hngprojects/telex_be/pkg/controller/auth"
 	"github.com/hngprojects/telex_be/pkg/middleware"
 	"github.com/hngprojects/telex_be/pkg/repository/storage"
@@ -21,6 +22,7 @@ func SetupAuthTestRouter() (*gin.Engine, *auth.Controller) {
 		Db:        db,
 		Validator: validator,
 		Logger:    logger,
+		ExtReq:    request.ExternalRequest{Logger: logger, Test: true},
 	}
 
 	r := gin.Default()
@@ -36,4 +38,54 @@ func SetupAuthRoutes(r *gin.Engine, authController *auth.Controller) {
 	r.POST("/api/v1/auth/password-reset/verify", authController.VerifyResetToken)
 	r.POST("/api/v1/auth/magick-link", authController.RequestMagicLink)
 	r.POST("/api/v1/auth/magick-link/verify", authController.VerifyMagicLink)
+	r.POST("/api/v1/auth/refresh", authController.RefreshToken)
+	r.GET("/api/v1/auth/sessions",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.GetSessions)
+	r.DELETE("/api/v1/auth/sessions/:sessionId",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.RevokeSession)
+	r.POST("/api/v1/auth/sessions/revoke-others",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.RevokeOtherSessions)
+	r.POST("/api/v1/auth/2fa/enroll",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.EnrollTwoFactor)
+	r.POST("/api/v1/auth/2fa/confirm",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.ConfirmTwoFactor)
+	r.POST("/api/v1/auth/2fa/disable",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.DisableTwoFactor)
+	r.POST("/api/v1/auth/2fa/verify", authController.VerifyTwoFactor)
+	r.GET("/api/v1/auth/identities",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.GetIdentities)
+	r.POST("/api/v1/auth/identities/:provider",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.LinkIdentity)
+	r.DELETE("/api/v1/auth/identities/:identityId",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.UnlinkIdentity)
+	r.GET("/api/v1/auth/api-tokens",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.GetAPITokens)
+	r.POST("/api/v1/auth/api-tokens",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.CreateAPIToken)
+	r.DELETE("/api/v1/auth/api-tokens/:tokenId",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.RevokeAPIToken)
+	r.GET("/api/v1/auth/oauth/:provider", authController.BeginOAuth)
+	r.GET("/api/v1/auth/oauth/:provider/callback", authController.OAuthCallback)
+	r.GET("/api/v1/auth/not-me", authController.ConfirmSignInReport)
+	r.POST("/api/v1/auth/not-me", authController.ReportSignIn)
+	r.POST("/api/v1/auth/phone/login", authController.RequestPhoneLogin)
+	r.POST("/api/v1/auth/phone/login/verify", authController.VerifyPhoneLogin)
+	r.POST("/api/v1/auth/phone/verify",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.RequestPhoneVerification)
+	r.POST("/api/v1/auth/phone/verify/confirm",
+		middleware.Authorize(authController.Db.Postgresql),
+		authController.ConfirmPhoneVerification)
 }
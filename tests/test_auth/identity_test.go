@@ -0,0 +1,146 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/faux"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestUserIdentities(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	gothic.Store = NewProviderStore()
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+	subject := utility.GenerateUUID()
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "identity jane doe",
+		Email:    fmt.Sprintf("testidentity%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// callback replays a finished faux login for the same provider account,
+	// optionally carrying a link token as the OAuth state and the one the
+	// browser started the link with
+	callback := func(state, boundToken string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/oauth/faux/callback?state="+url.QueryEscape(state), nil)
+
+		sess := faux.Session{ID: subject, Name: "Identity Jane", Email: user.Email, AccessToken: "access-token"}
+		session, _ := gothic.Store.Get(req, gothic.SessionName)
+		session.Values["faux"] = gzipString(sess.Marshal())
+		session.Save(req, resp)
+
+		if boundToken != "" {
+			link, _ := gothic.Store.Get(req, "_oauth_link")
+			link.Values["link_token"] = boundToken
+			link.IsNew = false
+			link.Save(req, resp)
+		}
+
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: user.Email, Password: currUUID})
+	tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	token := tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+	t.Run("Unverified Email Does Not Match Account", func(t *testing.T) {
+		resp := callback("", "")
+		tests.AssertStatusCode(t, resp.Code, http.StatusConflict)
+	})
+
+	t.Run("Link Requires Password", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/auth/identities/faux", token, models.LinkIdentityRequest{Password: "wrong"})
+		tests.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("Link Unknown Provider", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/auth/identities/unknown", token, models.LinkIdentityRequest{Password: currUUID})
+		tests.AssertStatusCode(t, resp.Code, http.StatusNotFound)
+	})
+
+	var identityID string
+
+	t.Run("Link Through Provider", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/auth/identities/faux", token, models.LinkIdentityRequest{Password: currUUID})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		authorizeURL, err := url.Parse(data["authorize_url"].(string))
+		if err != nil {
+			t.Fatalf("expected an authorize url, got %v", data["authorize_url"])
+		}
+
+		linkToken := authorizeURL.Query().Get("link_token")
+
+		resp = callback(linkToken, "")
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = callback(linkToken, linkToken)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		identityID = tests.ParseResponse(resp)["data"].(map[string]interface{})["id"].(string)
+
+		resp = send(http.MethodGet, "/api/v1/auth/identities", token, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		identities := tests.ParseResponse(resp)["data"].([]interface{})
+		if len(identities) != 1 || identities[0].(map[string]interface{})["provider"] != "faux" {
+			t.Errorf("expected the faux identity to be listed, got %v", identities)
+		}
+	})
+
+	t.Run("Linked Identity Logs In", func(t *testing.T) {
+		resp := callback("", "")
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		if data["user"].(map[string]interface{})["id"].(string) != user.ID {
+			t.Errorf("expected the linked identity to sign in to the existing account")
+		}
+	})
+
+	t.Run("Unlink Requires Password", func(t *testing.T) {
+		resp := send(http.MethodDelete, "/api/v1/auth/identities/"+identityID, token, models.ReauthRequest{Password: "wrong"})
+		tests.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("Unlink Identity", func(t *testing.T) {
+		resp := send(http.MethodDelete, "/api/v1/auth/identities/"+identityID, token, models.ReauthRequest{Password: currUUID})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = callback("", "")
+		tests.AssertStatusCode(t, resp.Code, http.StatusConflict)
+	})
+}
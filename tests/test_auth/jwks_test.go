@@ -0,0 +1,233 @@
+package test_auth
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	authService "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// publicKeyFromJWK is what a service verifying Telex tokens would do with
+// the key set.
+func publicKeyFromJWK(t *testing.T, jwk utility.JWK) interface{} {
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(decode(jwk.N)), E: int(new(big.Int).SetBytes(decode(jwk.E)).Int64())}
+	case "OKP":
+		return ed25519.PublicKey(decode(jwk.X))
+	}
+	t.Fatalf("unexpected key type %v", jwk.Kty)
+	return nil
+}
+
+func TestJWKS(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "jwks jane doe",
+		Email:    fmt.Sprintf("testjwks%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+	router.GET("/.well-known/jwks.json", authController.GetJWKS)
+
+	reqBody, _ := json.Marshal(models.LoginRequestModel{Email: user.Email, Password: currUUID})
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	accessToken := tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+	getJWKS := func() authService.JWKS {
+		var jwks authService.JWKS
+
+		req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		if err := json.Unmarshal(resp.Body.Bytes(), &jwks); err != nil {
+			t.Fatal(err)
+		}
+		return jwks
+	}
+
+	parsed, _, err := new(jwt.Parser).ParseUnverified(accessToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+
+	t.Run("Token Verifies Against Published Key", func(t *testing.T) {
+		if alg := parsed.Header["alg"]; alg != utility.SigningAlgRS256 && alg != utility.SigningAlgEdDSA {
+			t.Fatalf("expected an asymmetric signature, got %v", alg)
+		}
+
+		for _, jwk := range getJWKS().Keys {
+			if jwk.Kid != kid {
+				continue
+			}
+
+			_, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
+				return publicKeyFromJWK(t, jwk), nil
+			})
+			if err != nil {
+				t.Fatalf("expected the token to verify with the published key, %v", err)
+			}
+			return
+		}
+		t.Fatalf("expected key %v to be published", kid)
+	})
+
+	t.Run("Rejects HMAC Token Naming A Key", func(t *testing.T) {
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": user.ID, "exp": time.Now().Add(time.Minute).Unix()})
+		forged.Header["kid"] = kid
+		forgedToken, _ := forged.SignedString([]byte("secret"))
+
+		if _, err := middleware.TokenValid(forgedToken); err == nil {
+			t.Error("expected an HMAC signed token to be rejected")
+		}
+	})
+
+	t.Run("Rejects Unknown Key", func(t *testing.T) {
+		parts := strings.Split(accessToken, ".")
+		header, _ := json.Marshal(map[string]string{"alg": parsed.Method.Alg(), "typ": "JWT", "kid": utility.GenerateUUID()})
+		parts[0] = base64.RawURLEncoding.EncodeToString(header)
+
+		if _, err := middleware.TokenValid(strings.Join(parts, ".")); err == nil {
+			t.Error("expected a token naming an unknown key to be rejected")
+		}
+	})
+
+	t.Run("Rotation Publishes Next Key And Keeps Old One", func(t *testing.T) {
+		var current models.SigningKey
+		if err := db.Where("id = ?", kid).First(&current).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		// instances run the job at the same time; only one may add a key
+		rotateAt := current.ActiveFrom.Add(365 * 24 * time.Hour)
+		results := make(chan bool, 4)
+		var wg sync.WaitGroup
+		for i := 0; i < cap(results); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rotated, err := authService.RotateSigningKeys(db, rotateAt)
+				if err != nil {
+					t.Error(err)
+				}
+				results <- rotated
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		var next []models.SigningKey
+		db.Where("active_from > ?", rotateAt).Find(&next)
+		t.Cleanup(func() {
+			for _, key := range next {
+				db.Delete(&key)
+			}
+			db.Model(&models.SigningKey{}).Where("id = ?", kid).Update("expires_at", nil)
+		})
+
+		created := 0
+		for rotated := range results {
+			if rotated {
+				created++
+			}
+		}
+		if created != 1 || len(next) != 1 {
+			t.Fatalf("expected one new key, %v rotations reported and %v keys added", created, len(next))
+		}
+		if next[0].ExpiresAt != nil {
+			t.Errorf("expected the new key not to be expired by its own rotation, expires at %v", next[0].ExpiresAt)
+		}
+
+		if rotated, _ := authService.RotateSigningKeys(db, rotateAt); rotated {
+			t.Error("expected a pending key to stop a second rotation")
+		}
+
+		published := map[string]bool{}
+		for _, jwk := range getJWKS().Keys {
+			published[jwk.Kid] = true
+		}
+		if !published[kid] || !published[next[0].ID] {
+			t.Errorf("expected the old and the next key to be published, got %v", published)
+		}
+
+		db.Where("id = ?", kid).First(&current)
+		if current.ExpiresAt == nil || !current.ExpiresAt.After(next[0].ActiveFrom) {
+			t.Errorf("expected the old key to verify past the switch, expires at %v", current.ExpiresAt)
+		}
+
+		if _, err := middleware.TokenValid(accessToken); err != nil {
+			t.Errorf("expected tokens signed with the old key to stay valid, %v", err)
+		}
+	})
+
+	t.Run("Private Keys Are Encrypted At Rest", func(t *testing.T) {
+		var stored []models.SigningKey
+		db.Find(&stored)
+
+		for _, key := range stored {
+			if !utility.IsSealedSigningKey(key.PrivateKey) || strings.Contains(key.PrivateKey, "PRIVATE KEY") {
+				t.Fatalf("expected key %v to be stored encrypted", key.ID)
+			}
+
+			privatePEM, err := utility.OpenPrivateSigningKey(key.PrivateKey, middleware.SigningKeySecret())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := utility.ParsePrivateSigningKey(privatePEM); err != nil {
+				t.Errorf("expected key %v to decrypt, %v", key.ID, err)
+			}
+			if _, err := utility.OpenPrivateSigningKey(key.PrivateKey, "wrong secret"); err == nil {
+				t.Errorf("expected key %v not to decrypt with another secret", key.ID)
+			}
+		}
+	})
+
+	t.Run("Rejects Token Without API Audience", func(t *testing.T) {
+		token, err := middleware.SignToken(jwt.MapClaims{"user_id": user.ID, "exp": time.Now().Add(time.Minute).Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := middleware.TokenValid(token); err == nil {
+			t.Error("expected a token without the api audience to be rejected")
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/admin"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestLockout(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	remoteAddr := fmt.Sprintf("198.51.100.%v:4000", utility.GetRandomNumbersInRange(1, 254))
+
+	user := tests.CreateUser(db, "lockout", currUUID)
+	operator := tests.CreateUser(db, "lockoutadmin", currUUID)
+	db.Model(&operator).Update("role", models.RoleAdmin)
+
+	adminController := admin.Controller{Db: authController.Db, Validator: authController.Validator, Logger: authController.Logger}
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+	router.POST("/api/v1/admin/users/:userId/unlock", middleware.Authorize(db), middleware.AdminOnly(db), adminController.UnlockUser)
+
+	send := func(path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	login := func(email, password string) *httptest.ResponseRecorder {
+		return send("/api/v1/auth/login", "", models.LoginRequestModel{Email: email, Password: password})
+	}
+
+	t.Run("Delays Repeated Failures", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			resp := login(user.Email, "incorrect")
+			tests.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+		}
+
+		resp := login(user.Email, "incorrect")
+		tests.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+
+		resp = login(user.Email, currUUID)
+		tests.AssertStatusCode(t, resp.Code, http.StatusTooManyRequests)
+		if resp.Header().Get("Retry-After") == "" {
+			t.Errorf("expected a Retry-After header")
+		}
+	})
+
+	t.Run("Other Accounts Unaffected", func(t *testing.T) {
+		resp := login(operator.Email, currUUID)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Admin Unlock", func(t *testing.T) {
+		resp := login(operator.Email, currUUID)
+		token := tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+		resp = send(fmt.Sprintf("/api/v1/admin/users/%v/unlock", user.ID), token, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = login(user.Email, currUUID)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Reauthentication Counts Towards Lockout", func(t *testing.T) {
+		reauthUser := tests.CreateUser(db, "lockoutreauth", currUUID)
+		resp := login(reauthUser.Email, currUUID)
+		token := tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+		for i := 0; i < 4; i++ {
+			resp := send("/api/v1/auth/identities/google", token, models.LinkIdentityRequest{Password: "incorrect"})
+			tests.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+		}
+
+		resp = send("/api/v1/auth/identities/google", token, models.LinkIdentityRequest{Password: currUUID})
+		tests.AssertStatusCode(t, resp.Code, http.StatusTooManyRequests)
+		if resp.Header().Get("Retry-After") == "" {
+			t.Errorf("expected a Retry-After header")
+		}
+
+		resp = login(reauthUser.Email, currUUID)
+		tests.AssertStatusCode(t, resp.Code, http.StatusTooManyRequests)
+	})
+
+	t.Run("Limits Reset Code Guesses Per IP", func(t *testing.T) {
+		guess := func() *httptest.ResponseRecorder {
+			return send("/api/v1/auth/password-reset/verify", "", models.ResetPasswordRequestModel{
+				Token:       utility.GenerateUUID(),
+				NewPassword: "newpassword",
+			})
+		}
+
+		for i := 0; i < 21; i++ {
+			resp := guess()
+			tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+		}
+
+		resp := guess()
+		tests.AssertStatusCode(t, resp.Code, http.StatusTooManyRequests)
+	})
+}
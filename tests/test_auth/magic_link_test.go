@@ -115,6 +115,12 @@ func TestVerifyMagicLink(t *testing.T) {
 		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
 		response := tests.ParseResponse(resp)
 		tests.AssertResponseMessage(t, response["message"].(string), "User login successfully")
+
+		var logins int64
+		db.Model(&models.SecurityEvent{}).Where("user_id = ? AND type = ?", adminData.ID, models.SecurityEventLogin).Count(&logins)
+		if logins != 1 {
+			t.Errorf("expected the magic link login to be recorded, got %v events", logins)
+		}
 	})
 
 	t.Run("Invalid or Expired Token", func(t *testing.T) {
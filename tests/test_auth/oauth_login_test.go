@@ -0,0 +1,83 @@
+package test_auth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/faux"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestOAuthLogin(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	gothic.Store = NewProviderStore()
+
+	email := fmt.Sprintf("testoauth%v@qa.team", utility.GenerateUUID())
+	subject := utility.GenerateUUID()
+
+	// callback replays a finished faux login, as if the provider had just
+	// redirected back
+	callback := func() *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/oauth/faux/callback", nil)
+
+		sess := faux.Session{ID: subject, Name: "Oauth Jane", Email: email, AccessToken: "access-token"}
+		session, _ := gothic.Store.Get(req, gothic.SessionName)
+		session.Values["faux"] = gzipString(sess.Marshal())
+		session.Save(req, resp)
+
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("Begin Redirects To Provider", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/oauth/faux", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		tests.AssertStatusCode(t, resp.Code, http.StatusTemporaryRedirect)
+	})
+
+	t.Run("Unknown Provider", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/oauth/unknown", nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		tests.AssertStatusCode(t, resp.Code, http.StatusNotFound)
+	})
+
+	var userID string
+
+	t.Run("Callback Creates User", func(t *testing.T) {
+		resp := callback()
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		if data["access_token"].(string) == "" {
+			t.Errorf("expected an access token")
+		}
+		userID = data["user"].(map[string]interface{})["id"].(string)
+
+		var user models.User
+		if err := db.First(&user, "email = ?", email).Error; err != nil || user.ID != userID {
+			t.Errorf("expected the provider identity to map onto a user")
+		}
+	})
+
+	t.Run("Callback Reuses User", func(t *testing.T) {
+		resp := callback()
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		if data["user"].(map[string]interface{})["id"].(string) != userID {
+			t.Errorf("expected the second login to reuse the account")
+		}
+	})
+}
@@ -52,6 +52,16 @@ func TestUpdateUserPassword(t *testing.T) {
 		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
 		response := tests.ParseResponse(resp)
 		tests.AssertResponseMessage(t, response["message"].(string), "Password updated successfully")
+
+		req, _ = http.NewRequest(http.MethodPut, "/api/v1/auth/change-password", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		loginData.Password = changePasswordRequest.NewPassword
+		token = tests.GetLoginToken(t, router, auth, loginData)
 	})
 
 	t.Run("Incorrect Old Password", func(t *testing.T) {
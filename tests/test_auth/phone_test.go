@@ -0,0 +1,190 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/services/send"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// fakeSMSSender keeps the last message sent to each number.
+type fakeSMSSender struct {
+	mu   sync.Mutex
+	sent map[string]string
+}
+
+func (s *fakeSMSSender) SendSMS(to, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent[to] = body
+	return nil
+}
+
+// take returns the code in the last message to a number and forgets it.
+func (s *fakeSMSSender) take(to string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, ok := s.sent[to]
+	delete(s.sent, to)
+	if !ok {
+		return ""
+	}
+	return regexp.MustCompile(`\d{6}`).FindString(body)
+}
+
+// failingSMSSender stands in for a provider that is down.
+type failingSMSSender struct{}
+
+func (failingSMSSender) SendSMS(to, body string) error {
+	return errors.New("sms provider unavailable")
+}
+
+func TestPhoneLogin(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	sender := &fakeSMSSender{sent: map[string]string{}}
+	send.UseSMSSender(sender)
+
+	phone := fmt.Sprintf("+447911%06d", time.Now().UnixNano()%1000000)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "phone login jane doe",
+		Email:    fmt.Sprintf("testphonelogin%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+
+	post := func(path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// texts to one number are rate limited, so each step starts fresh
+	resetCooldown := func() {
+		_, _ = rdb.RedisDelete(authController.Db.Redis, "phone_send:number:"+utility.HashToken(phone)+":cooldown")
+	}
+
+	resp := post("/api/v1/auth/login", "", models.LoginRequestModel{Email: user.Email, Password: currUUID})
+	tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	token := tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+	t.Run("Unverified Number Gets No Code", func(t *testing.T) {
+		resp := post("/api/v1/auth/phone/login", "", models.PhoneCodeRequest{PhoneNumber: phone})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		if code := sender.take(phone); code != "" {
+			t.Errorf("expected no text to an unverified number, got %v", code)
+		}
+	})
+
+	t.Run("Verify Phone", func(t *testing.T) {
+		resetCooldown()
+		resp := post("/api/v1/auth/phone/verify", token, models.PhoneCodeRequest{PhoneNumber: phone})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		code := sender.take(phone)
+		if code == "" {
+			t.Fatal("expected a verification code to be sent")
+		}
+
+		wrong := "000000"
+		if code == wrong {
+			wrong = "111111"
+		}
+		resp = post("/api/v1/auth/phone/verify/confirm", token, models.PhoneCodeVerifyRequest{PhoneNumber: phone, Code: wrong})
+		tests.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+
+		resp = post("/api/v1/auth/phone/verify/confirm", token, models.PhoneCodeVerifyRequest{PhoneNumber: phone, Code: code})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		profile, err := models.GetProfileByVerifiedPhone(db, phone)
+		if err != nil || profile.Userid != user.ID {
+			t.Errorf("expected the number to be verified for the user, %v", err)
+		}
+	})
+
+	t.Run("Verified Number Is Unique", func(t *testing.T) {
+		other := models.User{
+			ID:       utility.GenerateUUID(),
+			Name:     "phone login john doe",
+			Email:    fmt.Sprintf("testphoneother%v@qa.team", currUUID),
+			Password: password,
+			Profile:  models.Profile{ID: utility.GenerateUUID()},
+		}
+		db.Create(&other)
+
+		// the database refuses a second verified copy even if the service
+		// check is raced
+		if err := other.Profile.SetVerifiedPhone(db, other.ID, phone, phone); err == nil {
+			t.Error("expected a number verified elsewhere to be refused")
+		}
+	})
+
+	t.Run("Cooldown Between Texts", func(t *testing.T) {
+		resetCooldown()
+		resp := post("/api/v1/auth/phone/login", "", models.PhoneCodeRequest{PhoneNumber: phone})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		sender.take(phone)
+
+		resp = post("/api/v1/auth/phone/login", "", models.PhoneCodeRequest{PhoneNumber: phone})
+		tests.AssertStatusCode(t, resp.Code, http.StatusTooManyRequests)
+		if resp.Header().Get("Retry-After") == "" {
+			t.Error("expected a Retry-After header")
+		}
+	})
+
+	t.Run("Failed Text Looks The Same", func(t *testing.T) {
+		resetCooldown()
+		send.UseSMSSender(failingSMSSender{})
+		defer send.UseSMSSender(sender)
+
+		resp := post("/api/v1/auth/phone/login", "", models.PhoneCodeRequest{PhoneNumber: phone})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Login With Code", func(t *testing.T) {
+		resetCooldown()
+		resp := post("/api/v1/auth/phone/login", "", models.PhoneCodeRequest{PhoneNumber: phone})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		code := sender.take(phone)
+		if code == "" {
+			t.Fatal("expected a sign-in code to be sent")
+		}
+
+		resp = post("/api/v1/auth/phone/login/verify", "", models.PhoneCodeVerifyRequest{PhoneNumber: phone, Code: code})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		if data["access_token"] == nil {
+			t.Error("expected an access token")
+		}
+
+		resp = post("/api/v1/auth/phone/login/verify", "", models.PhoneCodeVerifyRequest{PhoneNumber: phone, Code: code})
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,78 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestRefreshToken(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "refresh jane doe",
+		Email:    fmt.Sprintf("testrefresh%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+
+	post := func(path string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		return post("/api/v1/auth/refresh", models.RefreshTokenRequest{RefreshToken: token})
+	}
+
+	resp := post("/api/v1/auth/login", models.LoginRequestModel{Email: user.Email, Password: currUUID})
+	tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	firstToken := tests.ParseResponse(resp)["data"].(map[string]interface{})["refresh_token"].(string)
+
+	var secondToken string
+
+	t.Run("Rotates Refresh Token", func(t *testing.T) {
+		resp := refresh(firstToken)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		secondToken = data["refresh_token"].(string)
+		if secondToken == "" || secondToken == firstToken {
+			t.Errorf("expected a new refresh token")
+		}
+		if data["access_token"].(string) == "" {
+			t.Errorf("expected a new access token")
+		}
+	})
+
+	t.Run("Reuse Revokes Family", func(t *testing.T) {
+		resp := refresh(firstToken)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = refresh(secondToken)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Unknown Token", func(t *testing.T) {
+		resp := refresh("not-a-token")
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
 	"github.com/hngprojects/telex_be/tests"
 	"github.com/hngprojects/telex_be/utility"
 )
@@ -41,6 +42,12 @@ func TestResetPassword(t *testing.T) {
 		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
 		response := tests.ParseResponse(resp)
 		tests.AssertResponseMessage(t, response["message"].(string), "Password reset email sent")
+
+		var reset models.PasswordReset
+		db.Where("email = ?", adminData.Email).First(&reset)
+		if len(reset.Token) < 32 {
+			t.Errorf("expected a long reset token, got %q", reset.Token)
+		}
 	})
 
 	t.Run("Invalid Email reset Password Request", func(t *testing.T) {
@@ -100,6 +107,9 @@ func TestVerifyResetPassword(t *testing.T) {
 	}
 	db.Create(&passwordResetData)
 
+	auth := auth.Controller{Db: authController.Db, Validator: authController.Validator, Logger: authController.Logger}
+	token := tests.GetLoginToken(t, router, auth, models.LoginRequestModel{Email: adminData.Email, Password: currUUID})
+
 	t.Run("Successful Password Reset", func(t *testing.T) {
 		resetPasswordRequest := models.ResetPasswordRequestModel{
 			Token:       resetToken,
@@ -115,6 +125,12 @@ func TestVerifyResetPassword(t *testing.T) {
 		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
 		response := tests.ParseResponse(resp)
 		tests.AssertResponseMessage(t, response["message"].(string), "Password has been reset successfully")
+
+		req, _ = http.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
 	})
 
 	t.Run("Invalid or Expired Token", func(t *testing.T) {
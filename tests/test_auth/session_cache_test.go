@@ -0,0 +1,164 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+// sessionCacheFixture logs a new user in and returns the router and a way to
+// call an authorized route with a token.
+func sessionCacheFixture(tb testing.TB) (*gin.Engine, func() string, func(token string) int) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "session cache jane doe",
+		Email:    fmt.Sprintf("testsessioncache%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+	router.POST("/api/v1/auth/logout", middleware.Authorize(db), authController.LogoutUser)
+	router.GET("/api/v1/authorized", middleware.Authorize(db), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	login := func() string {
+		reqBody, _ := json.Marshal(models.LoginRequestModel{Email: user.Email, Password: currUUID})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			tb.Fatalf("login failed with %v", resp.Code)
+		}
+		return tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+	}
+
+	authorized := func(token string) int {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/authorized", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	return router, login, authorized
+}
+
+func accessUUID(t *testing.T, token string) string {
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Claims.(jwt.MapClaims)["access_uuid"].(string)
+}
+
+func TestSessionCache(t *testing.T) {
+	router, login, authorized := sessionCacheFixture(t)
+	redisClient := storage.DB.Redis
+
+	t.Run("Caches Session", func(t *testing.T) {
+		token := login()
+		tests.AssertStatusCode(t, authorized(token), http.StatusNoContent)
+
+		if _, err := rdb.RedisGet(redisClient, "session:"+accessUUID(t, token)); err != nil {
+			t.Fatalf("expected the session to be cached, %v", err)
+		}
+		tests.AssertStatusCode(t, authorized(token), http.StatusNoContent)
+	})
+
+	t.Run("Logout Invalidates Cache", func(t *testing.T) {
+		token := login()
+		tests.AssertStatusCode(t, authorized(token), http.StatusNoContent)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		tests.AssertStatusCode(t, authorized(token), http.StatusUnauthorized)
+	})
+
+	t.Run("Revocation Invalidates Cache", func(t *testing.T) {
+		first, second := login(), login()
+		tests.AssertStatusCode(t, authorized(first), http.StatusNoContent)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/sessions/revoke-others", nil)
+		req.Header.Set("Authorization", "Bearer "+second)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		tests.AssertStatusCode(t, authorized(first), http.StatusUnauthorized)
+		tests.AssertStatusCode(t, authorized(second), http.StatusNoContent)
+	})
+
+	t.Run("Falls Back To Postgres When Redis Is Down", func(t *testing.T) {
+		token := login()
+
+		storage.DB.Redis = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+		defer func() { storage.DB.Redis = redisClient }()
+
+		tests.AssertStatusCode(t, authorized(token), http.StatusNoContent)
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		tests.AssertStatusCode(t, authorized(token), http.StatusUnauthorized)
+	})
+}
+
+// BenchmarkAuthorize compares validating a session against Postgres on every
+// request with reading it from the Redis cache.
+func BenchmarkAuthorize(b *testing.B) {
+	_, login, authorized := sessionCacheFixture(b)
+	token := login()
+	redisClient := storage.DB.Redis
+
+	b.Run("Postgres", func(b *testing.B) {
+		storage.DB.Redis = nil
+		defer func() { storage.DB.Redis = redisClient }()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if code := authorized(token); code != http.StatusNoContent {
+				b.Fatalf("expected 204, got %v", code)
+			}
+		}
+	})
+
+	b.Run("RedisCache", func(b *testing.B) {
+		authorized(token)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if code := authorized(token); code != http.StatusNoContent {
+				b.Fatalf("expected 204, got %v", code)
+			}
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestSessions(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "sessions jane doe",
+		Email:    fmt.Sprintf("testsessions%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	login := func() string {
+		resp := send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: user.Email, Password: currUUID})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		return tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+	}
+
+	firstToken := login()
+	secondToken := login()
+
+	t.Run("Lists Sessions", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/auth/sessions", secondToken, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		sessions := tests.ParseResponse(resp)["data"].([]interface{})
+		if len(sessions) != 2 {
+			t.Fatalf("expected 2 sessions, got %v", len(sessions))
+		}
+
+		current := 0
+		for _, s := range sessions {
+			if s.(map[string]interface{})["current"].(bool) {
+				current++
+			}
+		}
+		if current != 1 {
+			t.Errorf("expected exactly one current session, got %v", current)
+		}
+	})
+
+	t.Run("Unknown Session", func(t *testing.T) {
+		resp := send(http.MethodDelete, "/api/v1/auth/sessions/"+utility.GenerateUUID(), secondToken, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusNotFound)
+	})
+
+	t.Run("Invalid Session ID", func(t *testing.T) {
+		resp := send(http.MethodDelete, "/api/v1/auth/sessions/not-a-uuid", secondToken, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Revokes Other Sessions", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/auth/sessions/revoke-others", secondToken, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = send(http.MethodGet, "/api/v1/auth/sessions", firstToken, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = send(http.MethodGet, "/api/v1/auth/sessions", secondToken, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		if sessions := tests.ParseResponse(resp)["data"].([]interface{}); len(sessions) != 1 {
+			t.Errorf("expected 1 session, got %v", len(sessions))
+		}
+	})
+}
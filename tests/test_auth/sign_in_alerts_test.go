@@ -0,0 +1,183 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	authService "github.com/hngprojects/telex_be/services/auth"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestNewSignInAlerts(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "sign in alerts jane doe",
+		Email:    fmt.Sprintf("testsigninalerts%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+
+	login := func(userAgent string) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(models.LoginRequestModel{Email: user.Email, Password: currUUID})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = "8.8.8.8:4000"
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resolve := func() {
+		for {
+			resolved, err := authService.ResolveSecurityEventLocations(authController.ExtReq, db, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resolved == 0 {
+				return
+			}
+		}
+	}
+
+	// alerts returns the new sign-in emails queued for the user
+	alerts := func() []models.SendNewSignIn {
+		entries, err := authController.Db.Redis.LRange(rdb.Ctx, rdb.KeyName, 0, -1).Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var found []models.SendNewSignIn
+		for _, entry := range entries {
+			var record models.NotificationRecord
+			var alert models.SendNewSignIn
+			if json.Unmarshal([]byte(entry), &record) != nil || record.Name != string(names.SendNewSignIn) {
+				continue
+			}
+			if json.Unmarshal([]byte(record.Data), &alert) == nil && alert.Email == user.Email {
+				found = append(found, alert)
+			}
+		}
+		return found
+	}
+
+	resp := login("Mozilla/5.0 (X11; Linux x86_64) Firefox/120.0")
+	tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	resolve()
+
+	t.Run("No Alert On First Login", func(t *testing.T) {
+		if found := alerts(); len(found) != 0 {
+			t.Errorf("expected no alert, got %v", found)
+		}
+	})
+
+	t.Run("No Alert After Browser Update", func(t *testing.T) {
+		resp := login("Mozilla/5.0 (X11; Linux x86_64) Firefox/121.0")
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		resolve()
+
+		if found := alerts(); len(found) != 0 {
+			t.Errorf("expected no alert, got %v", found)
+		}
+	})
+
+	resp = login("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Safari/604.1")
+	tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	strangerToken := tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+	resolve()
+
+	found := alerts()
+	if len(found) != 1 {
+		t.Fatalf("expected 1 alert for the new device, got %v", len(found))
+	}
+
+	link, err := url.Parse(found[0].NotMeLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notMePath := link.Path + "?" + link.RawQuery
+
+	notMe := func() *httptest.ResponseRecorder {
+		form := url.Values{"token": {link.Query().Get("token")}}
+		req, _ := http.NewRequest(http.MethodPost, link.Path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("Opening Link Only Asks For Confirmation", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, notMePath, nil)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		if !strings.Contains(resp.Body.String(), `method="POST"`) {
+			t.Errorf("expected a confirmation form, got %v", resp.Body.String())
+		}
+
+		req, _ = http.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+strangerToken)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Not Me Revokes Session", func(t *testing.T) {
+		resp := notMe()
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+strangerToken)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Link Works Once", func(t *testing.T) {
+		resp := notMe()
+		tests.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+
+		req, _ := http.NewRequest(http.MethodGet, notMePath, nil)
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Password Login Blocked Until Reset", func(t *testing.T) {
+		resp := login("Mozilla/5.0 (X11; Linux x86_64) Firefox/121.0")
+		tests.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+
+		var reset models.PasswordReset
+		if err := db.Where("email = ?", user.Email).First(&reset).Error; err != nil {
+			t.Fatalf("expected a reset code to be sent, %v", err)
+		}
+
+		reqBody, _ := json.Marshal(models.ResetPasswordRequestModel{Token: reset.Token, NewPassword: currUUID})
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/password-reset/verify", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp = httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = login("Mozilla/5.0 (X11; Linux x86_64) Firefox/121.0")
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+}
@@ -0,0 +1,166 @@
+package test_auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestTwoFactor(t *testing.T) {
+	router, authController := SetupAuthTestRouter()
+	db := authController.Db.Postgresql
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	user := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "two factor jane doe",
+		Email:    fmt.Sprintf("testtwofactor%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Create(&user)
+
+	router.POST("/api/v1/auth/login", authController.LoginUser)
+
+	send := func(path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	login := func() map[string]interface{} {
+		resp := send("/api/v1/auth/login", "", models.LoginRequestModel{Email: user.Email, Password: currUUID})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		return tests.ParseResponse(resp)["data"].(map[string]interface{})
+	}
+
+	verify := func(challenge, code string) *httptest.ResponseRecorder {
+		return send("/api/v1/auth/2fa/verify", "", models.VerifyTwoFactorRequest{ChallengeToken: challenge, Code: code})
+	}
+
+	token := login()["access_token"].(string)
+
+	var (
+		secret        string
+		recoveryCodes []interface{}
+	)
+
+	t.Run("Enroll", func(t *testing.T) {
+		resp := send("/api/v1/auth/2fa/enroll", token, nil)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tests.ParseResponse(resp)["data"].(map[string]interface{})
+		secret = data["secret"].(string)
+		if secret == "" || data["otpauth_uri"].(string) == "" {
+			t.Fatalf("expected a secret and an otpauth uri")
+		}
+		if _, ok := data["qr_data"]; ok {
+			t.Errorf("expected the otpauth uri only once")
+		}
+	})
+
+	t.Run("Confirm With Wrong Code", func(t *testing.T) {
+		resp := send("/api/v1/auth/2fa/confirm", token, models.TwoFactorCodeRequest{Code: "000000"})
+		tests.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		code, _ := utility.TOTPCode(secret, utility.TOTPStep(time.Now()))
+		resp := send("/api/v1/auth/2fa/confirm", token, models.TwoFactorCodeRequest{Code: code})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		recoveryCodes = tests.ParseResponse(resp)["data"].(map[string]interface{})["recovery_codes"].([]interface{})
+		if len(recoveryCodes) != 10 {
+			t.Fatalf("expected 10 recovery codes, got %v", len(recoveryCodes))
+		}
+	})
+
+	t.Run("Login Requires Challenge", func(t *testing.T) {
+		data := login()
+		if data["two_factor_required"] != true || data["access_token"] != nil {
+			t.Fatalf("expected a challenge instead of a session")
+		}
+
+		resp := verify(data["challenge_token"].(string), "000000")
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Verify With TOTP Code", func(t *testing.T) {
+		// the confirm step spent the current step, so use the next one
+		code, _ := utility.TOTPCode(secret, utility.TOTPStep(time.Now())+1)
+
+		challenge := login()["challenge_token"].(string)
+		resp := verify(challenge, code)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+		if tests.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string) == "" {
+			t.Errorf("expected an access token")
+		}
+
+		resp = verify(challenge, code)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = verify(login()["challenge_token"].(string), code)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Verify With Recovery Code", func(t *testing.T) {
+		code := recoveryCodes[0].(string)
+
+		resp := verify(login()["challenge_token"].(string), code)
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = verify(login()["challenge_token"].(string), code)
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Challenge Attempts Are Limited", func(t *testing.T) {
+		challenge := login()["challenge_token"].(string)
+		for i := 0; i < 5; i++ {
+			verify(challenge, "000000")
+		}
+
+		resp := verify(challenge, recoveryCodes[1].(string))
+		tests.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Failures Are Counted Across Challenges", func(t *testing.T) {
+		// each login is a correct password and a fresh challenge, neither of
+		// which may reset the count
+		var resp *httptest.ResponseRecorder
+		for i := 0; i < 6; i++ {
+			resp = verify(login()["challenge_token"].(string), "000000")
+			if resp.Code == http.StatusTooManyRequests {
+				break
+			}
+		}
+
+		tests.AssertStatusCode(t, resp.Code, http.StatusTooManyRequests)
+		if resp.Header().Get("Retry-After") == "" {
+			t.Error("expected a Retry-After header")
+		}
+	})
+
+	t.Run("Disable", func(t *testing.T) {
+		resp := send("/api/v1/auth/2fa/disable", token, models.TwoFactorCodeRequest{Code: recoveryCodes[2].(string)})
+		tests.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		if login()["access_token"] == nil {
+			t.Errorf("expected login to issue a session once 2FA is off")
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package test_import
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/importer"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func writeSlackExport(t *testing.T, dir, suffix string) string {
+	path := filepath.Join(dir, "slack.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	channelName := "general-" + suffix
+	contents := map[string]interface{}{
+		"users.json": []map[string]interface{}{
+			{"id": "U1" + suffix, "name": "ada" + suffix, "real_name": "Ada Lovelace"},
+			{"id": "U2" + suffix, "name": "alan" + suffix, "real_name": "Alan Turing"},
+		},
+		"channels.json": []map[string]interface{}{
+			{"id": "C1" + suffix, "name": channelName, "members": []string{"U1" + suffix, "U2" + suffix}, "purpose": map[string]string{"value": "general chat"}},
+		},
+		channelName + "/2020-01-01.json": []map[string]interface{}{
+			{"type": "message", "user": "U1" + suffix, "text": "hello", "ts": "1577836800.000100"},
+			{"type": "message", "subtype": "channel_join", "user": "U2" + suffix, "text": "joined", "ts": "1577836801.000100"},
+			{"type": "message", "user": "U2" + suffix, "text": "hi ada", "ts": "1577836802.000100"},
+		},
+		channelName + "/2020-01-02.json": []map[string]interface{}{
+			{"type": "message", "user": "U1" + suffix, "text": "next day", "ts": "1577923200.000100"},
+		},
+	}
+
+	w := zip.NewWriter(file)
+	for name, content := range contents {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewEncoder(f).Encode(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeDiscordExport(t *testing.T, dir, suffix string) string {
+	path := filepath.Join(dir, "discord.json")
+	content := map[string]interface{}{
+		"channel": map[string]string{"id": "D1" + suffix, "name": "lobby-" + suffix, "topic": "discord lobby"},
+		"messages": []map[string]interface{}{
+			{"id": "M1" + suffix, "type": "Default", "timestamp": "2021-03-04T10:00:00+00:00", "content": "first", "author": map[string]string{"id": "A1" + suffix, "name": "grace" + suffix}},
+			{"id": "M2" + suffix, "type": "ChannelPinnedMessage", "timestamp": "2021-03-04T10:01:00+00:00", "content": "pinned", "author": map[string]string{"id": "A1" + suffix, "name": "grace" + suffix}},
+			{"id": "M3" + suffix, "type": "Reply", "timestamp": "2021-03-04T10:02:00+00:00", "content": "second", "author": map[string]string{"id": "A2" + suffix, "name": "linus" + suffix}},
+		},
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImport(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+	userSignUpData := models.CreateUserRequestModel{
+		Email:       fmt.Sprintf("testuser%v@qa.team", currUUID),
+		PhoneNumber: fmt.Sprintf("+234%v", utility.GetRandomNumbersInRange(7000000000, 9099999999)),
+		FirstName:   "test",
+		LastName:    "user",
+		Password:    "password",
+		UserName:    fmt.Sprintf("test_username%v", currUUID),
+	}
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, userSignUpData, false)
+
+	var owner models.User
+	owner, err := owner.GetUserByEmail(db.Postgresql, userSignUpData.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		Name     string
+		Provider string
+		Path     string
+		Rooms    int
+		Users    int
+		Messages int
+	}{
+		{
+			Name:     "Slack Export",
+			Provider: models.ImportProviderSlack,
+			Path:     writeSlackExport(t, dir, currUUID),
+			Rooms:    1,
+			Users:    2,
+			Messages: 3,
+		},
+		{
+			Name:     "Discord Export",
+			Provider: models.ImportProviderDiscord,
+			Path:     writeDiscordExport(t, dir, currUUID),
+			Rooms:    1,
+			Users:    2,
+			Messages: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			src, err := importer.Open(test.Provider, test.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := importer.Run(db.Postgresql, src, owner.ID)
+			src.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.RoomsCreated != test.Rooms || result.UsersCreated != test.Users || result.MessagesCreated != test.Messages {
+				t.Errorf("unexpected import result: %+v", result)
+			}
+
+			// a second run must not duplicate anything
+			src, err = importer.Open(test.Provider, test.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err = importer.Run(db.Postgresql, src, owner.ID)
+			src.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.RoomsCreated != 0 || result.UsersCreated != 0 || result.MessagesCreated != 0 || result.MessagesSkipped != test.Messages {
+				t.Errorf("re-run was not idempotent: %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package test_middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestRateLimit(t *testing.T) {
+	tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	var (
+		rdb        = storage.Connection().Redis
+		remoteAddr = fmt.Sprintf("203.0.113.%v:4000", utility.GetRandomNumbersInRange(1, 254))
+	)
+
+	ipPolicy := middleware.RateLimitPolicy{Name: "test-ip-" + utility.GenerateUUID(), Limit: 3, Window: time.Minute, Key: middleware.ByIP}
+	userPolicy := middleware.RateLimitPolicy{Name: "test-user-" + utility.GenerateUUID(), Limit: 1, Window: time.Minute, Key: middleware.ByUser}
+	refreshPolicy := middleware.RateLimitPolicy{Name: "test-refresh-" + utility.GenerateUUID(), Limit: 1, Window: time.Minute, Key: middleware.ByRefreshToken}
+
+	r := gin.Default()
+	r.GET("/ip", middleware.RateLimit(rdb, ipPolicy), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.GET("/user", func(c *gin.Context) {
+		c.Set("userClaims", jwt.MapClaims{"user_id": c.Query("user")})
+	}, middleware.RateLimit(rdb, userPolicy), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	r.POST("/refresh", middleware.RateLimit(rdb, refreshPolicy), func(c *gin.Context) {
+		var req models.RefreshTokenRequest
+		if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("Limits Per IP", func(t *testing.T) {
+		for i := 2; i >= 0; i-- {
+			resp := get("/ip")
+			tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+			if got := resp.Header().Get("RateLimit-Remaining"); got != fmt.Sprint(i) {
+				t.Errorf("expected RateLimit-Remaining %v, got %q", i, got)
+			}
+			if resp.Header().Get("RateLimit-Limit") != "3" || resp.Header().Get("RateLimit-Reset") == "" {
+				t.Errorf("expected RateLimit-Limit and RateLimit-Reset headers")
+			}
+		}
+
+		resp := get("/ip")
+		tst.AssertStatusCode(t, resp.Code, http.StatusTooManyRequests)
+		if resp.Header().Get("Retry-After") == "" {
+			t.Errorf("expected a Retry-After header")
+		}
+	})
+
+	t.Run("Limits Per User", func(t *testing.T) {
+		first, second := utility.GenerateUUID(), utility.GenerateUUID()
+
+		tst.AssertStatusCode(t, get("/user?user="+first).Code, http.StatusOK)
+		tst.AssertStatusCode(t, get("/user?user="+first).Code, http.StatusTooManyRequests)
+		tst.AssertStatusCode(t, get("/user?user="+second).Code, http.StatusOK)
+	})
+
+	t.Run("Limits Refresh Per Token", func(t *testing.T) {
+		refresh := func(token string) int {
+			body, _ := json.Marshal(models.RefreshTokenRequest{RefreshToken: token})
+			req, _ := http.NewRequest(http.MethodPost, "/refresh", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.RemoteAddr = remoteAddr
+
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			return resp.Code
+		}
+		first, second := utility.GenerateUUID(), utility.GenerateUUID()
+
+		// the handler still sees the body the key was read from
+		tst.AssertStatusCode(t, refresh(first), http.StatusOK)
+		tst.AssertStatusCode(t, refresh(first), http.StatusTooManyRequests)
+		tst.AssertStatusCode(t, refresh(second), http.StatusOK)
+	})
+}
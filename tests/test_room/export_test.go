@@ -0,0 +1,198 @@
+package test_room
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/room"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/services/export"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestRoomExport(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+	userSignUpData := models.CreateUserRequestModel{
+		Email:       fmt.Sprintf("testuser%v@qa.team", currUUID),
+		PhoneNumber: fmt.Sprintf("+234%v", utility.GetRandomNumbersInRange(7000000000, 9099999999)),
+		FirstName:   "test",
+		LastName:    "user",
+		Password:    "password",
+		UserName:    fmt.Sprintf("test_username%v", currUUID),
+	}
+	loginData := models.LoginRequestModel{
+		Email:    userSignUpData.Email,
+		Password: userSignUpData.Password,
+	}
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	roomController := room.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, userSignUpData, false)
+
+	token := tst.GetLoginToken(t, r, auth, loginData)
+
+	createRoomReq := models.CreateRoomRequest{
+		Name:        fmt.Sprintf("TestRoom%s", utility.GenerateUUID()),
+		Description: "This is a test room",
+		Username:    userSignUpData.UserName,
+	}
+
+	roomId, _ := tst.CreateRoom(t, r, roomController, db, createRoomReq, token)
+
+	r = gin.Default()
+	roomUrl := r.Group(fmt.Sprintf("%v", "/api/v1/rooms"), middleware.Authorize(db.Postgresql))
+	{
+		roomUrl.POST("/:roomId/exports", roomController.CreateRoomExport)
+		roomUrl.GET("/:roomId/exports/:exportId", roomController.GetRoomExport)
+	}
+	r.GET("/api/v1/exports/:exportId/download", roomController.DownloadRoomExport)
+
+	var exportId string
+
+	t.Run("Invalid Export Format", func(t *testing.T) {
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(models.CreateRoomExportRequest{Format: "pdf"})
+
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/exports", roomId), &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Request Room Export", func(t *testing.T) {
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(models.CreateRoomExportRequest{Format: models.ExportFormatCSV})
+
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/exports", roomId), &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusAccepted)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["message"].(string), "room export requested successfully")
+		exportId = data["data"].(map[string]interface{})["id"].(string)
+	})
+
+	t.Run("Process And Download Room Export", func(t *testing.T) {
+		db.Postgresql.Create(&models.Message{Content: "hello, export", RoomID: roomId, UserID: userIDFromToken(t, token)})
+
+		var pending models.RoomExport
+		if err := db.Postgresql.First(&pending, "id = ?", exportId).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := export.ProcessRoomExport(db.Postgresql, db.Redis, pending); err != nil {
+			t.Fatal(err)
+		}
+
+		var completed models.RoomExport
+		db.Postgresql.First(&completed, "id = ?", exportId)
+		if completed.Status != models.ExportCompleted {
+			t.Fatalf("expected export to be completed, got %v", completed.Status)
+		}
+
+		if len(completed.DownloadTokenHash) != 64 {
+			t.Fatalf("expected only a hash of the download token to be stored, got %v", completed.DownloadTokenHash)
+		}
+
+		var downloadLink string
+		for _, data := range tst.QueuedNotifications(t, db, names.SendRoomExportReady) {
+			var ready models.SendRoomExportReady
+			if json.Unmarshal([]byte(data), &ready) == nil && strings.Contains(ready.DownloadLink, exportId) {
+				downloadLink = ready.DownloadLink
+				break
+			}
+		}
+		link, err := url.Parse(downloadLink)
+		if err != nil || link.Query().Get("token") == "" {
+			t.Fatalf("expected a download link to be queued, got %q", downloadLink)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/exports/%s/download?token=%s", exportId, url.QueryEscape(link.Query().Get("token"))), nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		if !strings.Contains(rr.Body.String(), "hello, export") {
+			t.Errorf("expected transcript to contain message, got %v", rr.Body.String())
+		}
+	})
+
+	t.Run("Download With Invalid Token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/exports/%s/download?token=wrong", exportId), nil)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusNotFound)
+	})
+
+	t.Run("Mbox Headers Stay On One Line", func(t *testing.T) {
+		db.Postgresql.Model(&models.Room{}).Where("id = ?", roomId).Update("name", "Room\r\nBcc: victim@example.com")
+		db.Postgresql.Create(&models.Message{Content: "hi", RoomID: roomId, UserID: userIDFromToken(t, token), Username: "eve\r\nX-Injected: yes"})
+
+		mbox := models.RoomExport{ID: utility.GenerateUUID(), RoomID: roomId, RequestedBy: userIDFromToken(t, token), Format: models.ExportFormatMbox}
+		if err := mbox.CreateRoomExport(db.Postgresql); err != nil {
+			t.Fatal(err)
+		}
+		if err := export.ProcessRoomExport(db.Postgresql, db.Redis, mbox); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Postgresql.First(&mbox, "id = ?", mbox.ID)
+		data, err := os.ReadFile(mbox.FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "Bcc:") || strings.HasPrefix(line, "X-Injected:") {
+				t.Errorf("expected user text not to start a header, got %q", line)
+			}
+		}
+	})
+
+	t.Run("Expired Export File Is Deleted", func(t *testing.T) {
+		var completed models.RoomExport
+		db.Postgresql.First(&completed, "id = ?", exportId)
+		db.Postgresql.Model(&completed).Update("expires_at", time.Now().Add(-time.Minute))
+
+		if _, err := export.PurgeExpiredExports(db.Postgresql, time.Now(), 100); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := os.Stat(completed.FilePath); !os.IsNotExist(err) {
+			t.Errorf("expected the export file to be deleted, got %v", err)
+		}
+
+		var expired models.RoomExport
+		db.Postgresql.First(&expired, "id = ?", exportId)
+		tst.AssertResponseMessage(t, expired.Status, models.ExportExpired)
+	})
+}
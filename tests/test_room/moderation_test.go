@@ -0,0 +1,178 @@
+package test_room
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/room"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/moderation"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestModeration(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+
+	owner, member, outsider := tst.NewSignupRequest(), tst.NewSignupRequest(), tst.NewSignupRequest()
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	roomController := room.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, owner, false)
+	tst.SignupUser(t, r, auth, member, false)
+	tst.SignupUser(t, r, auth, outsider, false)
+
+	ownerToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: owner.Email, Password: owner.Password})
+	memberToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: member.Email, Password: member.Password})
+	outsiderToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: outsider.Email, Password: outsider.Password})
+
+	createRoomReq := models.CreateRoomRequest{
+		Name:        fmt.Sprintf("TestRoom%s", utility.GenerateUUID()),
+		Description: "This is a test room",
+		Username:    owner.UserName,
+	}
+
+	roomId, _ := tst.CreateRoom(t, r, roomController, db, createRoomReq, ownerToken)
+
+	r = gin.Default()
+	roomUrl := r.Group(fmt.Sprintf("%v", "/api/v1/rooms"), middleware.Authorize(db.Postgresql))
+	{
+		roomUrl.POST("/:roomId/join", roomController.JoinRoom)
+		roomUrl.POST("/:roomId/messages", roomController.AddRoomMsg)
+		roomUrl.POST("/:roomId/messages/:messageId/report", roomController.ReportMessage)
+		roomUrl.GET("/:roomId/reports", roomController.GetRoomReports)
+		roomUrl.PATCH("/:roomId/reports/:reportId", roomController.ResolveReport)
+		roomUrl.POST("/:roomId/word-filters", roomController.CreateWordFilter)
+	}
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(body)
+
+		req, _ := http.NewRequest(method, path, &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	messagesPath := fmt.Sprintf("/api/v1/rooms/%s/messages", roomId)
+	filtersPath := fmt.Sprintf("/api/v1/rooms/%s/word-filters", roomId)
+	memberId := userIDFromToken(t, memberToken)
+
+	rr := send(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/join", roomId), memberToken, models.JoinRoomRequest{Username: member.UserName})
+	tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+	t.Run("Members Cannot Manage Filters", func(t *testing.T) {
+		rr := send(http.MethodPost, filtersPath, memberToken, models.CreateWordFilterRequest{Word: "darn", Action: models.FilterActionMask})
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Masked Word", func(t *testing.T) {
+		rr := send(http.MethodPost, filtersPath, ownerToken, models.CreateWordFilterRequest{Word: "darn", Action: models.FilterActionMask})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		rr = send(http.MethodPost, messagesPath, memberToken, models.CreateMessageRequest{Content: "darn it"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		var message models.Message
+		db.Postgresql.Where("room_id = ? AND user_id = ?", roomId, memberId).Order("id desc").First(&message)
+		if message.Content != "**** it" {
+			t.Errorf("expected masked content, got %q", message.Content)
+		}
+	})
+
+	t.Run("Blocked Word", func(t *testing.T) {
+		rr := send(http.MethodPost, filtersPath, ownerToken, models.CreateWordFilterRequest{Word: "spoiler", Action: models.FilterActionBlock})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		rr = send(http.MethodPost, messagesPath, memberToken, models.CreateMessageRequest{Content: "big SPOILER ahead"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Filter Changes Apply Immediately", func(t *testing.T) {
+		rr := send(http.MethodPost, messagesPath, memberToken, models.CreateMessageRequest{Content: "heck yes"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		rr = send(http.MethodPost, filtersPath, ownerToken, models.CreateWordFilterRequest{Word: "heck", Action: models.FilterActionBlock})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		rr = send(http.MethodPost, messagesPath, memberToken, models.CreateMessageRequest{Content: "heck yes"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Non Members Are Rejected Before Filtering", func(t *testing.T) {
+		rr := send(http.MethodPost, messagesPath, outsiderToken, models.CreateMessageRequest{Content: "big spoiler ahead"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Report And Remove Message", func(t *testing.T) {
+		rr := send(http.MethodPost, messagesPath, ownerToken, models.CreateMessageRequest{Content: "report me"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		var message models.Message
+		db.Postgresql.Where("room_id = ? AND content = ?", roomId, "report me").First(&message)
+		reportPath := fmt.Sprintf("/api/v1/rooms/%s/messages/%d/report", roomId, message.ID)
+
+		rr = send(http.MethodPost, reportPath, memberToken, models.CreateReportRequest{Reason: "rude"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+		reportId := tst.ParseResponse(rr)["data"].(map[string]interface{})["id"].(string)
+
+		rr = send(http.MethodPost, reportPath, memberToken, models.CreateReportRequest{Reason: "rude"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusConflict)
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/v1/rooms/%s/reports", roomId), memberToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnauthorized)
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/v1/rooms/%s/reports", roomId), ownerToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		if reports := tst.ParseResponse(rr)["data"].([]interface{}); len(reports) != 1 {
+			t.Errorf("expected 1 open report, got %d", len(reports))
+		}
+
+		rr = send(http.MethodPatch, fmt.Sprintf("/api/v1/rooms/%s/reports/%s", roomId, reportId), ownerToken, models.ResolveReportRequest{Action: "remove"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		var count int64
+		db.Postgresql.Model(&models.Message{}).Where("id = ?", message.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("expected reported message to be removed")
+		}
+	})
+
+	t.Run("Link Filter", func(t *testing.T) {
+		filter := moderation.NewLinkFilter([]string{"telex.im"}, []string{"bad.example"})
+
+		verdict, _ := filter.Check(moderation.Input{Content: "see https://docs.telex.im/start"})
+		if verdict.Action != moderation.Allow {
+			t.Errorf("expected allowlisted link to pass, got %v", verdict.Action)
+		}
+
+		verdict, _ = filter.Check(moderation.Input{Content: "see www.bad.example/x"})
+		if verdict.Action != moderation.Block {
+			t.Errorf("expected denylisted link to be blocked, got %v", verdict.Action)
+		}
+
+		verdict, _ = filter.Check(moderation.Input{Content: "see http://other.org"})
+		if verdict.Action != moderation.Flag {
+			t.Errorf("expected unknown link to be flagged, got %v", verdict.Action)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package test_room
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/room"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestRoomRetention(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+	userSignUpData := models.CreateUserRequestModel{
+		Email:       fmt.Sprintf("testuser%v@qa.team", currUUID),
+		PhoneNumber: fmt.Sprintf("+234%v", utility.GetRandomNumbersInRange(7000000000, 9099999999)),
+		FirstName:   "test",
+		LastName:    "user",
+		Password:    "password",
+		UserName:    fmt.Sprintf("test_username%v", currUUID),
+	}
+	loginData := models.LoginRequestModel{
+		Email:    userSignUpData.Email,
+		Password: userSignUpData.Password,
+	}
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	roomController := room.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, userSignUpData, false)
+
+	token := tst.GetLoginToken(t, r, auth, loginData)
+
+	createRoomReq := models.CreateRoomRequest{
+		Name:        fmt.Sprintf("TestRoom%s", utility.GenerateUUID()),
+		Description: "This is a test room",
+		Username:    userSignUpData.UserName,
+	}
+
+	roomId, _ := tst.CreateRoom(t, r, roomController, db, createRoomReq, token)
+
+	r = gin.Default()
+	roomUrl := r.Group(fmt.Sprintf("%v", "/api/v1/rooms"), middleware.Authorize(db.Postgresql))
+	{
+		roomUrl.PATCH("/:roomId/retention", roomController.UpdateRoomRetention)
+	}
+
+	t.Run("Update Room Retention", func(t *testing.T) {
+		days := 7
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(models.UpdateRoomRetentionRequest{RetentionDays: &days})
+
+		req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/rooms/%s/retention", roomId), &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["message"].(string), "room retention updated successfully")
+	})
+
+	t.Run("Owner Cannot Set Legal Hold", func(t *testing.T) {
+		hold := false
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(models.UpdateRoomRetentionRequest{LegalHold: &hold})
+
+		req, _ := http.NewRequest(http.MethodPatch, fmt.Sprintf("/api/v1/rooms/%s/retention", roomId), &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusForbidden)
+	})
+
+	t.Run("Purge Expired Messages", func(t *testing.T) {
+		var roomModel models.Room
+		roomModel, err := roomModel.GetRoomByID(db.Postgresql, roomId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		old := models.Message{Content: "old", RoomID: roomId, UserID: roomModel.OwnerId, CreatedAt: time.Now().AddDate(0, 0, -30)}
+		fresh := models.Message{Content: "fresh", RoomID: roomId, UserID: roomModel.OwnerId}
+		db.Postgresql.Create(&old)
+		db.Postgresql.Create(&fresh)
+
+		purged, err := roomModel.PurgeMessagesBefore(db.Postgresql, time.Now().AddDate(0, 0, -roomModel.RetentionDays), 10, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if purged != 1 {
+			t.Errorf("expected 1 purged message, got %d", purged)
+		}
+
+		var archived models.ArchivedMessage
+		if err := db.Postgresql.First(&archived, "id = ?", old.ID).Error; err != nil {
+			t.Errorf("expected purged message to be archived: %v", err)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package test_room
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/room"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestRoomEvents(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+
+	owner, member, other := tst.NewSignupRequest(), tst.NewSignupRequest(), tst.NewSignupRequest()
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	extReq := request.ExternalRequest{Logger: logger, Test: true}
+	roomController := room.Controller{Db: db, Validator: validatorRef, Logger: logger, ExtReq: extReq}
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, owner, false)
+	tst.SignupUser(t, r, auth, member, false)
+	tst.SignupUser(t, r, auth, other, false)
+
+	ownerToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: owner.Email, Password: owner.Password})
+	memberToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: member.Email, Password: member.Password})
+	otherToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: other.Email, Password: other.Password})
+
+	createRoomReq := models.CreateRoomRequest{
+		Name:        fmt.Sprintf("TestRoom%s", utility.GenerateUUID()),
+		Description: "This is a test room",
+		Username:    owner.UserName,
+	}
+
+	roomId, _ := tst.CreateRoom(t, r, roomController, db, createRoomReq, ownerToken)
+
+	r = gin.Default()
+	roomUrl := r.Group(fmt.Sprintf("%v", "/api/v1/rooms"), middleware.Authorize(db.Postgresql))
+	{
+		roomUrl.POST("/:roomId/join", roomController.JoinRoom)
+		roomUrl.POST("/:roomId/leave", roomController.LeaveRoom)
+		roomUrl.PATCH("/:roomId", roomController.UpdateRoom)
+		roomUrl.DELETE("/:roomId/members/:userId", roomController.KickMember)
+		roomUrl.POST("/:roomId/transfer-ownership", roomController.TransferOwnership)
+		roomUrl.GET("/:roomId/events", roomController.GetRoomEvents)
+	}
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(body)
+
+		req, _ := http.NewRequest(method, path, &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	eventsPath := fmt.Sprintf("/api/v1/rooms/%s/events", roomId)
+	memberId := userIDFromToken(t, memberToken)
+	otherId := userIDFromToken(t, otherToken)
+
+	rr := send(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/join", roomId), memberToken, models.JoinRoomRequest{Username: member.UserName})
+	tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+	rr = send(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/join", roomId), otherToken, models.JoinRoomRequest{Username: other.UserName})
+	tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+	t.Run("Join Leaves System Message", func(t *testing.T) {
+		var message models.Message
+		err := db.Postgresql.Where("room_id = ? AND user_id = ? AND type = ?", roomId, memberId, models.RoomEventMemberJoined).First(&message).Error
+		if err != nil {
+			t.Errorf("expected a member_joined system message: %v", err)
+		}
+	})
+
+	t.Run("Rename Records Event", func(t *testing.T) {
+		rr := send(http.MethodPatch, fmt.Sprintf("/api/v1/rooms/%s", roomId), ownerToken, models.UpdateRoomRequest{Name: createRoomReq.Name + "x", Description: createRoomReq.Description})
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		rr = send(http.MethodGet, eventsPath+"?type="+models.RoomEventRoomRenamed, ownerToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		if events := tst.ParseResponse(rr)["data"].([]interface{}); len(events) != 1 {
+			t.Errorf("expected 1 room_renamed event, got %d", len(events))
+		}
+	})
+
+	t.Run("Members Cannot Read Events", func(t *testing.T) {
+		rr := send(http.MethodGet, eventsPath, memberToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Members Cannot Kick", func(t *testing.T) {
+		rr := send(http.MethodDelete, fmt.Sprintf("/api/v1/rooms/%s/members/%s", roomId, otherId), memberToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Kick Member", func(t *testing.T) {
+		rr := send(http.MethodDelete, fmt.Sprintf("/api/v1/rooms/%s/members/%s", roomId, otherId), ownerToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		var event models.RoomEvent
+		err := db.Postgresql.Where("room_id = ? AND type = ?", roomId, models.RoomEventMemberKicked).First(&event).Error
+		if err != nil {
+			t.Fatalf("expected a member_kicked event: %v", err)
+		}
+		if event.TargetID == nil || *event.TargetID != otherId {
+			t.Errorf("expected kick to target %v", otherId)
+		}
+	})
+
+	t.Run("Transfer Ownership", func(t *testing.T) {
+		rr := send(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/transfer-ownership", roomId), memberToken, models.TransferOwnershipRequest{UserID: memberId})
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnauthorized)
+
+		rr = send(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/transfer-ownership", roomId), ownerToken, models.TransferOwnershipRequest{UserID: memberId})
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		rr = send(http.MethodGet, eventsPath, memberToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+	})
+}
@@ -0,0 +1,245 @@
+package test_room
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/room"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	roomService "github.com/hngprojects/telex_be/services/room"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestScheduledMessages(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+	userSignUpData := models.CreateUserRequestModel{
+		Email:       fmt.Sprintf("testuser%v@qa.team", currUUID),
+		PhoneNumber: fmt.Sprintf("+234%v", utility.GetRandomNumbersInRange(7000000000, 9099999999)),
+		FirstName:   "test",
+		LastName:    "user",
+		Password:    "password",
+		UserName:    fmt.Sprintf("test_username%v", currUUID),
+	}
+	loginData := models.LoginRequestModel{
+		Email:    userSignUpData.Email,
+		Password: userSignUpData.Password,
+	}
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	roomController := room.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, userSignUpData, false)
+
+	token := tst.GetLoginToken(t, r, auth, loginData)
+
+	createRoomReq := models.CreateRoomRequest{
+		Name:        fmt.Sprintf("TestRoom%s", utility.GenerateUUID()),
+		Description: "This is a test room",
+		Username:    userSignUpData.UserName,
+	}
+
+	roomId, _ := tst.CreateRoom(t, r, roomController, db, createRoomReq, token)
+
+	r = gin.Default()
+	roomUrl := r.Group(fmt.Sprintf("%v", "/api/v1/rooms"), middleware.Authorize(db.Postgresql))
+	{
+		roomUrl.POST("/:roomId/leave", roomController.LeaveRoom)
+		roomUrl.POST("/:roomId/scheduled-messages", roomController.CreateScheduledMessage)
+		roomUrl.GET("/:roomId/scheduled-messages", roomController.GetScheduledMessages)
+		roomUrl.PATCH("/:roomId/scheduled-messages/:scheduledId", roomController.UpdateScheduledMessage)
+		roomUrl.DELETE("/:roomId/scheduled-messages/:scheduledId", roomController.CancelScheduledMessage)
+	}
+
+	send := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(body)
+		req, err := http.NewRequest(method, (&url.URL{Path: path}).String(), &b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	scheduledPath := fmt.Sprintf("/api/v1/rooms/%s/scheduled-messages", roomId)
+	var scheduledId string
+
+	t.Run("Schedule Message In The Past", func(t *testing.T) {
+		rr := send(http.MethodPost, scheduledPath, models.CreateScheduledMessageRequest{
+			Content: "too late",
+			SendAt:  time.Now().Add(-time.Hour),
+		})
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusBadRequest)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["message"].(string), "send_at must be in the future")
+	})
+
+	t.Run("Schedule Message", func(t *testing.T) {
+		rr := send(http.MethodPost, scheduledPath, models.CreateScheduledMessageRequest{
+			Content: "see you tomorrow",
+			SendAt:  time.Now().Add(time.Hour),
+		})
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["message"].(string), "message scheduled successfully")
+		scheduledId = data["data"].(map[string]interface{})["id"].(string)
+	})
+
+	t.Run("List Scheduled Messages", func(t *testing.T) {
+		rr := send(http.MethodGet, scheduledPath, nil)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["message"].(string), "scheduled messages fetched successfully")
+		if len(data["data"].([]interface{})) != 1 {
+			t.Errorf("expected 1 scheduled message, got %d", len(data["data"].([]interface{})))
+		}
+	})
+
+	t.Run("Edit Scheduled Message", func(t *testing.T) {
+		rr := send(http.MethodPatch, scheduledPath+"/"+scheduledId, models.UpdateScheduledMessageRequest{
+			Content: "see you later",
+		})
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["data"].(map[string]interface{})["content"].(string), "see you later")
+	})
+
+	t.Run("Edit After Claim Conflicts", func(t *testing.T) {
+		scheduled := models.ScheduledMessage{}
+		db.Postgresql.First(&scheduled, "id = ?", scheduledId)
+
+		claimed, err := scheduled.Claim(db.Postgresql)
+		if err != nil || !claimed {
+			t.Fatalf("expected to claim scheduled message, got %v, %v", claimed, err)
+		}
+
+		rr := send(http.MethodPatch, scheduledPath+"/"+scheduledId, models.UpdateScheduledMessageRequest{
+			Content: "too late to edit",
+		})
+		tst.AssertStatusCode(t, rr.Code, http.StatusBadRequest)
+
+		// a stale read that slipped past the pending check must not win
+		scheduled.Content = "too late to edit"
+		updated, err := scheduled.UpdatePending(db.Postgresql)
+		if err != nil || updated {
+			t.Errorf("expected no update to a claimed message, got %v, %v", updated, err)
+		}
+
+		db.Postgresql.Model(&scheduled).Update("status", models.ScheduledMessagePending)
+	})
+
+	t.Run("Abandoned Claim Is Picked Up Again", func(t *testing.T) {
+		scheduled := models.ScheduledMessage{}
+		db.Postgresql.First(&scheduled, "id = ?", scheduledId)
+
+		claimed, err := scheduled.Claim(db.Postgresql)
+		if err != nil || !claimed {
+			t.Fatalf("expected to claim scheduled message, got %v, %v", claimed, err)
+		}
+
+		claimed, _ = scheduled.Claim(db.Postgresql)
+		if claimed {
+			t.Errorf("expected a live claim to block a second worker")
+		}
+
+		db.Postgresql.Model(&scheduled).Update("claimed_at", time.Now().Add(-time.Hour))
+		claimed, err = scheduled.Claim(db.Postgresql)
+		if err != nil || !claimed {
+			t.Errorf("expected a timed out claim to be taken over, got %v, %v", claimed, err)
+		}
+
+		db.Postgresql.Model(&scheduled).Update("status", models.ScheduledMessagePending)
+	})
+
+	t.Run("Cancel Scheduled Message", func(t *testing.T) {
+		rr := send(http.MethodDelete, scheduledPath+"/"+scheduledId, nil)
+
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["message"].(string), "scheduled message cancelled successfully")
+	})
+
+	t.Run("Due Message Is Posted", func(t *testing.T) {
+		scheduled := models.ScheduledMessage{
+			ID:      utility.GenerateUUID(),
+			Content: "posted by the worker",
+			RoomID:  roomId,
+			UserID:  userIDFromToken(t, token),
+			SendAt:  time.Now().Add(-time.Minute),
+		}
+		if err := scheduled.CreateScheduledMessage(db.Postgresql); err != nil {
+			t.Fatal(err)
+		}
+
+		claimed, err := scheduled.Claim(db.Postgresql)
+		if err != nil || !claimed {
+			t.Fatalf("expected to claim scheduled message, got %v, %v", claimed, err)
+		}
+
+		if err := roomService.SendScheduledMessage(db.Postgresql, db.Redis, logger, scheduled); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Postgresql.First(&scheduled, "id = ?", scheduled.ID)
+		tst.AssertResponseMessage(t, scheduled.Status, models.ScheduledMessageSent)
+	})
+
+	t.Run("Due Message Is Cancelled After Author Leaves", func(t *testing.T) {
+		scheduled := models.ScheduledMessage{
+			ID:      utility.GenerateUUID(),
+			Content: "never posted",
+			RoomID:  roomId,
+			UserID:  userIDFromToken(t, token),
+			SendAt:  time.Now().Add(-time.Minute),
+		}
+		if err := scheduled.CreateScheduledMessage(db.Postgresql); err != nil {
+			t.Fatal(err)
+		}
+
+		rr := send(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/leave", roomId), nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		if err := roomService.SendScheduledMessage(db.Postgresql, db.Redis, logger, scheduled); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Postgresql.First(&scheduled, "id = ?", scheduled.ID)
+		tst.AssertResponseMessage(t, scheduled.Status, models.ScheduledMessageCancelled)
+	})
+}
+
+func userIDFromToken(t *testing.T, token string) string {
+	parsed, err := middleware.TokenValid(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	return claims["user_id"].(string)
+}
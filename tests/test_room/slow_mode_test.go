@@ -0,0 +1,147 @@
+package test_room
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/room"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	roomService "github.com/hngprojects/telex_be/services/room"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestSlowMode(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+
+	owner, member := tst.NewSignupRequest(), tst.NewSignupRequest()
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	roomController := room.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, owner, false)
+	tst.SignupUser(t, r, auth, member, false)
+
+	ownerToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: owner.Email, Password: owner.Password})
+	memberToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: member.Email, Password: member.Password})
+
+	createRoomReq := models.CreateRoomRequest{
+		Name:        fmt.Sprintf("TestRoom%s", utility.GenerateUUID()),
+		Description: "This is a test room",
+		Username:    owner.UserName,
+	}
+
+	roomId, _ := tst.CreateRoom(t, r, roomController, db, createRoomReq, ownerToken)
+
+	r = gin.Default()
+	roomUrl := r.Group(fmt.Sprintf("%v", "/api/v1/rooms"), middleware.Authorize(db.Postgresql))
+	{
+		roomUrl.POST("/:roomId/join", roomController.JoinRoom)
+		roomUrl.POST("/:roomId/messages", roomController.AddRoomMsg)
+		roomUrl.PATCH("/:roomId/slow-mode", roomController.UpdateSlowMode)
+		roomUrl.PATCH("/:roomId/members/:userId/role", roomController.UpdateMemberRole)
+	}
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(body)
+
+		req, _ := http.NewRequest(method, path, &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	messagesPath := fmt.Sprintf("/api/v1/rooms/%s/messages", roomId)
+	message := models.CreateMessageRequest{Content: "hello"}
+
+	rr := send(http.MethodPost, fmt.Sprintf("/api/v1/rooms/%s/join", roomId), memberToken, models.JoinRoomRequest{Username: member.UserName})
+	tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+	t.Run("Only Moderators Can Set Slow Mode", func(t *testing.T) {
+		interval := 30
+		rr := send(http.MethodPatch, fmt.Sprintf("/api/v1/rooms/%s/slow-mode", roomId), memberToken, models.UpdateSlowModeRequest{Interval: &interval})
+		tst.AssertStatusCode(t, rr.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Set Slow Mode", func(t *testing.T) {
+		interval, burst := 30, 1
+		rr := send(http.MethodPatch, fmt.Sprintf("/api/v1/rooms/%s/slow-mode", roomId), ownerToken, models.UpdateSlowModeRequest{Interval: &interval, Burst: &burst})
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		data := tst.ParseResponse(rr)
+		tst.AssertResponseMessage(t, data["message"].(string), "slow mode updated successfully")
+	})
+
+	t.Run("Member Is Rate Limited", func(t *testing.T) {
+		rr := send(http.MethodPost, messagesPath, memberToken, message)
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		rr = send(http.MethodPost, messagesPath, memberToken, message)
+		tst.AssertStatusCode(t, rr.Code, http.StatusTooManyRequests)
+		if rr.Header().Get("Retry-After") == "" {
+			t.Errorf("expected a Retry-After header")
+		}
+	})
+
+	t.Run("Scheduled Message Waits For Slow Mode", func(t *testing.T) {
+		scheduled := models.ScheduledMessage{
+			ID:      utility.GenerateUUID(),
+			Content: "scheduled while limited",
+			RoomID:  roomId,
+			UserID:  userIDFromToken(t, memberToken),
+			SendAt:  time.Now().Add(-time.Minute),
+		}
+		if err := scheduled.CreateScheduledMessage(db.Postgresql); err != nil {
+			t.Fatal(err)
+		}
+
+		claimed, err := scheduled.Claim(db.Postgresql)
+		if err != nil || !claimed {
+			t.Fatalf("expected to claim scheduled message, got %v, %v", claimed, err)
+		}
+
+		if err := roomService.SendScheduledMessage(db.Postgresql, db.Redis, logger, scheduled); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Postgresql.First(&scheduled, "id = ?", scheduled.ID)
+		tst.AssertResponseMessage(t, scheduled.Status, models.ScheduledMessagePending)
+		if !scheduled.SendAt.After(time.Now()) {
+			t.Errorf("expected the message to be put back until the member may post, got %v", scheduled.SendAt)
+		}
+	})
+
+	t.Run("Owner Is Exempt", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			rr := send(http.MethodPost, messagesPath, ownerToken, message)
+			tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+		}
+	})
+
+	t.Run("Moderator Is Exempt", func(t *testing.T) {
+		memberId := userIDFromToken(t, memberToken)
+		rr := send(http.MethodPatch, fmt.Sprintf("/api/v1/rooms/%s/members/%s/role", roomId, memberId), ownerToken, models.UpdateMemberRoleRequest{Role: models.RoomRoleModerator})
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		rr = send(http.MethodPost, messagesPath, memberToken, message)
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+	})
+}
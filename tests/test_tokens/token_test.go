@@ -130,6 +130,12 @@ func TestToken(t *testing.T) {
 			genToken := data["data"].(map[string]interface{})["token"].(string)
 			tst.AssertBool(t, genToken != "", true)
 
+			// centrifugo tokens share the signing keys but must not work as
+			// access tokens
+			if _, err := middleware.TokenValid(genToken); err == nil {
+				t.Error("expected a centrifugo token to be rejected by the api")
+			}
+
 		})
 
 	}
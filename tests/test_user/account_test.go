@@ -0,0 +1,253 @@
+package test_user
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/user"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	"github.com/hngprojects/telex_be/services/actions/names"
+	"github.com/hngprojects/telex_be/services/export"
+	userService "github.com/hngprojects/telex_be/services/user"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestAccountExportAndDeletion(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+
+	me := tst.CreateUser(db.Postgresql, "accountme", currUUID)
+	other := tst.CreateUser(db.Postgresql, "accountother", currUUID)
+
+	room := models.Room{ID: utility.GenerateUUID(), Name: "account room " + currUUID, Description: "account room", OwnerId: me.ID}
+	db.Postgresql.Create(&room)
+	db.Postgresql.Create(&models.UserRoom{RoomID: room.ID, UserID: me.ID, Username: "accountme", Role: "owner"})
+	db.Postgresql.Create(&models.UserRoom{RoomID: room.ID, UserID: other.ID, Username: "accountother", CreatedAt: time.Now().Add(time.Minute)})
+	db.Postgresql.Create(&models.Message{Content: "hello, account export", RoomID: room.ID, UserID: me.ID, Username: "accountme"})
+
+	authController := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	userController := user.Controller{Db: db, Validator: validatorRef, Logger: logger}
+
+	r := gin.Default()
+	r.POST("/api/v1/auth/login", authController.LoginUser)
+
+	meUrl := r.Group("/api/v1/me", middleware.Authorize(db.Postgresql))
+	{
+		meUrl.POST("/export", userController.RequestExport)
+		meUrl.DELETE("", userController.DeleteMe)
+		meUrl.POST("/restore", userController.RestoreMe)
+	}
+	r.GET("/api/v1/account-exports/:exportId/download", userController.DownloadExport)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: me.Email, Password: currUUID})
+	tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+	token := tst.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+	var exportId string
+
+	t.Run("Request Export", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/me/export", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusAccepted)
+		exportId = tst.ParseResponse(resp)["data"].(map[string]interface{})["id"].(string)
+
+		resp = send(http.MethodPost, "/api/v1/me/export", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusConflict)
+	})
+
+	t.Run("Process And Download Export", func(t *testing.T) {
+		var pending models.AccountExport
+		if err := db.Postgresql.First(&pending, "id = ?", exportId).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := export.ProcessAccountExport(db.Postgresql, db.Redis, pending); err != nil {
+			t.Fatal(err)
+		}
+
+		var completed models.AccountExport
+		db.Postgresql.First(&completed, "id = ?", exportId)
+		if completed.Status != models.ExportCompleted {
+			t.Fatalf("expected export to be completed, got %v", completed.Status)
+		}
+
+		var downloadLink string
+		for _, data := range tst.QueuedNotifications(t, db, names.SendAccountExportReady) {
+			var ready models.SendAccountExportReady
+			if json.Unmarshal([]byte(data), &ready) == nil && strings.Contains(ready.DownloadLink, exportId) {
+				downloadLink = ready.DownloadLink
+				break
+			}
+		}
+		link, err := url.Parse(downloadLink)
+		if err != nil || link.Query().Get("token") == "" {
+			t.Fatalf("expected a download link to be queued, got %q", downloadLink)
+		}
+
+		resp := send(http.MethodGet, fmt.Sprintf("/api/v1/account-exports/%s/download?token=%s", exportId, url.QueryEscape(link.Query().Get("token"))), "", nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		archive, err := zip.NewReader(bytes.NewReader(resp.Body.Bytes()), int64(resp.Body.Len()))
+		if err != nil {
+			t.Fatalf("expected a zip archive, %v", err)
+		}
+
+		files := map[string]string{}
+		for _, f := range archive.File {
+			rc, _ := f.Open()
+			var buf bytes.Buffer
+			buf.ReadFrom(rc)
+			rc.Close()
+			files[f.Name] = buf.String()
+		}
+
+		for _, name := range []string{"profile.json", "sessions.json", "rooms.json", "messages.json"} {
+			if _, ok := files[name]; !ok {
+				t.Errorf("expected %v in the export", name)
+			}
+		}
+		if !bytes.Contains([]byte(files["messages.json"]), []byte("hello, account export")) {
+			t.Errorf("expected messages.json to contain the message, got %v", files["messages.json"])
+		}
+	})
+
+	t.Run("Download With Invalid Token", func(t *testing.T) {
+		resp := send(http.MethodGet, fmt.Sprintf("/api/v1/account-exports/%s/download?token=wrong", exportId), "", nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusNotFound)
+	})
+
+	t.Run("Delete Requires Password", func(t *testing.T) {
+		resp := send(http.MethodDelete, "/api/v1/me", token, models.ReauthRequest{Password: "wrong"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("Delete And Restore", func(t *testing.T) {
+		resp := send(http.MethodDelete, "/api/v1/me", token, models.ReauthRequest{Password: currUUID})
+		tst.AssertStatusCode(t, resp.Code, http.StatusAccepted)
+
+		data := tst.ParseResponse(resp)["data"].(map[string]interface{})
+		if data["delete_after"] == nil {
+			t.Errorf("expected the account to be scheduled for deletion")
+		}
+
+		resp = send(http.MethodPost, "/api/v1/me/restore", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = send(http.MethodPost, "/api/v1/me/restore", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Purge After Grace Period", func(t *testing.T) {
+		var userRoom models.UserRoom
+
+		renamed := "accountrenamed" + currUUID[:8]
+		activity, err := userRoom.UpdateUsername(db.Postgresql, models.UpdateRoomUserNameReq{Username: renamed}, room.ID, me.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		workspace := models.Workspace{ID: utility.GenerateUUID(), Name: "account workspace", OwnerID: me.ID}
+		if err := workspace.CreateWorkspace(db.Postgresql); err != nil {
+			t.Fatal(err)
+		}
+
+		resp := send(http.MethodDelete, "/api/v1/me", token, models.ReauthRequest{Password: currUUID})
+		tst.AssertStatusCode(t, resp.Code, http.StatusAccepted)
+
+		db.Postgresql.Model(&models.User{}).Where("id = ?", me.ID).Update("delete_after", time.Now().Add(-time.Minute))
+
+		due, err := models.GetUsersDueForDeletion(db.Postgresql, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var account *models.User
+		for i := range due {
+			if due[i].ID == me.ID {
+				account = &due[i]
+			}
+		}
+		if account == nil {
+			t.Fatalf("expected the account to be due for deletion")
+		}
+
+		if err := userService.PurgeAccount(db.Postgresql, *account); err != nil {
+			t.Fatal(err)
+		}
+
+		var count int64
+		db.Postgresql.Unscoped().Model(&models.User{}).Where("id = ? OR email = ?", me.ID, me.Email).Count(&count)
+		if count != 0 {
+			t.Errorf("expected the account to be erased")
+		}
+
+		db.Postgresql.Model(&models.UserRoom{}).Where("user_id = ?", me.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("expected room memberships to be removed")
+		}
+
+		var updatedRoom models.Room
+		db.Postgresql.First(&updatedRoom, "id = ?", room.ID)
+		if updatedRoom.OwnerId != other.ID {
+			t.Errorf("expected room ownership to pass to %v, got %v", other.ID, updatedRoom.OwnerId)
+		}
+
+		var message models.Message
+		db.Postgresql.Where("user_id = ? AND room_id = ?", me.ID, room.ID).First(&message)
+		if message.Username != models.DeletedUsername {
+			t.Errorf("expected the message to be anonymised, got %v", message.Username)
+		}
+
+		db.Postgresql.Model(&models.AccessToken{}).Where("owner_id = ?", me.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("expected sessions to be removed")
+		}
+
+		db.Postgresql.Model(&models.Workspace{}).Where("id = ?", workspace.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("expected the workspace nobody else is in to be deleted")
+		}
+
+		var event models.RoomEvent
+		db.Postgresql.First(&event, "id = ?", activity.Event.ID)
+		if strings.Contains(event.Metadata, "accountme") || strings.Contains(event.Metadata, renamed) {
+			t.Errorf("expected the event to drop the username, got %v", event.Metadata)
+		}
+
+		var systemMessage models.Message
+		db.Postgresql.First(&systemMessage, "id = ?", activity.Message.ID)
+		if want := fmt.Sprintf("%v is now known as %v", models.DeletedUsername, models.DeletedUsername); systemMessage.Content != want {
+			t.Errorf("expected the system message to drop the username, got %q", systemMessage.Content)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package test_user
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/user"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestEmailChange(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+
+	me := models.User{
+		ID:         utility.GenerateUUID(),
+		Name:       "emailchange" + currUUID[:8],
+		Email:      fmt.Sprintf("testemailchange%v@qa.team", currUUID),
+		Password:   password,
+		IsVerified: true,
+	}
+	db.Postgresql.Create(&me)
+	other := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "emailother" + currUUID[:8],
+		Email:    fmt.Sprintf("testemailother%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Postgresql.Create(&other)
+	newEmail := fmt.Sprintf("testemailnew%v@qa.team", currUUID)
+
+	authController := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	userController := user.Controller{Db: db, Validator: validatorRef, Logger: logger}
+
+	r := gin.Default()
+	r.POST("/api/v1/auth/login", authController.LoginUser)
+
+	meUrl := r.Group("/api/v1/me", middleware.Authorize(db.Postgresql))
+	{
+		meUrl.GET("", userController.GetMe)
+		meUrl.POST("/email", userController.ChangeEmail)
+		meUrl.POST("/email/confirm", userController.ConfirmEmailChange)
+	}
+	r.POST("/api/v1/email-change/revert", userController.RevertEmailChange)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	login := func(email string) string {
+		resp := send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: email, Password: currUUID})
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+		return tst.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+	}
+
+	// requestChange starts a change and swaps its tokens for known ones,
+	// since the real ones only travel by email
+	requestChange := func(token string) (string, string) {
+		resp := send(http.MethodPost, "/api/v1/me/email", token, models.ChangeEmailRequest{NewEmail: newEmail, Password: currUUID})
+		tst.AssertStatusCode(t, resp.Code, http.StatusAccepted)
+
+		changeId := tst.ParseResponse(resp)["data"].(map[string]interface{})["id"].(string)
+		confirmToken, revertToken := utility.GenerateUUID(), utility.GenerateUUID()
+		db.Postgresql.Model(&models.EmailChange{}).Where("id = ?", changeId).Updates(map[string]interface{}{
+			"confirm_token_hash": utility.HashToken(confirmToken),
+			"revert_token_hash":  utility.HashToken(revertToken),
+		})
+		return confirmToken, revertToken
+	}
+
+	token := login(me.Email)
+	otherSession := login(me.Email)
+
+	t.Run("Change Requires Password", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/me/email", token, models.ChangeEmailRequest{NewEmail: newEmail, Password: "wrong"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusForbidden)
+	})
+
+	t.Run("Change To Email In Use", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/me/email", token, models.ChangeEmailRequest{NewEmail: other.Email, Password: currUUID})
+		tst.AssertStatusCode(t, resp.Code, http.StatusConflict)
+	})
+
+	t.Run("Revert Cancels Pending Change", func(t *testing.T) {
+		confirmToken, revertToken := requestChange(token)
+
+		resp := send(http.MethodPost, "/api/v1/email-change/revert", "", models.EmailChangeTokenRequest{Token: revertToken})
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		resp = send(http.MethodPost, "/api/v1/me/email/confirm", token, models.EmailChangeTokenRequest{Token: confirmToken})
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	var revertToken string
+
+	t.Run("Email Unchanged Until Confirmed", func(t *testing.T) {
+		var confirmToken string
+		confirmToken, revertToken = requestChange(token)
+
+		resp := send(http.MethodGet, "/api/v1/me", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+		if tst.ParseResponse(resp)["data"].(map[string]interface{})["email"] != me.Email {
+			t.Errorf("expected the email to stay the same before confirmation")
+		}
+
+		resp = send(http.MethodPost, "/api/v1/me/email/confirm", token, models.EmailChangeTokenRequest{Token: "wrong"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = send(http.MethodPost, "/api/v1/me/email/confirm", token, models.EmailChangeTokenRequest{Token: confirmToken})
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tst.ParseResponse(resp)["data"].(map[string]interface{})
+		if data["email"] != newEmail || data["is_verified"] != true {
+			t.Errorf("expected the verified new email, got %v %v", data["email"], data["is_verified"])
+		}
+	})
+
+	t.Run("Other Sessions Revoked", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/me", otherSession, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = send(http.MethodGet, "/api/v1/me", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+	})
+
+	t.Run("Revert Confirmed Change", func(t *testing.T) {
+		resp := send(http.MethodPost, "/api/v1/email-change/revert", "", models.EmailChangeTokenRequest{Token: revertToken})
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		var reverted models.User
+		db.Postgresql.First(&reverted, "id = ?", me.ID)
+		if reverted.Email != me.Email || !reverted.IsVerified {
+			t.Errorf("expected the old verified email back, got %v %v", reverted.Email, reverted.IsVerified)
+		}
+
+		resp = send(http.MethodGet, "/api/v1/me", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+
+		resp = send(http.MethodPost, "/api/v1/email-change/revert", "", models.EmailChangeTokenRequest{Token: revertToken})
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,116 @@
+package test_user
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/external/request"
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/user"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	rdb "github.com/hngprojects/telex_be/pkg/repository/storage/redis"
+	authService "github.com/hngprojects/telex_be/services/auth"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestSecurityEvents(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+	password, _ := utility.HashPassword(currUUID)
+	extReq := request.ExternalRequest{Logger: logger, Test: true}
+	remoteIP := "8.8.4.4"
+
+	me := models.User{
+		ID:       utility.GenerateUUID(),
+		Name:     "securityevents" + currUUID[:8],
+		Email:    fmt.Sprintf("testsecurityevents%v@qa.team", currUUID),
+		Password: password,
+	}
+	db.Postgresql.Create(&me)
+
+	authController := auth.Controller{Db: db, Validator: validatorRef, Logger: logger, ExtReq: extReq}
+	userController := user.Controller{Db: db, Validator: validatorRef, Logger: logger, ExtReq: extReq}
+
+	r := gin.Default()
+	r.POST("/api/v1/auth/login", authController.LoginUser)
+	r.GET("/api/v1/me/security-events", middleware.Authorize(db.Postgresql), userController.GetSecurityEvents)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "security-events-test")
+		req.RemoteAddr = remoteIP + ":4000"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: me.Email, Password: "wrong"})
+	tst.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+
+	resp = send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: me.Email, Password: currUUID})
+	tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+	token := tst.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+	t.Run("Events Recorded", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/me/security-events", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		events := tst.ParseResponse(resp)["data"].([]interface{})
+		if len(events) != 2 {
+			t.Fatalf("expected 2 security events, got %v", len(events))
+		}
+
+		latest := events[0].(map[string]interface{})
+		if latest["type"] != models.SecurityEventLogin || events[1].(map[string]interface{})["type"] != models.SecurityEventLoginFailed {
+			t.Errorf("expected a login after a failed login, got %v", events)
+		}
+		if latest["ip_address"] != remoteIP || latest["user_agent"] != "security-events-test" {
+			t.Errorf("expected the client to be recorded, got %v", latest)
+		}
+	})
+
+	t.Run("Locations Resolved And Cached", func(t *testing.T) {
+		for {
+			resolved, err := authService.ResolveSecurityEventLocations(extReq, db.Postgresql, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resolved == 0 {
+				break
+			}
+		}
+
+		resp := send(http.MethodGet, "/api/v1/me/security-events", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		for _, event := range tst.ParseResponse(resp)["data"].([]interface{}) {
+			if location := event.(map[string]interface{})["location"]; location != "city, name" {
+				t.Errorf("expected the mocked ipstack location, got %v", location)
+			}
+		}
+
+		if _, err := rdb.RedisGet(db.Redis, "ipstack:"+remoteIP); err != nil {
+			t.Errorf("expected the location to be cached, %v", err)
+		}
+	})
+}
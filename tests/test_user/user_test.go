@@ -0,0 +1,169 @@
+package test_user
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/user"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestUserProfile(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+	currUUID := utility.GenerateUUID()
+
+	me := tst.CreateUser(db.Postgresql, "profileme", currUUID)
+	other := tst.CreateUser(db.Postgresql, "profileother", currUUID)
+
+	authController := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	userController := user.Controller{Db: db, Validator: validatorRef, Logger: logger}
+
+	r := gin.Default()
+	r.POST("/api/v1/auth/login", authController.LoginUser)
+
+	meUrl := r.Group("/api/v1/me", middleware.Authorize(db.Postgresql))
+	{
+		meUrl.GET("", userController.GetMe)
+		meUrl.PATCH("", userController.UpdateMe)
+		meUrl.POST("/avatar", userController.UploadAvatar)
+	}
+	r.GET("/api/v1/users/:userId", userController.GetPublicProfile)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		reqBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/api/v1/auth/login", "", models.LoginRequestModel{Email: me.Email, Password: currUUID})
+	tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+	token := tst.ParseResponse(resp)["data"].(map[string]interface{})["access_token"].(string)
+
+	t.Run("Get Me", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/me", token, nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tst.ParseResponse(resp)["data"].(map[string]interface{})
+		if data["email"] != me.Email {
+			t.Errorf("expected email %v, got %v", me.Email, data["email"])
+		}
+	})
+
+	t.Run("Get Me Unauthorized", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/me", "", nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Update Me", func(t *testing.T) {
+		firstName := "jane"
+		resp := send(http.MethodPatch, "/api/v1/me", token, map[string]interface{}{"first_name": firstName})
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tst.ParseResponse(resp)["data"].(map[string]interface{})
+		profile := data["profile"].(map[string]interface{})
+		if profile["first_name"] != "Jane" {
+			t.Errorf("expected first name Jane, got %v", profile["first_name"])
+		}
+		if data["name"] != me.Name {
+			t.Errorf("expected username to be kept, got %v", data["name"])
+		}
+	})
+
+	t.Run("Username Taken", func(t *testing.T) {
+		resp := send(http.MethodPatch, "/api/v1/me", token, map[string]interface{}{"username": other.Name})
+		tst.AssertStatusCode(t, resp.Code, http.StatusConflict)
+	})
+
+	t.Run("Invalid Phone Number", func(t *testing.T) {
+		resp := send(http.MethodPatch, "/api/v1/me", token, map[string]interface{}{"phone_number": "not a phone"})
+		tst.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Upload Avatar", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+		for x := 0; x < 300; x++ {
+			for y := 0; y < 200; y++ {
+				img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+			}
+		}
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("avatar", "avatar.png")
+		png.Encode(part, img)
+		writer.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/avatar", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		profile := tst.ParseResponse(resp)["data"].(map[string]interface{})["profile"].(map[string]interface{})
+		if profile["avatar_url"] == "" {
+			t.Errorf("expected an avatar url")
+		}
+	})
+
+	t.Run("Upload Avatar Not An Image", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("avatar", "avatar.png")
+		part.Write([]byte("not an image"))
+		writer.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/avatar", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		tst.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Public Profile", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/users/"+me.ID, "", nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusOK)
+
+		data := tst.ParseResponse(resp)["data"].(map[string]interface{})
+		if _, ok := data["email"]; ok {
+			t.Errorf("public profile must not expose email")
+		}
+		if data["first_name"] != "Jane" {
+			t.Errorf("expected first name Jane, got %v", data["first_name"])
+		}
+	})
+
+	t.Run("Public Profile Invalid ID", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/v1/users/not-a-uuid", "", nil)
+		tst.AssertStatusCode(t, resp.Code, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,181 @@
+package test_workspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/hngprojects/telex_be/internal/models"
+	"github.com/hngprojects/telex_be/pkg/controller/auth"
+	"github.com/hngprojects/telex_be/pkg/controller/room"
+	"github.com/hngprojects/telex_be/pkg/controller/workspace"
+	"github.com/hngprojects/telex_be/pkg/middleware"
+	"github.com/hngprojects/telex_be/pkg/repository/storage"
+	tst "github.com/hngprojects/telex_be/tests"
+	"github.com/hngprojects/telex_be/utility"
+)
+
+func TestWorkspaces(t *testing.T) {
+	logger := tst.Setup()
+	gin.SetMode(gin.TestMode)
+
+	validatorRef := validator.New()
+	db := storage.Connection()
+
+	owner, invitee, outsider := tst.NewSignupRequest(), tst.NewSignupRequest(), tst.NewSignupRequest()
+
+	auth := auth.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	workspaceController := workspace.Controller{Db: db, Validator: validatorRef, Logger: logger}
+	roomController := room.Controller{Db: db, Validator: validatorRef, Logger: logger}
+
+	r := gin.Default()
+	tst.SignupUser(t, r, auth, owner, false)
+	tst.SignupUser(t, r, auth, invitee, false)
+	tst.SignupUser(t, r, auth, outsider, false)
+
+	ownerToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: owner.Email, Password: owner.Password})
+	inviteeToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: invitee.Email, Password: invitee.Password})
+	outsiderToken := tst.GetLoginToken(t, r, auth, models.LoginRequestModel{Email: outsider.Email, Password: outsider.Password})
+
+	r = gin.Default()
+	workspaceUrl := r.Group("/api/v1/workspaces", middleware.Authorize(db.Postgresql))
+	{
+		workspaceUrl.POST("/", workspaceController.CreateWorkspace)
+		workspaceUrl.GET("/", workspaceController.GetWorkspaces)
+		workspaceUrl.GET("/:workspaceId", workspaceController.GetWorkspace)
+		workspaceUrl.POST("/:workspaceId/invites", workspaceController.CreateInvite)
+		workspaceUrl.POST("/:workspaceId/invites/accept", workspaceController.AcceptInvite)
+		workspaceUrl.POST("/:workspaceId/domains", workspaceController.CreateDomain)
+	}
+	workspaceRoomUrl := r.Group("/api/v1/workspaces/:workspaceId/rooms", middleware.Authorize(db.Postgresql), middleware.RoomScope(db.Postgresql))
+	{
+		workspaceRoomUrl.POST("/", roomController.CreateRoom)
+		workspaceRoomUrl.GET("/", roomController.GetRooms)
+		workspaceRoomUrl.GET("/:roomId", roomController.GetRoom)
+	}
+	roomUrl := r.Group("/api/v1/rooms", middleware.Authorize(db.Postgresql), middleware.RoomScope(db.Postgresql))
+	{
+		roomUrl.GET("/:roomId", roomController.GetRoom)
+	}
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var b bytes.Buffer
+		json.NewEncoder(&b).Encode(body)
+
+		req, _ := http.NewRequest(method, path, &b)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	createWorkspace := func(name string) string {
+		rr := send(http.MethodPost, "/api/v1/workspaces/", ownerToken, models.CreateWorkspaceRequest{Name: name})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+		return tst.ParseResponse(rr)["data"].(map[string]interface{})["id"].(string)
+	}
+
+	first := createWorkspace("Acme")
+	second := createWorkspace("Globex")
+	roomName := fmt.Sprintf("general%v", utility.GenerateUUID())
+	var roomId string
+
+	t.Run("Room Names Are Unique Per Workspace", func(t *testing.T) {
+		req := models.CreateRoomRequest{Name: roomName, Description: "general", Username: owner.UserName}
+
+		rr := send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/rooms/", first), ownerToken, req)
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+		roomId = tst.ParseResponse(rr)["data"].(map[string]interface{})["room_id"].(string)
+
+		rr = send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/rooms/", first), ownerToken, req)
+		tst.AssertStatusCode(t, rr.Code, http.StatusBadRequest)
+
+		rr = send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/rooms/", second), ownerToken, req)
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+	})
+
+	t.Run("Room Names Are Unique Outside Workspaces", func(t *testing.T) {
+		name := fmt.Sprintf("lobby%v", utility.GenerateUUID())
+
+		first := models.Room{ID: utility.GenerateUUID(), Name: name, Description: "lobby"}
+		if err := db.Postgresql.Create(&first).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		second := models.Room{ID: utility.GenerateUUID(), Name: name, Description: "lobby"}
+		if err := db.Postgresql.Create(&second).Error; err == nil {
+			t.Errorf("expected a second room without a workspace to reuse the name to fail")
+		}
+	})
+
+	t.Run("Rooms Are Scoped To Their Workspace", func(t *testing.T) {
+		rr := send(http.MethodGet, fmt.Sprintf("/api/v1/workspaces/%s/rooms/%s", first, roomId), ownerToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/v1/workspaces/%s/rooms/%s", second, roomId), ownerToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusNotFound)
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/v1/rooms/%s", roomId), ownerToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusNotFound)
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/v1/workspaces/%s/rooms/", first), outsiderToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusNotFound)
+	})
+
+	t.Run("Invite By Email", func(t *testing.T) {
+		rr := send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/invites", first), ownerToken, models.CreateWorkspaceInviteRequest{Email: invitee.Email})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		var invite models.WorkspaceInvite
+		db.Postgresql.Where("workspace_id = ? AND email = ?", first, invitee.Email).First(&invite)
+
+		accept := models.AcceptWorkspaceInviteRequest{Token: invite.Token}
+
+		rr = send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/invites/accept", first), outsiderToken, accept)
+		tst.AssertStatusCode(t, rr.Code, http.StatusForbidden)
+
+		rr = send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/invites/accept", first), inviteeToken, accept)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		rr = send(http.MethodGet, "/api/v1/workspaces/", inviteeToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+		if workspaces := tst.ParseResponse(rr)["data"].([]interface{}); len(workspaces) != 1 {
+			t.Errorf("expected invitee to belong to 1 workspace, got %d", len(workspaces))
+		}
+	})
+
+	t.Run("Public Email Domains Are Refused", func(t *testing.T) {
+		rr := send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/domains", second), ownerToken, models.CreateWorkspaceDomainRequest{Domain: "gmail.com"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Domain Auto Join", func(t *testing.T) {
+		rr := send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/domains", second), ownerToken, models.CreateWorkspaceDomainRequest{Domain: "qa.team"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusForbidden)
+
+		db.Postgresql.Model(&models.User{}).Where("email IN ?", []string{owner.Email, outsider.Email}).Update("is_verified", true)
+
+		rr = send(http.MethodPost, fmt.Sprintf("/api/v1/workspaces/%s/domains", second), ownerToken, models.CreateWorkspaceDomainRequest{Domain: "qa.team"})
+		tst.AssertStatusCode(t, rr.Code, http.StatusCreated)
+
+		var user models.User
+		user, _ = user.GetUserByEmail(db.Postgresql, outsider.Email)
+		if err := models.AutoJoinWorkspaces(db.Postgresql, user); err != nil {
+			t.Fatal(err)
+		}
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/v1/workspaces/%s", second), outsiderToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusOK)
+
+		rr = send(http.MethodGet, fmt.Sprintf("/api/v1/workspaces/%s", first), outsiderToken, nil)
+		tst.AssertStatusCode(t, rr.Code, http.StatusNotFound)
+	})
+}
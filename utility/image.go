@@ -0,0 +1,67 @@
+package utility
+
+import (
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxImageSide bounds the dimensions DecodeImage accepts, so a small file
+// cannot expand into a huge bitmap.
+const maxImageSide = 4096
+
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// DecodeImage reads a GIF, JPEG, PNG or WebP image after checking its
+// dimensions from the header.
+func DecodeImage(r io.ReadSeeker) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width > maxImageSide || cfg.Height > maxImageSide {
+		return nil, ErrImageTooLarge
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// CenterSquare is the largest square centred in bounds.
+func CenterSquare(bounds image.Rectangle) image.Rectangle {
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// CropAndResize cuts crop out of src and scales it to a size by size
+// square. crop is clipped to the image first.
+func CropAndResize(src image.Image, crop image.Rectangle, size int) (image.Image, error) {
+	crop = crop.Intersect(src.Bounds())
+	if crop.Empty() {
+		return nil, errors.New("crop area is outside the image")
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+	return dst, nil
+}
+
+func EncodePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
@@ -2,6 +2,7 @@ package utility
 
 import (
 	crand "crypto/rand"
+	"encoding/hex"
 	"io"
 	"math/rand"
 	"regexp"
@@ -52,3 +53,12 @@ func GenerateOTP(max int) (int, error) {
 	}
 	return strconv.Atoi(string(b))
 }
+
+// GenerateSecureToken returns a hex encoded token built from n bytes of crypto/rand.
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,174 @@
+package utility
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Algorithms access and Centrifugo tokens can be signed with.
+const (
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+const rsaSigningKeyBits = 2048
+
+// JWK is the public half of a signing key as published in a JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// GenerateSigningKey returns a new key pair for alg, the private key as
+// PKCS #8 PEM and the public key as PKIX PEM.
+func GenerateSigningKey(alg string) (string, string, error) {
+	var (
+		private crypto.Signer
+		err     error
+	)
+
+	switch alg {
+	case SigningAlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaSigningKeyBits)
+	case SigningAlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", "", fmt.Errorf("unsupported signing algorithm %v", alg)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return "", "", err
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return string(privatePEM), string(publicPEM), nil
+}
+
+// SealedSigningKeyPrefix marks a private key encrypted by SealPrivateSigningKey, as
+// opposed to a PEM stored before keys were encrypted.
+const SealedSigningKeyPrefix = "sealed:v1:"
+
+func signingKeyCipher(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, errors.New("signing key secret is not configured")
+	}
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealPrivateSigningKey encrypts a private key from GenerateSigningKey with
+// AES-GCM under secret so it is not stored in the clear.
+func SealPrivateSigningKey(privatePEM, secret string) (string, error) {
+	aead, err := signingKeyCipher(secret)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(privatePEM), nil)
+	return SealedSigningKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenPrivateSigningKey reverses SealPrivateSigningKey. Keys stored before
+// encryption are returned as they are.
+func OpenPrivateSigningKey(stored, secret string) (string, error) {
+	if !IsSealedSigningKey(stored) {
+		return stored, nil
+	}
+
+	aead, err := signingKeyCipher(secret)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, SealedSigningKeyPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("invalid sealed private key")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	privatePEM, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("unable to decrypt private key")
+	}
+	return string(privatePEM), nil
+}
+
+// IsSealedSigningKey reports whether stored was encrypted by
+// SealPrivateSigningKey.
+func IsSealedSigningKey(stored string) bool {
+	return strings.HasPrefix(stored, SealedSigningKeyPrefix)
+}
+
+// ParsePrivateSigningKey decodes a key from GenerateSigningKey into the form
+// jwt signing methods expect.
+func ParsePrivateSigningKey(privatePEM string) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid private key")
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// ParsePublicSigningKey is ParsePrivateSigningKey for the public half.
+func ParsePublicSigningKey(publicPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("invalid public key")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// PublicJWK describes key as a JWK (RFC 7517, RFC 8037 for Ed25519).
+func PublicJWK(kid, alg string, key crypto.PublicKey) (JWK, error) {
+	jwk := JWK{Use: "sig", Alg: alg, Kid: kid}
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	default:
+		return jwk, fmt.Errorf("unsupported public key type %T", key)
+	}
+	return jwk, nil
+}
@@ -32,6 +32,16 @@ func PhoneValid(phone string) (string, bool) {
 	return formattedNum, true
 }
 
+// PhoneE164 returns phone in E.164 form, the form SMS providers expect. The
+// number must carry its country code.
+func PhoneE164(phone string) (string, bool) {
+	parsed, err := phonenumbers.Parse(phone, "")
+	if err != nil || !phonenumbers.IsValidNumber(parsed) {
+		return phone, false
+	}
+	return phonenumbers.Format(parsed, phonenumbers.E164), true
+}
+
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {